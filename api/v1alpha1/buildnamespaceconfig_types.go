@@ -0,0 +1,159 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildNamespaceConfigName is the name of the BuildNamespaceConfig of a namespace
+const BuildNamespaceConfigName = "config"
+
+// BuildStrategyName is a shipped build strategy with a SecurityContextConstraints
+// +kubebuilder:validation:Enum=buildah;buildpacks;source-to-image
+type BuildStrategyName string
+
+// Shipped build strategies with a SecurityContextConstraints
+const (
+	BuildahStrategy       BuildStrategyName = "buildah"
+	BuildpacksStrategy    BuildStrategyName = "buildpacks"
+	SourceToImageStrategy BuildStrategyName = "source-to-image"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Service Account",type=string,JSONPath=`.spec.serviceAccountName`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:validation:XValidation:rule="self.metadata.name == 'config'",message="BuildNamespaceConfig is a singleton and must be named config"
+
+// BuildNamespaceConfig onboards its namespace to builds. The operator creates the service account
+// running the builds, grants it the internal registry push credentials and the use of shared
+// resources, binds the build personas, and applies the build quota and concurrency limits.
+// Deleting the BuildNamespaceConfig removes them.
+type BuildNamespaceConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BuildNamespaceConfigSpec   `json:"spec,omitempty"`
+	Status BuildNamespaceConfigStatus `json:"status,omitempty"`
+}
+
+// BuildNamespaceConfigSpec defines the desired onboarding of a namespace to builds
+type BuildNamespaceConfigSpec struct {
+
+	// ServiceAccountName is the service account running the builds. It is created if it does
+	// not exist, and allowed to push to the ImageStreams of the namespace. Defaults to pipeline.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=pipeline
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// Editors are allowed to manage the Builds, BuildRuns and BuildStrategies of the namespace.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Editors []rbacv1.Subject `json:"editors,omitempty"`
+
+	// Viewers are allowed to view the Builds, BuildRuns and BuildStrategies of the namespace.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Viewers []rbacv1.Subject `json:"viewers,omitempty"`
+
+	// SharedSecrets are the names of the SharedSecrets the service account is allowed to mount.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	SharedSecrets []string `json:"sharedSecrets,omitempty"`
+
+	// SharedConfigMaps are the names of the SharedConfigMaps the service account is allowed to
+	// mount.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	SharedConfigMaps []string `json:"sharedConfigMaps,omitempty"`
+
+	// Strategies are the shipped build strategies the service account is allowed to run, by
+	// granting it the use of their SecurityContextConstraints, rather than the privileged one.
+	//
+	// +kubebuilder:validation:Optional
+	// +listType=set
+	// +optional
+	Strategies []BuildStrategyName `json:"strategies,omitempty"`
+
+	// Quota limits the resources of the namespace. No quota is applied when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+
+	// Concurrency limits the BuildRuns running concurrently in the namespace. The pods of the
+	// excess BuildRuns are queued, and started in priority and FIFO order. No limit is applied
+	// when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Concurrency *BuildConcurrency `json:"concurrency,omitempty"`
+}
+
+// BuildConcurrency limits the BuildRuns running concurrently in a namespace
+type BuildConcurrency struct {
+
+	// MaxRunning is the maximum number of BuildRuns running concurrently in the namespace. The
+	// number of BuildRuns is not limited when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRunning *int32 `json:"maxRunning,omitempty"`
+
+	// Strategies limit the BuildRuns running concurrently with a build strategy.
+	//
+	// +kubebuilder:validation:Optional
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Strategies []StrategyConcurrency `json:"strategies,omitempty"`
+}
+
+// StrategyConcurrency limits the BuildRuns running concurrently with a build strategy
+type StrategyConcurrency struct {
+
+	// Name is the name of the BuildStrategy or ClusterBuildStrategy.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// MaxRunning is the maximum number of BuildRuns running concurrently with the strategy.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	MaxRunning int32 `json:"maxRunning"`
+}
+
+// BuildNamespaceConfigStatus defines the observed onboarding of a namespace
+type BuildNamespaceConfigStatus struct {
+
+	// Conditions holds the latest available observations of a resource's current state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// PushSecret is the secret holding the internal registry credentials of the service
+	// account, generated by OpenShift.
+	PushSecret string `json:"pushSecret,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BuildNamespaceConfigList contains a list of BuildNamespaceConfig
+type BuildNamespaceConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BuildNamespaceConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BuildNamespaceConfig{}, &BuildNamespaceConfigList{})
+}
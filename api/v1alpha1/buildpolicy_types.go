@@ -0,0 +1,108 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterBuildPolicyName is the name of the BuildPolicy applying to all namespaces
+const ClusterBuildPolicyName = "cluster"
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:validation:XValidation:rule="self.metadata.name != 'cluster' || !has(self.spec) || !has(self.spec.namespaceSelector)",message="the cluster BuildPolicy applies to all namespaces and cannot select namespaces"
+
+// BuildPolicy restricts the Builds and BuildRuns of the namespaces. The BuildPolicy named cluster
+// applies to all namespaces, and the other BuildPolicies override its rules in the namespaces
+// they select. A validating webhook rejects the Builds and BuildRuns violating the rules of their
+// namespace, and records the violations as events and metrics.
+type BuildPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BuildPolicySpec `json:"spec,omitempty"`
+}
+
+// BuildPolicySpec defines the rules of the Builds and BuildRuns and the namespaces they apply to
+type BuildPolicySpec struct {
+
+	// NamespaceSelector selects the namespaces whose rules are overridden by the BuildPolicy. A
+	// nil selector selects no namespace, and an empty selector selects all namespaces. It must be
+	// omitted from the cluster BuildPolicy.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	BuildPolicyRules `json:",inline"`
+}
+
+// BuildPolicyRules are the rules of the Builds and BuildRuns. Omitted rules do not restrict the
+// Builds, or keep the rule of the cluster BuildPolicy in the overridden namespaces. Lists allow
+// any value with the "*" entry.
+type BuildPolicyRules struct {
+
+	// AllowedStrategies are the build strategies the Builds may use.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	AllowedStrategies []BuildPolicyStrategy `json:"allowedStrategies,omitempty"`
+
+	// AllowedOutputRegistries are the registries the Builds may push to, as a host, a host with
+	// a repository prefix such as "quay.io/team", or a "*.example.com" host pattern.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	AllowedOutputRegistries []string `json:"allowedOutputRegistries,omitempty"`
+
+	// AllowedGitHosts are the hosts the Builds may clone their Git source from, such as
+	// "github.com", or a "*.example.com" host pattern.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	AllowedGitHosts []string `json:"allowedGitHosts,omitempty"`
+
+	// MaxTimeout is the maximum timeout of the Builds and BuildRuns.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	MaxTimeout *metav1.Duration `json:"maxTimeout,omitempty"`
+
+	// RequiredLabels are the label keys the Builds, and the BuildRuns embedding their Build
+	// specification, must carry.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+}
+
+// BuildPolicyStrategy references build strategies
+type BuildPolicyStrategy struct {
+
+	// Kind is the kind of the build strategies.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=ClusterBuildStrategy;BuildStrategy
+	// +kubebuilder:default=ClusterBuildStrategy
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the build strategy, or "*" for all build strategies of the kind.
+	//
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+
+// BuildPolicyList contains a list of BuildPolicy
+type BuildPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BuildPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BuildPolicy{}, &BuildPolicyList{})
+}
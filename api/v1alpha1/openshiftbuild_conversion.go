@@ -0,0 +1,240 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/redhat-openshift-builds/operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Convertible = &OpenShiftBuild{}
+
+// ConvertTo converts this OpenShiftBuild to the hub version (v1beta1)
+func (src *OpenShiftBuild) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1beta1.OpenShiftBuild)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1.OpenShiftBuild object but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.Config.Namespace = src.Spec.Namespace
+	dst.Spec.Shipwright = nil
+	if src.Spec.Shipwright != nil {
+		dst.Spec.Shipwright = &v1beta1.Shipwright{}
+		if src.Spec.Shipwright.Build != nil {
+			dst.Spec.Shipwright.Build = &v1beta1.Component{
+				ManagementState: stateToManagementState(src.Spec.Shipwright.Build.State),
+			}
+		}
+		if src.Spec.Shipwright.Triggers != nil {
+			dst.Spec.Shipwright.Triggers = &v1beta1.Triggers{
+				ManagementState: stateToManagementState(src.Spec.Shipwright.Triggers.State),
+			}
+		}
+		if pruning := src.Spec.Shipwright.Pruning; pruning != nil {
+			dst.Spec.Shipwright.Pruning = &v1beta1.Pruning{
+				ManagementState:   stateToManagementState(pruning.State),
+				SucceededLimit:    copyInt32(pruning.SucceededLimit),
+				FailedLimit:       copyInt32(pruning.FailedLimit),
+				TTLAfterSucceeded: copyDuration(pruning.TTLAfterSucceeded),
+				TTLAfterFailed:    copyDuration(pruning.TTLAfterFailed),
+			}
+		}
+		if sandboxed := src.Spec.Shipwright.Sandboxed; sandboxed != nil {
+			dst.Spec.Shipwright.Sandboxed = &v1beta1.Sandboxed{
+				ManagementState:  stateToManagementState(sandboxed.State),
+				RuntimeClassName: sandboxed.RuntimeClassName,
+			}
+		}
+		if userNamespaces := src.Spec.Shipwright.UserNamespaces; userNamespaces != nil {
+			dst.Spec.Shipwright.UserNamespaces = &v1beta1.UserNamespaces{
+				ManagementState:   stateToManagementState(userNamespaces.State),
+				NamespaceSelector: userNamespaces.NamespaceSelector.DeepCopy(),
+			}
+		}
+	}
+	dst.Spec.SharedResource = nil
+	if src.Spec.SharedResource != nil {
+		dst.Spec.SharedResource = &v1beta1.Component{
+			ManagementState: stateToManagementState(src.Spec.SharedResource.State),
+		}
+	}
+	dst.Spec.Entitlements = nil
+	if src.Spec.Entitlements != nil {
+		dst.Spec.Entitlements = &v1beta1.Entitlements{
+			ManagementState:   stateToManagementState(src.Spec.Entitlements.State),
+			NamespaceSelector: src.Spec.Entitlements.NamespaceSelector.DeepCopy(),
+		}
+	}
+	dst.Spec.BuildCache = nil
+	if src.Spec.BuildCache != nil {
+		dst.Spec.BuildCache = &v1beta1.BuildCache{
+			ManagementState:  stateToManagementState(src.Spec.BuildCache.State),
+			Scope:            v1beta1.BuildCacheScope(src.Spec.BuildCache.Scope),
+			StorageClassName: copyString(src.Spec.BuildCache.StorageClassName),
+			Size:             copyQuantity(src.Spec.BuildCache.Size),
+		}
+	}
+	dst.Status.Conditions = copyConditions(src.Status.Conditions)
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1beta1) to this version
+func (dst *OpenShiftBuild) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1beta1.OpenShiftBuild)
+	if !ok {
+		return fmt.Errorf("expected a v1beta1.OpenShiftBuild object but got %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec.Namespace = src.Spec.Config.Namespace
+	dst.Spec.Shipwright = nil
+	if src.Spec.Shipwright != nil {
+		dst.Spec.Shipwright = &Shipwright{}
+		if src.Spec.Shipwright.Build != nil {
+			dst.Spec.Shipwright.Build = &ShipwrightBuild{
+				State: managementStateToState(src.Spec.Shipwright.Build.ManagementState),
+			}
+		}
+		if src.Spec.Shipwright.Triggers != nil {
+			dst.Spec.Shipwright.Triggers = &ShipwrightTriggers{
+				State: managementStateToState(src.Spec.Shipwright.Triggers.ManagementState),
+			}
+		}
+		if pruning := src.Spec.Shipwright.Pruning; pruning != nil {
+			dst.Spec.Shipwright.Pruning = &ShipwrightPruning{
+				State:             managementStateToState(pruning.ManagementState),
+				SucceededLimit:    copyInt32(pruning.SucceededLimit),
+				FailedLimit:       copyInt32(pruning.FailedLimit),
+				TTLAfterSucceeded: copyDuration(pruning.TTLAfterSucceeded),
+				TTLAfterFailed:    copyDuration(pruning.TTLAfterFailed),
+			}
+		}
+		if sandboxed := src.Spec.Shipwright.Sandboxed; sandboxed != nil {
+			dst.Spec.Shipwright.Sandboxed = &ShipwrightSandboxed{
+				State:            managementStateToState(sandboxed.ManagementState),
+				RuntimeClassName: sandboxed.RuntimeClassName,
+			}
+		}
+		if userNamespaces := src.Spec.Shipwright.UserNamespaces; userNamespaces != nil {
+			dst.Spec.Shipwright.UserNamespaces = &ShipwrightUserNamespaces{
+				State:             managementStateToState(userNamespaces.ManagementState),
+				NamespaceSelector: userNamespaces.NamespaceSelector.DeepCopy(),
+			}
+		}
+	}
+	dst.Spec.SharedResource = nil
+	if src.Spec.SharedResource != nil {
+		dst.Spec.SharedResource = &SharedResource{
+			State: managementStateToState(src.Spec.SharedResource.ManagementState),
+		}
+	}
+	dst.Spec.Entitlements = nil
+	if src.Spec.Entitlements != nil {
+		dst.Spec.Entitlements = &Entitlements{
+			State:             managementStateToState(src.Spec.Entitlements.ManagementState),
+			NamespaceSelector: src.Spec.Entitlements.NamespaceSelector.DeepCopy(),
+		}
+	}
+	dst.Spec.BuildCache = nil
+	if src.Spec.BuildCache != nil {
+		dst.Spec.BuildCache = &BuildCache{
+			State:            managementStateToState(src.Spec.BuildCache.ManagementState),
+			Scope:            BuildCacheScope(src.Spec.BuildCache.Scope),
+			StorageClassName: copyString(src.Spec.BuildCache.StorageClassName),
+			Size:             copyQuantity(src.Spec.BuildCache.Size),
+		}
+	}
+	dst.Status.Conditions = copyConditions(src.Status.Conditions)
+	return nil
+}
+
+// stateToManagementState maps Enabled to Managed and Disabled to Removed. Other values are
+// kept as is so that the conversion is lossless.
+func stateToManagementState(state State) v1beta1.ManagementState {
+	switch state {
+	case Enabled:
+		return v1beta1.Managed
+	case Disabled:
+		return v1beta1.Removed
+	}
+	return v1beta1.ManagementState(state)
+}
+
+// managementStateToState maps Managed to Enabled and Removed to Disabled. Other values are
+// kept as is so that the conversion is lossless.
+func managementStateToState(state v1beta1.ManagementState) State {
+	switch state {
+	case v1beta1.Managed:
+		return Enabled
+	case v1beta1.Removed:
+		return Disabled
+	}
+	return State(state)
+}
+
+// copyConditions returns a deep copy of the conditions, preserving nil
+func copyConditions(conditions []metav1.Condition) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	copied := make([]metav1.Condition, len(conditions))
+	for i := range conditions {
+		conditions[i].DeepCopyInto(&copied[i])
+	}
+	return copied
+}
+
+// copyInt32 returns a copy of the pointed value, preserving nil
+func copyInt32(value *int32) *int32 {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// copyDuration returns a copy of the pointed duration, preserving nil
+func copyDuration(duration *metav1.Duration) *metav1.Duration {
+	if duration == nil {
+		return nil
+	}
+	copied := *duration
+	return &copied
+}
+
+// copyString returns a copy of the pointed string, preserving nil
+func copyString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+// copyQuantity returns a deep copy of the pointed quantity, preserving nil
+func copyQuantity(quantity *resource.Quantity) *resource.Quantity {
+	if quantity == nil {
+		return nil
+	}
+	copied := quantity.DeepCopy()
+	return &copied
+}
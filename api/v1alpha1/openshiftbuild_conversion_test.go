@@ -0,0 +1,184 @@
+package v1alpha1_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/api/v1beta1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/randfill"
+)
+
+var _ = Describe("OpenShiftBuild conversion", Label("conversion"), func() {
+	var (
+		now        metav1.Time
+		objectMeta metav1.ObjectMeta
+		conditions []metav1.Condition
+	)
+
+	BeforeEach(func() {
+		now = metav1.NewTime(time.Now().Truncate(time.Second))
+		objectMeta = metav1.ObjectMeta{
+			Name:            "cluster",
+			UID:             "6d5a3c4e-0000-4000-8000-000000000000",
+			ResourceVersion: "42",
+			Generation:      3,
+			Labels:          map[string]string{"app": "openshift-builds"},
+			Annotations:     map[string]string{"note": "converted"},
+			Finalizers:      []string{"operator.openshift.io/openshiftbuilds"},
+		}
+		conditions = []metav1.Condition{
+			{
+				Type:               v1alpha1.ConditionReady,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 3,
+				LastTransitionTime: now,
+				Reason:             "Success",
+				Message:            "Successfully reconciled OpenShiftBuild",
+			},
+		}
+	})
+
+	Describe("ConvertTo", func() {
+		It("converts every field to v1beta1", func() {
+			src := &v1alpha1.OpenShiftBuild{
+				ObjectMeta: objectMeta,
+				Spec: v1alpha1.OpenShiftBuildSpec{
+					Namespace: "builds",
+					Shipwright: &v1alpha1.Shipwright{
+						Build:     &v1alpha1.ShipwrightBuild{State: v1alpha1.Enabled},
+						Triggers:  &v1alpha1.ShipwrightTriggers{State: v1alpha1.Disabled},
+						Sandboxed: &v1alpha1.ShipwrightSandboxed{State: v1alpha1.Enabled, RuntimeClassName: "kata-remote"},
+						UserNamespaces: &v1alpha1.ShipwrightUserNamespaces{
+							State:             v1alpha1.Enabled,
+							NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"builds": "userns"}},
+						},
+					},
+					SharedResource: &v1alpha1.SharedResource{State: v1alpha1.Disabled},
+					Entitlements: &v1alpha1.Entitlements{
+						State:             v1alpha1.Enabled,
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"builds": "entitled"}},
+					},
+					BuildCache: &v1alpha1.BuildCache{
+						State: v1alpha1.Enabled,
+						Scope: v1alpha1.BuildCacheScopeNamespace,
+						Size:  ptr.To(resource.MustParse("20Gi")),
+					},
+				},
+				Status: v1alpha1.OpenShiftBuildStatus{Conditions: conditions},
+			}
+			dst := &v1beta1.OpenShiftBuild{}
+			Expect(src.ConvertTo(dst)).To(Succeed())
+			Expect(dst.ObjectMeta).To(Equal(objectMeta))
+			Expect(dst.Spec.Config.Namespace).To(Equal("builds"))
+			Expect(dst.Spec.Shipwright.Build.ManagementState).To(Equal(v1beta1.Managed))
+			Expect(dst.Spec.Shipwright.Triggers.ManagementState).To(Equal(v1beta1.Removed))
+			Expect(dst.Spec.Shipwright.Sandboxed.ManagementState).To(Equal(v1beta1.Managed))
+			Expect(dst.Spec.Shipwright.Sandboxed.RuntimeClassName).To(Equal("kata-remote"))
+			Expect(dst.Spec.Shipwright.UserNamespaces.ManagementState).To(Equal(v1beta1.Managed))
+			Expect(dst.Spec.Shipwright.UserNamespaces.NamespaceSelector.MatchLabels).To(HaveKeyWithValue("builds", "userns"))
+			Expect(dst.Spec.SharedResource.ManagementState).To(Equal(v1beta1.Removed))
+			Expect(dst.Spec.Entitlements.ManagementState).To(Equal(v1beta1.Managed))
+			Expect(dst.Spec.Entitlements.NamespaceSelector.MatchLabels).To(HaveKeyWithValue("builds", "entitled"))
+			Expect(dst.Spec.BuildCache.ManagementState).To(Equal(v1beta1.Managed))
+			Expect(dst.Spec.BuildCache.Scope).To(Equal(v1beta1.BuildCacheScopeNamespace))
+			Expect(dst.Spec.BuildCache.Size.String()).To(Equal("20Gi"))
+			Expect(dst.Status.Conditions).To(Equal(conditions))
+		})
+
+		It("keeps empty component stanzas empty", func() {
+			src := &v1alpha1.OpenShiftBuild{
+				Spec: v1alpha1.OpenShiftBuildSpec{
+					Shipwright: &v1alpha1.Shipwright{},
+				},
+			}
+			dst := &v1beta1.OpenShiftBuild{}
+			Expect(src.ConvertTo(dst)).To(Succeed())
+			Expect(dst.Spec.Shipwright).NotTo(BeNil())
+			Expect(dst.Spec.Shipwright.Build).To(BeNil())
+			Expect(dst.Spec.Shipwright.Triggers).To(BeNil())
+			Expect(dst.Spec.Shipwright.Sandboxed).To(BeNil())
+			Expect(dst.Spec.Shipwright.UserNamespaces).To(BeNil())
+			Expect(dst.Spec.SharedResource).To(BeNil())
+			Expect(dst.Spec.Entitlements).To(BeNil())
+			Expect(dst.Spec.BuildCache).To(BeNil())
+		})
+	})
+
+	Describe("ConvertFrom", func() {
+		It("converts every field from v1beta1", func() {
+			src := &v1beta1.OpenShiftBuild{
+				ObjectMeta: objectMeta,
+				Spec: v1beta1.OpenShiftBuildSpec{
+					Config: v1beta1.OperandConfig{Namespace: "builds"},
+					Shipwright: &v1beta1.Shipwright{
+						Build:    &v1beta1.Component{ManagementState: v1beta1.Removed},
+						Triggers: &v1beta1.Triggers{ManagementState: v1beta1.Managed},
+					},
+					SharedResource: &v1beta1.Component{ManagementState: v1beta1.Managed},
+					Entitlements:   &v1beta1.Entitlements{ManagementState: v1beta1.Removed},
+				},
+				Status: v1beta1.OpenShiftBuildStatus{Conditions: conditions},
+			}
+			dst := &v1alpha1.OpenShiftBuild{}
+			Expect(dst.ConvertFrom(src)).To(Succeed())
+			Expect(dst.ObjectMeta).To(Equal(objectMeta))
+			Expect(dst.Spec.Namespace).To(Equal("builds"))
+			Expect(dst.Spec.Shipwright.Build.State).To(Equal(v1alpha1.Disabled))
+			Expect(dst.Spec.Shipwright.Triggers.State).To(Equal(v1alpha1.Enabled))
+			Expect(dst.Spec.SharedResource.State).To(Equal(v1alpha1.Enabled))
+			Expect(dst.Spec.Entitlements.State).To(Equal(v1alpha1.Disabled))
+			Expect(dst.Spec.Entitlements.NamespaceSelector).To(BeNil())
+			Expect(dst.Status.Conditions).To(Equal(conditions))
+		})
+	})
+
+	Describe("round trip", func() {
+		var filler *randfill.Filler
+
+		BeforeEach(func() {
+			filler = randfill.NewWithSeed(GinkgoRandomSeed()).NilChance(0.2).Funcs(
+				func(s *v1alpha1.State, c randfill.Continue) {
+					*s = []v1alpha1.State{"", v1alpha1.Enabled, v1alpha1.Disabled}[c.Intn(3)]
+				},
+				func(s *v1beta1.ManagementState, c randfill.Continue) {
+					*s = []v1beta1.ManagementState{"", v1beta1.Managed, v1beta1.Removed}[c.Intn(3)]
+				},
+			)
+		})
+
+		It("is lossless from v1alpha1", func() {
+			for range 1000 {
+				original := &v1alpha1.OpenShiftBuild{}
+				filler.Fill(original)
+				original.TypeMeta = metav1.TypeMeta{}
+
+				hub := &v1beta1.OpenShiftBuild{}
+				Expect(original.DeepCopy().ConvertTo(hub)).To(Succeed())
+				converted := &v1alpha1.OpenShiftBuild{}
+				Expect(converted.ConvertFrom(hub)).To(Succeed())
+				Expect(apiequality.Semantic.DeepEqual(original, converted)).To(BeTrue(), "round trip of %+v", original)
+			}
+		})
+
+		It("is lossless from v1beta1", func() {
+			for range 1000 {
+				original := &v1beta1.OpenShiftBuild{}
+				filler.Fill(original)
+				original.TypeMeta = metav1.TypeMeta{}
+
+				spoke := &v1alpha1.OpenShiftBuild{}
+				Expect(spoke.ConvertFrom(original.DeepCopy())).To(Succeed())
+				converted := &v1beta1.OpenShiftBuild{}
+				Expect(spoke.ConvertTo(converted)).To(Succeed())
+				Expect(apiequality.Semantic.DeepEqual(original, converted)).To(BeTrue(), "round trip of %+v", original)
+			}
+		})
+	})
+})
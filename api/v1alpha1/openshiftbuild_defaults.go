@@ -0,0 +1,83 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// DefaultRuntimeClassName is the RuntimeClass of the sandboxed build strategies, installed by
+// OpenShift sandboxed containers
+const DefaultRuntimeClassName = "kata"
+
+// Default fills the component stanzas left empty with their default state. It is called by the
+// defaulting webhook, and by the reconciler for objects created before the webhook was installed.
+func (o *OpenShiftBuild) Default() {
+	if o.Spec.Shipwright == nil {
+		o.Spec.Shipwright = &Shipwright{}
+	}
+	if o.Spec.Shipwright.Build == nil {
+		o.Spec.Shipwright.Build = &ShipwrightBuild{}
+	}
+	if o.Spec.Shipwright.Build.State == "" {
+		o.Spec.Shipwright.Build.State = Enabled
+	}
+	if o.Spec.Shipwright.Triggers == nil {
+		o.Spec.Shipwright.Triggers = &ShipwrightTriggers{}
+	}
+	if o.Spec.Shipwright.Triggers.State == "" {
+		o.Spec.Shipwright.Triggers.State = Disabled
+	}
+	if o.Spec.Shipwright.Pruning == nil {
+		o.Spec.Shipwright.Pruning = &ShipwrightPruning{}
+	}
+	if o.Spec.Shipwright.Pruning.State == "" {
+		o.Spec.Shipwright.Pruning.State = Disabled
+	}
+	if o.Spec.Shipwright.Sandboxed == nil {
+		o.Spec.Shipwright.Sandboxed = &ShipwrightSandboxed{}
+	}
+	if o.Spec.Shipwright.Sandboxed.State == "" {
+		o.Spec.Shipwright.Sandboxed.State = Disabled
+	}
+	if o.Spec.Shipwright.Sandboxed.RuntimeClassName == "" {
+		o.Spec.Shipwright.Sandboxed.RuntimeClassName = DefaultRuntimeClassName
+	}
+	if o.Spec.Shipwright.UserNamespaces == nil {
+		o.Spec.Shipwright.UserNamespaces = &ShipwrightUserNamespaces{}
+	}
+	if o.Spec.Shipwright.UserNamespaces.State == "" {
+		o.Spec.Shipwright.UserNamespaces.State = Disabled
+	}
+	if o.Spec.SharedResource == nil {
+		o.Spec.SharedResource = &SharedResource{}
+	}
+	if o.Spec.SharedResource.State == "" {
+		o.Spec.SharedResource.State = Enabled
+	}
+	if o.Spec.Entitlements == nil {
+		o.Spec.Entitlements = &Entitlements{}
+	}
+	if o.Spec.Entitlements.State == "" {
+		o.Spec.Entitlements.State = Disabled
+	}
+	if o.Spec.BuildCache == nil {
+		o.Spec.BuildCache = &BuildCache{}
+	}
+	if o.Spec.BuildCache.State == "" {
+		o.Spec.BuildCache.State = Disabled
+	}
+	if o.Spec.BuildCache.Scope == "" {
+		o.Spec.BuildCache.Scope = BuildCacheScopeBuild
+	}
+}
@@ -0,0 +1,66 @@
+package v1alpha1_test
+
+import (
+	"encoding/json"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("OpenShiftBuild defaults", Label("defaults"), func() {
+	var versions map[string]apiextensionsv1.JSONSchemaProps
+
+	// stateDefault returns the default of the state field of the component stanza in the schema
+	stateDefault := func(version, field string, path ...string) string {
+		schema := versions[version]
+		for _, name := range append([]string{"spec"}, path...) {
+			Expect(schema.Properties).To(HaveKey(name))
+			schema = schema.Properties[name]
+		}
+		Expect(schema.Properties).To(HaveKey(field))
+		Expect(schema.Properties[field].Default).NotTo(BeNil(), "%s has no default", path)
+		value := ""
+		Expect(json.Unmarshal(schema.Properties[field].Default.Raw, &value)).To(Succeed())
+		return value
+	}
+
+	BeforeEach(func() {
+		data, err := os.ReadFile("../../config/crd/bases/operator.openshift.io_openshiftbuilds.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		Expect(yaml.Unmarshal(data, crd)).To(Succeed())
+		versions = map[string]apiextensionsv1.JSONSchemaProps{}
+		for _, version := range crd.Spec.Versions {
+			versions[version.Name] = *version.Schema.OpenAPIV3Schema
+		}
+	})
+
+	It("defaults the component states as the CRD does", func() {
+		object := &v1alpha1.OpenShiftBuild{}
+		object.Default()
+
+		stanzas := []struct {
+			path  []string
+			state v1alpha1.State
+		}{
+			{[]string{"shipwright", "build"}, object.Spec.Shipwright.Build.State},
+			{[]string{"shipwright", "triggers"}, object.Spec.Shipwright.Triggers.State},
+			{[]string{"shipwright", "pruning"}, object.Spec.Shipwright.Pruning.State},
+			{[]string{"shipwright", "sandboxed"}, object.Spec.Shipwright.Sandboxed.State},
+			{[]string{"shipwright", "userNamespaces"}, object.Spec.Shipwright.UserNamespaces.State},
+			{[]string{"sharedResource"}, object.Spec.SharedResource.State},
+			{[]string{"entitlements"}, object.Spec.Entitlements.State},
+			{[]string{"buildCache"}, object.Spec.BuildCache.State},
+		}
+		managementStates := map[v1alpha1.State]string{v1alpha1.Enabled: "Managed", v1alpha1.Disabled: "Removed"}
+		for _, stanza := range stanzas {
+			Expect(stateDefault("v1alpha1", "state", stanza.path...)).To(Equal(string(stanza.state)), "v1alpha1 %s", stanza.path)
+			Expect(stateDefault("v1beta1", "managementState", stanza.path...)).To(Equal(managementStates[stanza.state]), "v1beta1 %s", stanza.path)
+		}
+	})
+})
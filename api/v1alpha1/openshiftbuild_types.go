@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -25,6 +26,10 @@ import (
 const (
 	// ConditionReady object is providing service.
 	ConditionReady = "Ready"
+
+	// ConditionDegraded object is providing service with a degraded component, e.g. rolled back
+	// to a previous release.
+	ConditionDegraded = "Degraded"
 )
 
 // State defines the desired state of a component
@@ -56,6 +61,18 @@ type OpenShiftBuild struct {
 // OpenShiftBuildSpec defines the desired state of Builds for OpenShift components.
 type OpenShiftBuildSpec struct {
 
+	// Namespace is the namespace where the operands are deployed. The operator creates and
+	// labels the namespace if it does not exist, and leaves existing namespaces as they are.
+	// Defaults to the operand namespace configured on the operator, cannot be a system
+	// namespace, and cannot be changed once set.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="namespace is immutable"
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// Shipwright defines the desired state of Shipwright components.
 	//
 	// +kubebuilder:validation:Optional
@@ -67,6 +84,20 @@ type OpenShiftBuildSpec struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	SharedResource *SharedResource `json:"sharedResource,omitempty"`
+
+	// Entitlements shares the RHEL entitlement of the cluster with the builds of the selected
+	// namespaces. The entitlement is not shared when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Entitlements *Entitlements `json:"entitlements,omitempty"`
+
+	// BuildCache keeps the layers of the builds requesting it in persistent volume claims managed
+	// by the operator. Builds start from a cold cache when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	BuildCache *BuildCache `json:"buildCache,omitempty"`
 }
 
 // Shipwright defines the desired state of Shipwright components
@@ -77,6 +108,36 @@ type Shipwright struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	Build *ShipwrightBuild `json:"build,omitempty"`
+
+	// Triggers defines the desired state of the Shipwright Triggers controller, which starts
+	// BuildRuns on Git webhook events. Triggers are disabled when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Triggers *ShipwrightTriggers `json:"triggers,omitempty"`
+
+	// Pruning defines the cluster retention policy of the completed BuildRuns. Completed
+	// BuildRuns are kept until their Build retention removes them when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Pruning *ShipwrightPruning `json:"pruning,omitempty"`
+
+	// Sandboxed generates the "<strategy>-sandboxed" variants of the build strategies installed
+	// by the operator, whose BuildRuns run with a RuntimeClass such as Kata Containers. The
+	// variants are not generated when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Sandboxed *ShipwrightSandboxed `json:"sandboxed,omitempty"`
+
+	// UserNamespaces generates the "<strategy>-userns" variants of the build strategies installed
+	// by the operator, whose pods run in a user namespace under a dedicated
+	// SecurityContextConstraints. The variants are not generated when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	UserNamespaces *ShipwrightUserNamespaces `json:"userNamespaces,omitempty"`
 }
 
 // ShipwrightBuild defines the desired state of Shipwright Builds
@@ -89,6 +150,96 @@ type ShipwrightBuild struct {
 	State `json:"state"`
 }
 
+// ShipwrightTriggers defines the desired state of Shipwright Triggers
+type ShipwrightTriggers struct {
+
+	// State defines the desired state of the Shipwright Triggers controller and the Route
+	// receiving the GitHub, GitLab and generic webhooks. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+}
+
+// ShipwrightPruning defines the cluster retention policy of the completed BuildRuns. The policy
+// applies to the BuildRuns whose Build, or the BuildRun itself, sets no retention, and can be
+// overridden per namespace with annotations.
+type ShipwrightPruning struct {
+
+	// State defines whether the operator prunes the completed BuildRuns, with their TaskRuns and
+	// pods. Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// SucceededLimit is the number of succeeded BuildRuns kept for each Build.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +kubebuilder:validation:Optional
+	// +optional
+	SucceededLimit *int32 `json:"succeededLimit,omitempty"`
+
+	// FailedLimit is the number of failed BuildRuns kept for each Build.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +kubebuilder:validation:Optional
+	// +optional
+	FailedLimit *int32 `json:"failedLimit,omitempty"`
+
+	// TTLAfterSucceeded is the duration a succeeded BuildRun is kept after its completion.
+	//
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:Optional
+	// +optional
+	TTLAfterSucceeded *metav1.Duration `json:"ttlAfterSucceeded,omitempty"`
+
+	// TTLAfterFailed is the duration a failed BuildRun is kept after its completion.
+	//
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:Optional
+	// +optional
+	TTLAfterFailed *metav1.Duration `json:"ttlAfterFailed,omitempty"`
+}
+
+// ShipwrightSandboxed defines the sandboxed variants of the build strategies
+type ShipwrightSandboxed struct {
+
+	// State defines whether the sandboxed variants of the build strategies are generated. Must
+	// be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// RuntimeClassName is the RuntimeClass running the pods of the BuildRuns using a sandboxed
+	// variant. Defaults to kata, the RuntimeClass of OpenShift sandboxed containers.
+	//
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:default="kata"
+	// +kubebuilder:validation:Optional
+	// +optional
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+}
+
+// ShipwrightUserNamespaces defines the user namespace variants of the build strategies
+type ShipwrightUserNamespaces struct {
+
+	// State defines whether the user namespace variants of the build strategies are generated,
+	// along with the SecurityContextConstraints running their pods with hostUsers set to false.
+	// Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// NamespaceSelector selects the namespaces whose service accounts are allowed to use the
+	// SecurityContextConstraints. An empty selector selects all namespaces, and no namespace is
+	// selected when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
 // SharedResource defines the desired state of Shared Resource CSI Driver and components.
 type SharedResource struct {
 
@@ -99,6 +250,69 @@ type SharedResource struct {
 	State `json:"state"`
 }
 
+// Entitlements defines the sharing of the cluster RHEL entitlement with builds
+type Entitlements struct {
+
+	// State defines whether the etc-pki-entitlement secret of the openshift-config-managed
+	// namespace is shared through a SharedSecret. It requires the Shared Resource CSI Driver.
+	// Must be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// NamespaceSelector selects the namespaces whose service accounts are allowed to use the
+	// SharedSecret. An empty selector selects all namespaces, and no namespace is selected when
+	// omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// BuildCache defines the persistent build cache of the buildah and buildpacks strategies
+type BuildCache struct {
+
+	// State defines whether the operator creates the build cache claims and mounts them into
+	// the BuildRuns annotated with operator.openshift.io/build-cache, or whose Build is. Must
+	// be one of Enabled or Disabled.
+	//
+	// +kubebuilder:default="Disabled"
+	State `json:"state"`
+
+	// Scope defines whether the BuildRuns of a Build share a claim, or all the BuildRuns of a
+	// namespace. Must be one of Build or Namespace.
+	//
+	// +kubebuilder:validation:Enum=Build;Namespace
+	// +kubebuilder:default="Build"
+	// +kubebuilder:validation:Optional
+	// +optional
+	Scope BuildCacheScope `json:"scope,omitempty"`
+
+	// StorageClassName is the storage class of the claims. The default storage class of the
+	// cluster is used when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the storage requested by the claims. Defaults to 10Gi.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// BuildCacheScope defines the BuildRuns sharing a build cache claim
+type BuildCacheScope string
+
+const (
+	// BuildCacheScopeBuild shares a claim between the BuildRuns of a Build
+	BuildCacheScopeBuild BuildCacheScope = "Build"
+
+	// BuildCacheScopeNamespace shares a claim between the BuildRuns of a namespace
+	BuildCacheScopeNamespace BuildCacheScope = "Namespace"
+)
+
 // OpenShiftBuildStatus defines the observed state of OpenShiftBuild
 type OpenShiftBuildStatus struct {
 
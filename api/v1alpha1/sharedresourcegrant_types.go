@@ -0,0 +1,83 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// SharedResourceGrant allows service accounts to mount SharedSecrets and SharedConfigMaps with
+// the Shared Resource CSI Driver. The operator binds the use of the shared resources to the
+// service accounts of the selected namespaces, and removes the bindings of the namespaces which
+// are no longer selected.
+type SharedResourceGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SharedResourceGrantSpec   `json:"spec,omitempty"`
+	Status SharedResourceGrantStatus `json:"status,omitempty"`
+}
+
+// SharedResourceGrantSpec defines the shared resources and their grantees
+// +kubebuilder:validation:XValidation:rule="(has(self.sharedSecrets) && size(self.sharedSecrets) > 0) || (has(self.sharedConfigMaps) && size(self.sharedConfigMaps) > 0)",message="at least one SharedSecret or SharedConfigMap must be granted"
+type SharedResourceGrantSpec struct {
+
+	// SharedSecrets are the names of the SharedSecrets granted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	SharedSecrets []string `json:"sharedSecrets,omitempty"`
+
+	// SharedConfigMaps are the names of the SharedConfigMaps granted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	SharedConfigMaps []string `json:"sharedConfigMaps,omitempty"`
+
+	// NamespaceSelector selects the namespaces of the grantees. A nil selector selects no
+	// namespace, and an empty selector selects all namespaces.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	// ServiceAccountSelector restricts the grant to the service accounts matching the selector.
+	// All the service accounts of the selected namespaces are granted when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ServiceAccountSelector *metav1.LabelSelector `json:"serviceAccountSelector,omitempty"`
+
+	// ServiceAccountNames restricts the grant to the service accounts with these names.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ServiceAccountNames []string `json:"serviceAccountNames,omitempty"`
+}
+
+// SharedResourceGrantStatus defines the observed state of SharedResourceGrant
+type SharedResourceGrantStatus struct {
+
+	// Conditions holds the latest available observations of a resource's current state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Grantees are the service accounts, as system:serviceaccount:<namespace>:<name>, and the
+	// namespaces, as system:serviceaccounts:<namespace>, granted the shared resources.
+	Grantees []string `json:"grantees,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SharedResourceGrantList contains a list of SharedResourceGrant
+type SharedResourceGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedResourceGrant `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedResourceGrant{}, &SharedResourceGrantList{})
+}
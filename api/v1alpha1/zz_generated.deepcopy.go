@@ -21,10 +21,347 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildCache) DeepCopyInto(out *BuildCache) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildCache.
+func (in *BuildCache) DeepCopy() *BuildCache {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildConcurrency) DeepCopyInto(out *BuildConcurrency) {
+	*out = *in
+	if in.MaxRunning != nil {
+		in, out := &in.MaxRunning, &out.MaxRunning
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Strategies != nil {
+		in, out := &in.Strategies, &out.Strategies
+		*out = make([]StrategyConcurrency, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildConcurrency.
+func (in *BuildConcurrency) DeepCopy() *BuildConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceConfig) DeepCopyInto(out *BuildNamespaceConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceConfig.
+func (in *BuildNamespaceConfig) DeepCopy() *BuildNamespaceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BuildNamespaceConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceConfigList) DeepCopyInto(out *BuildNamespaceConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BuildNamespaceConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceConfigList.
+func (in *BuildNamespaceConfigList) DeepCopy() *BuildNamespaceConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BuildNamespaceConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceConfigSpec) DeepCopyInto(out *BuildNamespaceConfigSpec) {
+	*out = *in
+	if in.Editors != nil {
+		in, out := &in.Editors, &out.Editors
+		*out = make([]v1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Viewers != nil {
+		in, out := &in.Viewers, &out.Viewers
+		*out = make([]v1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedSecrets != nil {
+		in, out := &in.SharedSecrets, &out.SharedSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedConfigMaps != nil {
+		in, out := &in.SharedConfigMaps, &out.SharedConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Strategies != nil {
+		in, out := &in.Strategies, &out.Strategies
+		*out = make([]BuildStrategyName, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(corev1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(BuildConcurrency)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceConfigSpec.
+func (in *BuildNamespaceConfigSpec) DeepCopy() *BuildNamespaceConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildNamespaceConfigStatus) DeepCopyInto(out *BuildNamespaceConfigStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildNamespaceConfigStatus.
+func (in *BuildNamespaceConfigStatus) DeepCopy() *BuildNamespaceConfigStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildNamespaceConfigStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPolicy) DeepCopyInto(out *BuildPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPolicy.
+func (in *BuildPolicy) DeepCopy() *BuildPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BuildPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPolicyList) DeepCopyInto(out *BuildPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BuildPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPolicyList.
+func (in *BuildPolicyList) DeepCopy() *BuildPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BuildPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPolicyRules) DeepCopyInto(out *BuildPolicyRules) {
+	*out = *in
+	if in.AllowedStrategies != nil {
+		in, out := &in.AllowedStrategies, &out.AllowedStrategies
+		*out = make([]BuildPolicyStrategy, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedOutputRegistries != nil {
+		in, out := &in.AllowedOutputRegistries, &out.AllowedOutputRegistries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedGitHosts != nil {
+		in, out := &in.AllowedGitHosts, &out.AllowedGitHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxTimeout != nil {
+		in, out := &in.MaxTimeout, &out.MaxTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPolicyRules.
+func (in *BuildPolicyRules) DeepCopy() *BuildPolicyRules {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPolicyRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPolicySpec) DeepCopyInto(out *BuildPolicySpec) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.BuildPolicyRules.DeepCopyInto(&out.BuildPolicyRules)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPolicySpec.
+func (in *BuildPolicySpec) DeepCopy() *BuildPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BuildPolicyStrategy) DeepCopyInto(out *BuildPolicyStrategy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildPolicyStrategy.
+func (in *BuildPolicyStrategy) DeepCopy() *BuildPolicyStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(BuildPolicyStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Entitlements) DeepCopyInto(out *Entitlements) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Entitlements.
+func (in *Entitlements) DeepCopy() *Entitlements {
+	if in == nil {
+		return nil
+	}
+	out := new(Entitlements)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenShiftBuild) DeepCopyInto(out *OpenShiftBuild) {
 	*out = *in
@@ -97,6 +434,16 @@ func (in *OpenShiftBuildSpec) DeepCopyInto(out *OpenShiftBuildSpec) {
 		*out = new(SharedResource)
 		**out = **in
 	}
+	if in.Entitlements != nil {
+		in, out := &in.Entitlements, &out.Entitlements
+		*out = new(Entitlements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BuildCache != nil {
+		in, out := &in.BuildCache, &out.BuildCache
+		*out = new(BuildCache)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpenShiftBuildSpec.
@@ -114,7 +461,7 @@ func (in *OpenShiftBuildStatus) DeepCopyInto(out *OpenShiftBuildStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]metav1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -146,6 +493,132 @@ func (in *SharedResource) DeepCopy() *SharedResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedResourceGrant) DeepCopyInto(out *SharedResourceGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceGrant.
+func (in *SharedResourceGrant) DeepCopy() *SharedResourceGrant {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedResourceGrant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedResourceGrant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedResourceGrantList) DeepCopyInto(out *SharedResourceGrantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedResourceGrant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceGrantList.
+func (in *SharedResourceGrantList) DeepCopy() *SharedResourceGrantList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedResourceGrantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedResourceGrantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedResourceGrantSpec) DeepCopyInto(out *SharedResourceGrantSpec) {
+	*out = *in
+	if in.SharedSecrets != nil {
+		in, out := &in.SharedSecrets, &out.SharedSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SharedConfigMaps != nil {
+		in, out := &in.SharedConfigMaps, &out.SharedConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccountSelector != nil {
+		in, out := &in.ServiceAccountSelector, &out.ServiceAccountSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceAccountNames != nil {
+		in, out := &in.ServiceAccountNames, &out.ServiceAccountNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceGrantSpec.
+func (in *SharedResourceGrantSpec) DeepCopy() *SharedResourceGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedResourceGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedResourceGrantStatus) DeepCopyInto(out *SharedResourceGrantStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Grantees != nil {
+		in, out := &in.Grantees, &out.Grantees
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedResourceGrantStatus.
+func (in *SharedResourceGrantStatus) DeepCopy() *SharedResourceGrantStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedResourceGrantStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Shipwright) DeepCopyInto(out *Shipwright) {
 	*out = *in
@@ -154,6 +627,26 @@ func (in *Shipwright) DeepCopyInto(out *Shipwright) {
 		*out = new(ShipwrightBuild)
 		**out = **in
 	}
+	if in.Triggers != nil {
+		in, out := &in.Triggers, &out.Triggers
+		*out = new(ShipwrightTriggers)
+		**out = **in
+	}
+	if in.Pruning != nil {
+		in, out := &in.Pruning, &out.Pruning
+		*out = new(ShipwrightPruning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sandboxed != nil {
+		in, out := &in.Sandboxed, &out.Sandboxed
+		*out = new(ShipwrightSandboxed)
+		**out = **in
+	}
+	if in.UserNamespaces != nil {
+		in, out := &in.UserNamespaces, &out.UserNamespaces
+		*out = new(ShipwrightUserNamespaces)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Shipwright.
@@ -180,3 +673,103 @@ func (in *ShipwrightBuild) DeepCopy() *ShipwrightBuild {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShipwrightPruning) DeepCopyInto(out *ShipwrightPruning) {
+	*out = *in
+	if in.SucceededLimit != nil {
+		in, out := &in.SucceededLimit, &out.SucceededLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailedLimit != nil {
+		in, out := &in.FailedLimit, &out.FailedLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TTLAfterSucceeded != nil {
+		in, out := &in.TTLAfterSucceeded, &out.TTLAfterSucceeded
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.TTLAfterFailed != nil {
+		in, out := &in.TTLAfterFailed, &out.TTLAfterFailed
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipwrightPruning.
+func (in *ShipwrightPruning) DeepCopy() *ShipwrightPruning {
+	if in == nil {
+		return nil
+	}
+	out := new(ShipwrightPruning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShipwrightSandboxed) DeepCopyInto(out *ShipwrightSandboxed) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipwrightSandboxed.
+func (in *ShipwrightSandboxed) DeepCopy() *ShipwrightSandboxed {
+	if in == nil {
+		return nil
+	}
+	out := new(ShipwrightSandboxed)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShipwrightTriggers) DeepCopyInto(out *ShipwrightTriggers) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipwrightTriggers.
+func (in *ShipwrightTriggers) DeepCopy() *ShipwrightTriggers {
+	if in == nil {
+		return nil
+	}
+	out := new(ShipwrightTriggers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ShipwrightUserNamespaces) DeepCopyInto(out *ShipwrightUserNamespaces) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipwrightUserNamespaces.
+func (in *ShipwrightUserNamespaces) DeepCopy() *ShipwrightUserNamespaces {
+	if in == nil {
+		return nil
+	}
+	out := new(ShipwrightUserNamespaces)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StrategyConcurrency) DeepCopyInto(out *StrategyConcurrency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StrategyConcurrency.
+func (in *StrategyConcurrency) DeepCopy() *StrategyConcurrency {
+	if in == nil {
+		return nil
+	}
+	out := new(StrategyConcurrency)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// ConditionReady object is providing service.
+	ConditionReady = "Ready"
+
+	// ConditionDegraded object is providing service with a degraded component, e.g. rolled back
+	// to a previous release.
+	ConditionDegraded = "Degraded"
+)
+
+// ManagementState defines whether a component is managed by the operator
+// +kubebuilder:validation:Enum="Managed";"Removed"
+type ManagementState string
+
+const (
+	// Managed will install the component, including any additional custom resource definitions.
+	Managed ManagementState = "Managed"
+
+	// Removed will remove the component, but may leave behind any custom resource definitions.
+	Removed ManagementState = "Removed"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
+
+// OpenShiftBuild describes the desired state of Builds for OpenShift, and the status of
+// all deployed components.
+type OpenShiftBuild struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpenShiftBuildSpec   `json:"spec,omitempty"`
+	Status OpenShiftBuildStatus `json:"status,omitempty"`
+}
+
+// OpenShiftBuildSpec defines the desired state of Builds for OpenShift components.
+type OpenShiftBuildSpec struct {
+
+	// Config holds the configuration shared by all the components.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Config OperandConfig `json:"config,omitempty"`
+
+	// Shipwright defines the desired state of Shipwright components.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Shipwright *Shipwright `json:"shipwright,omitempty"`
+
+	// SharedResource defines the desired state of the Shared Resource CSI Driver components.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	SharedResource *Component `json:"sharedResource,omitempty"`
+
+	// Entitlements shares the RHEL entitlement of the cluster with the builds of the selected
+	// namespaces. The entitlement is not shared when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Entitlements *Entitlements `json:"entitlements,omitempty"`
+
+	// BuildCache keeps the layers of the builds requesting it in persistent volume claims managed
+	// by the operator. Builds start from a cold cache when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	BuildCache *BuildCache `json:"buildCache,omitempty"`
+}
+
+// OperandConfig holds the configuration shared by all the components
+type OperandConfig struct {
+
+	// Namespace is the namespace where the operands are deployed. The operator creates and
+	// labels the namespace if it does not exist, and leaves existing namespaces as they are.
+	// Defaults to the operand namespace configured on the operator, cannot be a system
+	// namespace, and cannot be changed once set.
+	//
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="namespace is immutable"
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Shipwright defines the desired state of Shipwright components
+type Shipwright struct {
+
+	// Build defines the desired state of Shipwright Build APIs, controllers, and related components.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Build *Component `json:"build,omitempty"`
+
+	// Triggers defines the desired state of the Shipwright Triggers controller, which starts
+	// BuildRuns on Git webhook events, and of the Route receiving the webhooks. Triggers are
+	// removed when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Triggers *Triggers `json:"triggers,omitempty"`
+
+	// Pruning defines the cluster retention policy of the completed BuildRuns. Completed
+	// BuildRuns are kept until their Build retention removes them when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Pruning *Pruning `json:"pruning,omitempty"`
+
+	// Sandboxed generates the "<strategy>-sandboxed" variants of the build strategies installed
+	// by the operator, whose BuildRuns run with a RuntimeClass such as Kata Containers. The
+	// variants are removed when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Sandboxed *Sandboxed `json:"sandboxed,omitempty"`
+
+	// UserNamespaces generates the "<strategy>-userns" variants of the build strategies installed
+	// by the operator, whose pods run in a user namespace under a dedicated
+	// SecurityContextConstraints. The variants are removed when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	UserNamespaces *UserNamespaces `json:"userNamespaces,omitempty"`
+}
+
+// Component defines the desired state of a component deployed by the operator
+type Component struct {
+
+	// ManagementState defines whether the component, its APIs and related components are
+	// deployed by the operator. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Managed"
+	ManagementState ManagementState `json:"managementState"`
+}
+
+// Triggers defines the desired state of the Shipwright Triggers controller
+type Triggers struct {
+
+	// ManagementState defines whether the Shipwright Triggers controller and the Route receiving
+	// the webhooks are deployed by the operator. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Removed"
+	ManagementState ManagementState `json:"managementState"`
+}
+
+// Pruning defines the cluster retention policy of the completed BuildRuns. The policy applies to
+// the BuildRuns whose Build, or the BuildRun itself, sets no retention, and can be overridden per
+// namespace with annotations.
+type Pruning struct {
+
+	// ManagementState defines whether the operator prunes the completed BuildRuns, with their
+	// TaskRuns and pods. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Removed"
+	ManagementState ManagementState `json:"managementState"`
+
+	// SucceededLimit is the number of succeeded BuildRuns kept for each Build.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +kubebuilder:validation:Optional
+	// +optional
+	SucceededLimit *int32 `json:"succeededLimit,omitempty"`
+
+	// FailedLimit is the number of failed BuildRuns kept for each Build.
+	//
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	// +kubebuilder:validation:Optional
+	// +optional
+	FailedLimit *int32 `json:"failedLimit,omitempty"`
+
+	// TTLAfterSucceeded is the duration a succeeded BuildRun is kept after its completion.
+	//
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:Optional
+	// +optional
+	TTLAfterSucceeded *metav1.Duration `json:"ttlAfterSucceeded,omitempty"`
+
+	// TTLAfterFailed is the duration a failed BuildRun is kept after its completion.
+	//
+	// +kubebuilder:validation:Format=duration
+	// +kubebuilder:validation:Optional
+	// +optional
+	TTLAfterFailed *metav1.Duration `json:"ttlAfterFailed,omitempty"`
+}
+
+// Sandboxed defines the sandboxed variants of the build strategies
+type Sandboxed struct {
+
+	// ManagementState defines whether the sandboxed variants of the build strategies are
+	// generated. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Removed"
+	ManagementState ManagementState `json:"managementState"`
+
+	// RuntimeClassName is the RuntimeClass running the pods of the BuildRuns using a sandboxed
+	// variant. Defaults to kata, the RuntimeClass of OpenShift sandboxed containers.
+	//
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:default="kata"
+	// +kubebuilder:validation:Optional
+	// +optional
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+}
+
+// UserNamespaces defines the user namespace variants of the build strategies
+type UserNamespaces struct {
+
+	// ManagementState defines whether the user namespace variants of the build strategies are
+	// generated, along with the SecurityContextConstraints running their pods with hostUsers set
+	// to false. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Removed"
+	ManagementState ManagementState `json:"managementState"`
+
+	// NamespaceSelector selects the namespaces whose service accounts are allowed to use the
+	// SecurityContextConstraints. An empty selector selects all namespaces, and no namespace is
+	// selected when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// Entitlements defines the sharing of the cluster RHEL entitlement with builds
+type Entitlements struct {
+
+	// ManagementState defines whether the etc-pki-entitlement secret of the
+	// openshift-config-managed namespace is shared through a SharedSecret. It requires the
+	// Shared Resource CSI Driver. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Removed"
+	ManagementState ManagementState `json:"managementState"`
+
+	// NamespaceSelector selects the namespaces whose service accounts are allowed to use the
+	// SharedSecret. An empty selector selects all namespaces, and no namespace is selected when
+	// omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// BuildCache defines the persistent build cache of the buildah and buildpacks strategies
+type BuildCache struct {
+
+	// ManagementState defines whether the operator creates the build cache claims and mounts
+	// them into the BuildRuns annotated with operator.openshift.io/build-cache, or whose Build
+	// is. Must be one of Managed or Removed.
+	//
+	// +kubebuilder:default="Removed"
+	ManagementState ManagementState `json:"managementState"`
+
+	// Scope defines whether the BuildRuns of a Build share a claim, or all the BuildRuns of a
+	// namespace. Must be one of Build or Namespace.
+	//
+	// +kubebuilder:validation:Enum=Build;Namespace
+	// +kubebuilder:default="Build"
+	// +kubebuilder:validation:Optional
+	// +optional
+	Scope BuildCacheScope `json:"scope,omitempty"`
+
+	// StorageClassName is the storage class of the claims. The default storage class of the
+	// cluster is used when omitted.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// Size is the storage requested by the claims. Defaults to 10Gi.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// BuildCacheScope defines the BuildRuns sharing a build cache claim
+type BuildCacheScope string
+
+const (
+	// BuildCacheScopeBuild shares a claim between the BuildRuns of a Build
+	BuildCacheScopeBuild BuildCacheScope = "Build"
+
+	// BuildCacheScopeNamespace shares a claim between the BuildRuns of a namespace
+	BuildCacheScopeNamespace BuildCacheScope = "Namespace"
+)
+
+// OpenShiftBuildStatus defines the observed state of OpenShiftBuild
+type OpenShiftBuildStatus struct {
+
+	// Conditions holds the latest available observations of a resource's current state.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OpenShiftBuildList contains a list of OpenShiftBuild
+type OpenShiftBuildList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenShiftBuild `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenShiftBuild{}, &OpenShiftBuildList{})
+}
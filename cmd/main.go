@@ -17,20 +17,32 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"flag"
 	"os"
 
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
 	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	shipwrightoperator "github.com/shipwright-io/operator/controllers"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	operatorv1beta1 "github.com/redhat-openshift-builds/operator/api/v1beta1"
+	"github.com/redhat-openshift-builds/operator/internal/bootstrap"
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/config"
 	"github.com/redhat-openshift-builds/operator/internal/controller"
-	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/health"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"github.com/redhat-openshift-builds/operator/internal/migration"
+	webhookcorev1 "github.com/redhat-openshift-builds/operator/internal/webhook/core/v1"
+	webhookshipwrightv1beta1 "github.com/redhat-openshift-builds/operator/internal/webhook/shipwright/v1beta1"
+	webhookv1alpha1 "github.com/redhat-openshift-builds/operator/internal/webhook/v1alpha1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -39,6 +51,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -51,16 +64,36 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(operatorv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(operatorv1beta1.AddToScheme(scheme))
 	utilruntime.Must(shipwrightv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(buildv1beta1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
 func main() {
+	// Run the BuildConfig converter instead of the operator when requested
+	if len(os.Args) > 1 && os.Args[1] == migration.CommandName {
+		command := &migration.Command{
+			Stdin:  os.Stdin,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+			NewClient: func() (client.Client, error) {
+				cfg, err := ctrl.GetConfig()
+				if err != nil {
+					return nil, err
+				}
+				return client.New(cfg, client.Options{Scheme: scheme})
+			},
+		}
+		os.Exit(command.Run(context.Background(), os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var configOptions config.Options
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -70,6 +103,7 @@ func main() {
 		"If set the metrics endpoint is served securely")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	configOptions.BindFlags(flag.CommandLine)
 	opts := zap.Options{
 		Development: true,
 	}
@@ -101,6 +135,9 @@ func main() {
 			SecureServing: secureMetrics,
 			TLSOpts:       tlsOpts,
 		},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			TLSOpts: tlsOpts,
+		}),
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "02e52450.openshift.io",
@@ -110,14 +147,45 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Fetch the namespace and store for later use
-	namespace := common.FetchCurrentNamespaceName()
+	ctxMain := ctrl.SetupSignalHandler()
+
+	// Create a non-cached client to load the configuration and bootstrap the OpenShiftBuild resource.
+	// If we use the same client as the manager, the bootstrap command will hang waiting for caches
+	// to be populated.
+	boostrapClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create bootstrap client")
+		os.Exit(1)
+	}
+
+	operatorConfig, err := configOptions.Load(ctxMain, boostrapClient)
+	if err != nil {
+		setupLog.Error(err, "unable to load operator configuration")
+		os.Exit(1)
+	}
+
+	// Operands are deployed in openshift-builds unless the configuration sets another namespace
+	if operatorConfig.OperandNamespace != "" {
+		common.OperandNamespaceName = operatorConfig.OperandNamespace
+	}
+	setupLog.Info("default operand namespace", "namespace", common.OperandNamespaceName)
+
+	// Register the components managed by the OpenshiftBuild controller
+	components, err := component.NewRegistry(controller.DefaultComponents(mgr.GetClient(), mgr.GetLogger(), operatorConfig)...)
+	if err != nil {
+		setupLog.Error(err, "unable to register components")
+		os.Exit(1)
+	}
+
+	// Track the reconcile loop for the health probes and the components debug endpoint
+	healthTracker := health.NewTracker(mgr.GetClient(), mgr.Elected())
 
 	// Run OpenshiftBuild controller
 	buildReconciler := &controller.OpenShiftBuildReconciler{
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
-		Shipwright: shipwrightbuild.New(mgr.GetClient(), namespace),
+		Components: components,
+		Health:     healthTracker,
 	}
 
 	if err := buildReconciler.SetupWithManager(mgr); err != nil {
@@ -127,8 +195,15 @@ func main() {
 
 	// Run ShipwrightBuild Controller
 	shipwrightReconciler := &controller.ShipwrightBuildReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		ShipwrightBuildReconciler: shipwrightoperator.ShipwrightBuildReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		},
+		ManifestPath:              operatorConfig.Manifests.ShipwrightBuild,
+		BuildStrategyManifestPath: operatorConfig.Manifests.ShipwrightBuildStrategy,
+		Images:                    images.New(operatorConfig.Images.Overrides, config.Enabled(operatorConfig.Images.RequireDigests)),
+		StagedUpgrades:            config.Enabled(operatorConfig.Features.StagedUpgrades),
+		APIReader:                 mgr.GetAPIReader(),
 	}
 
 	if err := shipwrightReconciler.SetupWithManager(mgr); err != nil {
@@ -136,6 +211,102 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Run the controller rendering the cluster image mirror sets for the build strategies
+	if err := (&controller.RegistriesReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Registries")
+		os.Exit(1)
+	}
+
+	// Run the controller converting the annotated BuildConfigs to Shipwright Builds
+	if config.Enabled(operatorConfig.Features.BuildConfigMigration) {
+		if err := (&controller.BuildConfigMigrationReconciler{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BuildConfigMigration")
+			os.Exit(1)
+		}
+	}
+
+	// Run the controller tagging the images of the BuildRuns with an ImageStreamTag output
+	if config.Enabled(operatorConfig.Features.ImageStreamOutputs) {
+		if err := (&controller.ImageStreamReconciler{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ImageStream")
+			os.Exit(1)
+		}
+	}
+
+	// Run the controller rebuilding the Builds when their triggering ImageStreamTags change
+	if config.Enabled(operatorConfig.Features.ImageStreamTriggers) {
+		if err := (&controller.BuildTriggerReconciler{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BuildTrigger")
+			os.Exit(1)
+		}
+	}
+
+	// Run the controller onboarding the namespaces holding a BuildNamespaceConfig to builds
+	if config.Enabled(operatorConfig.Features.BuildNamespaces) {
+		if err := (&controller.BuildNamespaceConfigReconciler{}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "BuildNamespaceConfig")
+			os.Exit(1)
+		}
+	}
+
+	// Run the controller binding the use of the shared resources of the SharedResourceGrants
+	if err := (&controller.SharedResourceGrantReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SharedResourceGrant")
+		os.Exit(1)
+	}
+
+	// Run the controller pruning the completed BuildRuns per the OpenShiftBuild retention policy
+	if err := (&controller.PruningReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Pruning")
+		os.Exit(1)
+	}
+
+	// Run the controller creating the build cache claims of the Builds requesting them
+	if err := (&controller.BuildCacheReconciler{}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildCache")
+		os.Exit(1)
+	}
+
+	// Run the controller starting the queued BuildRuns within the concurrency limits of their
+	// namespace. It runs regardless of the feature, to start the BuildRuns queued before it was
+	// disabled.
+	if err := (&controller.BuildQueueReconciler{
+		Enabled: config.Enabled(operatorConfig.Features.BuildQueue),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BuildQueue")
+		os.Exit(1)
+	}
+
+	// Serve the OpenShiftBuild admission and conversion webhooks
+	if config.Enabled(operatorConfig.Features.Webhooks) {
+		if err := webhookv1alpha1.SetupOpenShiftBuildWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenShiftBuild")
+			os.Exit(1)
+		}
+		// The BuildRun webhooks always serve the strategy variants, entitlement mounts and build
+		// cache, which are enabled by the OpenShiftBuild
+		buildDefaults := config.Enabled(operatorConfig.Features.BuildDefaults)
+		imageStreamOutputs := config.Enabled(operatorConfig.Features.ImageStreamOutputs)
+		builderImageStreams := config.Enabled(operatorConfig.Features.BuilderImageStreams)
+		buildPolicies := config.Enabled(operatorConfig.Features.BuildPolicies)
+		buildQueue := config.Enabled(operatorConfig.Features.BuildQueue)
+		if err := webhookshipwrightv1beta1.SetupBuildRunWebhookWithManager(mgr, buildDefaults, imageStreamOutputs, builderImageStreams, buildPolicies, buildQueue); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "BuildRun")
+			os.Exit(1)
+		}
+		// The Build webhook rejects the Builds violating the BuildPolicies
+		if err := webhookshipwrightv1beta1.SetupBuildWebhookWithManager(mgr, buildPolicies); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Build")
+			os.Exit(1)
+		}
+		// The Pod webhooks run the pods of the user namespace strategy variants without host
+		// users, and hold the pods of the queued BuildRuns
+		if err := webhookcorev1.SetupPodWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -143,31 +314,52 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		setupLog.Error(err, "unable to set up ready check")
+	if err := mgr.AddHealthzCheck("reconcile", healthTracker.LivezCheck); err != nil {
+		setupLog.Error(err, "unable to set up reconcile health check")
 		os.Exit(1)
 	}
 
-	ctxMain := ctrl.SetupSignalHandler()
+	if err := mgr.AddReadyzCheck("cache-sync", health.CacheSyncCheck(mgr.GetCache())); err != nil {
+		setupLog.Error(err, "unable to set up cache sync ready check")
+		os.Exit(1)
+	}
 
-	// Create a non-cached client to bootstrap the OpenShiftBuild resource.
-	// If we use the same client as the manager, the bootstrap command will hang waiting for caches
-	// to be populated.
-	boostrapClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
-	if err != nil {
-		setupLog.Error(err, "unable to create bootstrap client")
+	if err := mgr.AddReadyzCheck("reconcile", healthTracker.ReadyzCheck); err != nil {
+		setupLog.Error(err, "unable to set up reconcile ready check")
 		os.Exit(1)
 	}
 
-	setupLog.Info("bootstrapping OpenShiftBuild resource")
-	if err := buildReconciler.BootstrapOpenShiftBuild(ctxMain, boostrapClient); err != nil {
-		setupLog.Error(err, "unable to bootstrap OpenShiftBuild resource")
+	if err := mgr.AddMetricsServerExtraHandler("/debug/components", healthTracker); err != nil {
+		setupLog.Error(err, "unable to set up components debug endpoint")
+		os.Exit(1)
+	}
+
+	// Register the startup tasks, run with retries by the elected leader only
+	bootstrapTasks := []bootstrap.Task{}
+	if config.Enabled(operatorConfig.Bootstrap.OpenShiftBuild) {
+		bootstrapTasks = append(bootstrapTasks, bootstrap.Task{
+			Name: "BootstrapOpenShiftBuild",
+			Run: func(ctx context.Context) error {
+				return buildReconciler.BootstrapOpenShiftBuild(ctx, boostrapClient)
+			},
+		})
+	}
+	if config.Enabled(operatorConfig.Bootstrap.CleanupRoleBindings) {
+		bootstrapTasks = append(bootstrapTasks, bootstrap.Task{
+			Name: "CleanupRoleBindings",
+			Run: func(ctx context.Context) error {
+				return buildReconciler.CleanupRoleBindings(ctx, boostrapClient)
+			},
+		})
+	}
+	bootstrapRunner := bootstrap.New(mgr, bootstrapTasks...)
+	if err := mgr.Add(bootstrapRunner); err != nil {
+		setupLog.Error(err, "unable to set up bootstrap tasks")
 		os.Exit(1)
 	}
 
-	setupLog.Info("cleaning up redundant role bindings")
-	if err := buildReconciler.CleanupRoleBindings(ctxMain, boostrapClient); err != nil {
-		setupLog.Error(err, "unable to clean redundant role bindings")
+	if err := mgr.AddReadyzCheck("bootstrap", bootstrapRunner.ReadyzCheck); err != nil {
+		setupLog.Error(err, "unable to set up bootstrap ready check")
 		os.Exit(1)
 	}
 
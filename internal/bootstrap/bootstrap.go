@@ -0,0 +1,127 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// DefaultBackoff is the backoff used to retry failed tasks
+var DefaultBackoff = wait.Backoff{
+	Duration: time.Second,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    10,
+	Cap:      2 * time.Minute,
+}
+
+// Task is a startup task, such as bootstrapping a resource or migrating an older release
+type Task struct {
+	// Name identifies the task in logs and readiness checks.
+	Name string
+
+	// Run performs the task. It must be idempotent, as it is retried until it succeeds.
+	Run func(ctx context.Context) error
+}
+
+// Runner is a manager.Runnable executing startup tasks in order on the elected leader,
+// retrying failed tasks with exponential backoff.
+type Runner struct {
+	Tasks   []Task
+	Backoff wait.Backoff
+	Logger  logr.Logger
+
+	// Elected is closed when the manager is elected leader. Until then, the runner is
+	// reported ready as the tasks are run by the leader only.
+	Elected <-chan struct{}
+
+	mu        sync.RWMutex
+	completed int
+	lastError error
+}
+
+var _ manager.LeaderElectionRunnable = &Runner{}
+
+// New creates new instance of Runner type for the manager
+func New(mgr manager.Manager, tasks ...Task) *Runner {
+	return &Runner{
+		Tasks:   tasks,
+		Backoff: DefaultBackoff,
+		Logger:  mgr.GetLogger().WithName("bootstrap"),
+		Elected: mgr.Elected(),
+	}
+}
+
+// NeedLeaderElection ensures the tasks only run on the elected leader
+func (r *Runner) NeedLeaderElection() bool {
+	return true
+}
+
+// Start runs the tasks in order, retrying each one until it succeeds or the context is done
+func (r *Runner) Start(ctx context.Context) error {
+	for i, task := range r.Tasks {
+		logger := r.Logger.WithValues("task", task.Name)
+		backoff := r.Backoff
+		for {
+			logger.Info("running bootstrap task")
+			err := task.Run(ctx)
+			r.setProgress(i, err)
+			if err == nil {
+				break
+			}
+			delay := backoff.Step()
+			logger.Error(err, "bootstrap task failed, retrying", "after", delay)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+		}
+		r.setProgress(i+1, nil)
+		logger.Info("bootstrap task completed")
+	}
+	return nil
+}
+
+// Done returns true when all the tasks completed successfully
+func (r *Runner) Done() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.completed == len(r.Tasks)
+}
+
+// ReadyzCheck is a healthz.Checker reporting an error until all the tasks are completed. Replicas
+// which are not the elected leader do not run the tasks, and are reported ready.
+func (r *Runner) ReadyzCheck(_ *http.Request) error {
+	if r.Elected != nil {
+		select {
+		case <-r.Elected:
+		default:
+			return nil
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.completed == len(r.Tasks) {
+		return nil
+	}
+	task := r.Tasks[r.completed].Name
+	if r.lastError != nil {
+		return fmt.Errorf("bootstrap task %q has not completed: %v", task, r.lastError)
+	}
+	return fmt.Errorf("bootstrap task %q has not completed", task)
+}
+
+func (r *Runner) setProgress(completed int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed = completed
+	r.lastError = err
+}
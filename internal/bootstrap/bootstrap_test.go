@@ -0,0 +1,100 @@
+package bootstrap_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	"github.com/redhat-openshift-builds/operator/internal/bootstrap"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var _ = Describe("Runner", Label("bootstrap"), func() {
+	var (
+		ctx     context.Context
+		cancel  context.CancelFunc
+		elected chan struct{}
+		runner  *bootstrap.Runner
+		calls   atomic.Int32
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		elected = make(chan struct{})
+		calls.Store(0)
+		runner = &bootstrap.Runner{
+			Backoff: wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: 1},
+			Logger:  logr.Discard(),
+			Elected: elected,
+		}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	It("requires leader election", func() {
+		Expect(runner.NeedLeaderElection()).To(BeTrue())
+	})
+
+	When("the manager is not the elected leader", func() {
+		BeforeEach(func() {
+			runner.Tasks = []bootstrap.Task{{Name: "task", Run: func(context.Context) error { return nil }}}
+		})
+
+		It("reports ready without running the tasks", func() {
+			Expect(runner.ReadyzCheck(nil)).To(Succeed())
+			Expect(runner.Done()).To(BeFalse())
+		})
+	})
+
+	When("a task fails transiently", func() {
+		BeforeEach(func() {
+			close(elected)
+			runner.Tasks = []bootstrap.Task{
+				{
+					Name: "flaky",
+					Run: func(context.Context) error {
+						if calls.Add(1) < 3 {
+							return errors.New("transient error")
+						}
+						return nil
+					},
+				},
+			}
+		})
+
+		It("retries until the task succeeds", func() {
+			Expect(runner.ReadyzCheck(nil)).To(MatchError(ContainSubstring(`"flaky"`)))
+			Expect(runner.Start(ctx)).To(Succeed())
+			Expect(calls.Load()).To(BeNumerically("==", 3))
+			Expect(runner.Done()).To(BeTrue())
+			Expect(runner.ReadyzCheck(nil)).To(Succeed())
+		})
+	})
+
+	When("a task keeps failing", func() {
+		BeforeEach(func() {
+			close(elected)
+			runner.Tasks = []bootstrap.Task{
+				{Name: "broken", Run: func(context.Context) error { return errors.New("permanent error") }},
+				{Name: "next", Run: func(context.Context) error { calls.Add(1); return nil }},
+			}
+		})
+
+		It("reports the failure in readiness and stops when the context is done", func() {
+			done := make(chan error)
+			go func() { done <- runner.Start(ctx) }()
+			Eventually(func() error { return runner.ReadyzCheck(nil) }).Should(MatchError(ContainSubstring("permanent error")))
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+			Expect(calls.Load()).To(BeZero())
+			Expect(runner.Done()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,109 @@
+package buildcache
+
+import (
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Annotation requests a Build or BuildRun to mount the build cache
+const Annotation = "operator.openshift.io/build-cache"
+
+// Label is set on the build cache claims created by the operator
+const Label = "operator.openshift.io/build-cache"
+
+// VolumeName is the overridable volume of the build strategies holding their cache: the
+// container storage of buildah, and the layer cache of buildpacks
+const VolumeName = "cache-dir"
+
+// NamespaceClaimName is the name of the claim shared by the BuildRuns of a namespace
+const NamespaceClaimName = "build-cache"
+
+// DefaultSize is the storage requested by the claims when the OpenShiftBuild sets none
+var DefaultSize = resource.MustParse("10Gi")
+
+// Enabled returns true when the OpenShiftBuild enables the build cache
+func Enabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return owner.Spec.BuildCache != nil && owner.Spec.BuildCache.State == openshiftv1alpha1.Enabled
+}
+
+// Requested returns true when the BuildRun, or else its Build, is annotated to mount the cache.
+// Either may be nil.
+func Requested(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.Build) bool {
+	var value string
+	var ok bool
+	if buildRun != nil {
+		value, ok = buildRun.Annotations[Annotation]
+	}
+	if !ok && build != nil {
+		value, ok = build.Annotations[Annotation]
+	}
+	return ok && value == "true"
+}
+
+// ClaimName returns the name of the cache claim of the Build, or the name shared by the
+// namespace. The Build may be nil for the BuildRuns embedding their Build spec, which only
+// share the cache of their namespace.
+func ClaimName(owner *openshiftv1alpha1.OpenShiftBuild, build *buildv1beta1.Build) (string, error) {
+	if owner.Spec.BuildCache != nil && owner.Spec.BuildCache.Scope == openshiftv1alpha1.BuildCacheScopeNamespace {
+		return NamespaceClaimName, nil
+	}
+	if build == nil {
+		return "", fmt.Errorf("the build cache is scoped to Builds, and the BuildRun references none")
+	}
+	name := "build-cache-" + build.Name
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return "", fmt.Errorf("invalid build cache claim name %q: %v", name, msgs)
+	}
+	return name, nil
+}
+
+// Claim returns the desired cache claim of the namespace, with the storage class and size of
+// the OpenShiftBuild
+func Claim(owner *openshiftv1alpha1.OpenShiftBuild, namespace, name string) *corev1.PersistentVolumeClaim {
+	size := DefaultSize
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{Label: "true"},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		},
+	}
+	if buildCache := owner.Spec.BuildCache; buildCache != nil {
+		claim.Spec.StorageClassName = buildCache.StorageClassName
+		if buildCache.Size != nil {
+			size = buildCache.Size.DeepCopy()
+		}
+	}
+	claim.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: size}
+	return claim
+}
+
+// Mount overrides the cache volume of the strategy with the claim, unless the BuildRun or its
+// Build already sets the volume. It returns whether the BuildRun was changed.
+func Mount(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec, claimName string) bool {
+	volumes := buildRun.Spec.Volumes
+	if build != nil {
+		volumes = append(append([]buildv1beta1.BuildVolume{}, volumes...), build.Volumes...)
+	}
+	for _, volume := range volumes {
+		if volume.Name == VolumeName {
+			return false
+		}
+	}
+	buildRun.Spec.Volumes = append(buildRun.Spec.Volumes, buildv1beta1.BuildVolume{
+		Name: VolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+		},
+	})
+	return true
+}
@@ -0,0 +1,13 @@
+package buildcache_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBuildCache(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BuildCache Suite")
+}
@@ -0,0 +1,99 @@
+package buildcache_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildcache"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("BuildCache", Label("buildcache"), func() {
+	var (
+		owner *openshiftv1alpha1.OpenShiftBuild
+		build *buildv1beta1.Build
+	)
+
+	BeforeEach(func() {
+		owner = &openshiftv1alpha1.OpenShiftBuild{}
+		owner.Default()
+		owner.Spec.BuildCache.State = openshiftv1alpha1.Enabled
+		build = &buildv1beta1.Build{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "team",
+			Name:        "app",
+			Annotations: map[string]string{buildcache.Annotation: "true"},
+		}}
+	})
+
+	Describe("Requested", func() {
+		It("prefers the annotation of the BuildRun", func() {
+			buildRun := &buildv1beta1.BuildRun{ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{buildcache.Annotation: "false"},
+			}}
+			Expect(buildcache.Requested(buildRun, build)).To(BeFalse())
+			Expect(buildcache.Requested(&buildv1beta1.BuildRun{}, build)).To(BeTrue())
+			Expect(buildcache.Requested(&buildv1beta1.BuildRun{}, nil)).To(BeFalse())
+		})
+	})
+
+	Describe("ClaimName", func() {
+		It("names the claim after the Build", func() {
+			Expect(buildcache.ClaimName(owner, build)).To(Equal("build-cache-app"))
+			_, err := buildcache.ClaimName(owner, nil)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("shares the claim of the namespace", func() {
+			owner.Spec.BuildCache.Scope = openshiftv1alpha1.BuildCacheScopeNamespace
+			Expect(buildcache.ClaimName(owner, build)).To(Equal(buildcache.NamespaceClaimName))
+			Expect(buildcache.ClaimName(owner, nil)).To(Equal(buildcache.NamespaceClaimName))
+		})
+
+		It("rejects the names exceeding the claim name length", func() {
+			build.Name = strings.Repeat("a", 250)
+			_, err := buildcache.ClaimName(owner, build)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Claim", func() {
+		It("requests the default size from the default storage class", func() {
+			claim := buildcache.Claim(owner, "team", "build-cache-app")
+			Expect(claim.Labels).To(HaveKeyWithValue(buildcache.Label, "true"))
+			Expect(claim.Spec.StorageClassName).To(BeNil())
+			Expect(claim.Spec.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+			Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+		})
+
+		It("uses the storage class and size of the OpenShiftBuild", func() {
+			owner.Spec.BuildCache.StorageClassName = ptr.To("fast")
+			owner.Spec.BuildCache.Size = ptr.To(resource.MustParse("50Gi"))
+			claim := buildcache.Claim(owner, "team", "build-cache-app")
+			Expect(claim.Spec.StorageClassName).To(HaveValue(Equal("fast")))
+			Expect(claim.Spec.Resources.Requests.Storage().String()).To(Equal("50Gi"))
+		})
+	})
+
+	Describe("Mount", func() {
+		It("overrides the cache volume with the claim", func() {
+			buildRun := &buildv1beta1.BuildRun{}
+			Expect(buildcache.Mount(buildRun, &build.Spec, "build-cache-app")).To(BeTrue())
+			Expect(buildRun.Spec.Volumes).To(HaveLen(1))
+			Expect(buildRun.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("build-cache-app"))
+		})
+
+		It("keeps the cache volume of the Build", func() {
+			build.Spec.Volumes = []buildv1beta1.BuildVolume{{Name: buildcache.VolumeName}}
+			buildRun := &buildv1beta1.BuildRun{}
+			Expect(buildcache.Mount(buildRun, &build.Spec, "build-cache-app")).To(BeFalse())
+			Expect(buildRun.Spec.Volumes).To(BeEmpty())
+		})
+	})
+})
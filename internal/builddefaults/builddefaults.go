@@ -0,0 +1,206 @@
+package builddefaults
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterBuildName is the name of the cluster build configuration
+const ClusterBuildName = "cluster"
+
+// ClusterBuildGVK is the kind of the cluster build configuration shared with BuildConfig builds
+var ClusterBuildGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Build"}
+
+// ImageLabel is a label applied to the built image
+type ImageLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// Proxy holds the proxy settings of the builds
+type Proxy struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty"`
+}
+
+// Defaults are applied to the builds which do not set the corresponding values
+type Defaults struct {
+	DefaultProxy *Proxy                      `json:"defaultProxy,omitempty"`
+	Env          []corev1.EnvVar             `json:"env,omitempty"`
+	ImageLabels  []ImageLabel                `json:"imageLabels,omitempty"`
+	Resources    corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// Overrides are applied to all the builds, replacing the values they set
+type Overrides struct {
+	ImageLabels  []ImageLabel        `json:"imageLabels,omitempty"`
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// Spec holds the fields of the build.config.openshift.io specification applied to Shipwright
+// BuildRuns. Other fields, such as the git proxy and forcePull, have no Shipwright equivalent.
+type Spec struct {
+	BuildDefaults  Defaults  `json:"buildDefaults,omitempty"`
+	BuildOverrides Overrides `json:"buildOverrides,omitempty"`
+}
+
+// Get returns the specification of the cluster build configuration. An empty specification is
+// returned when the configuration does not exist or is not served by the cluster.
+func Get(ctx context.Context, reader client.Reader) (*Spec, error) {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(ClusterBuildGVK)
+	if err := reader.Get(ctx, types.NamespacedName{Name: ClusterBuildName}, object); err != nil {
+		if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+			return &Spec{}, nil
+		}
+		return nil, err
+	}
+	spec := &Spec{}
+	content, _, err := unstructured.NestedMap(object.Object, "spec")
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// IsEmpty returns true when the specification has nothing to apply
+func (s *Spec) IsEmpty() bool {
+	return s.BuildDefaults.DefaultProxy == nil &&
+		len(s.BuildDefaults.Env) == 0 &&
+		len(s.BuildDefaults.ImageLabels) == 0 &&
+		len(s.BuildDefaults.Resources.Limits) == 0 &&
+		len(s.BuildDefaults.Resources.Requests) == 0 &&
+		len(s.BuildOverrides.ImageLabels) == 0 &&
+		len(s.BuildOverrides.NodeSelector) == 0 &&
+		len(s.BuildOverrides.Tolerations) == 0
+}
+
+// Apply sets the defaults and overrides on the BuildRun. The build is the specification of the
+// referenced or embedded Build, if known, and steps are the steps of its build strategy.
+func (s *Spec) Apply(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec, steps []buildv1beta1.Step) {
+	if buildRun.Spec.Build.Spec != nil {
+		build = buildRun.Spec.Build.Spec
+	}
+	s.applyEnv(buildRun, build)
+	s.applyImageLabels(buildRun, build)
+	s.applyResources(buildRun, build, steps)
+	s.applyScheduling(buildRun)
+}
+
+// applyEnv adds the default environment variables, including the proxy ones, which are not set
+// on the Build or BuildRun
+func (s *Spec) applyEnv(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec) {
+	var env []corev1.EnvVar
+	if proxy := s.BuildDefaults.DefaultProxy; proxy != nil {
+		for _, e := range []corev1.EnvVar{
+			{Name: "HTTP_PROXY", Value: proxy.HTTPProxy},
+			{Name: "HTTPS_PROXY", Value: proxy.HTTPSProxy},
+			{Name: "NO_PROXY", Value: proxy.NoProxy},
+		} {
+			if e.Value != "" {
+				env = append(env, e, corev1.EnvVar{Name: strings.ToLower(e.Name), Value: e.Value})
+			}
+		}
+	}
+	env = append(env, s.BuildDefaults.Env...)
+
+	set := func(name string) bool {
+		has := func(e corev1.EnvVar) bool { return e.Name == name }
+		return slices.ContainsFunc(buildRun.Spec.Env, has) || (build != nil && slices.ContainsFunc(build.Env, has))
+	}
+	for _, e := range env {
+		if !set(e.Name) {
+			buildRun.Spec.Env = append(buildRun.Spec.Env, e)
+		}
+	}
+}
+
+// applyImageLabels sets the default and override labels of the output image. The output of the
+// Build is copied to the BuildRun when the BuildRun does not override it.
+func (s *Spec) applyImageLabels(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec) {
+	if len(s.BuildDefaults.ImageLabels) == 0 && len(s.BuildOverrides.ImageLabels) == 0 {
+		return
+	}
+	output := buildRun.Spec.Output
+	if buildRun.Spec.Build.Spec != nil {
+		if output == nil {
+			output = &buildRun.Spec.Build.Spec.Output
+		}
+	} else if output == nil {
+		if build == nil {
+			return
+		}
+		output = build.Output.DeepCopy()
+		buildRun.Spec.Output = output
+	}
+
+	if output.Labels == nil {
+		output.Labels = map[string]string{}
+	}
+	for _, label := range s.BuildDefaults.ImageLabels {
+		if _, found := output.Labels[label.Name]; !found {
+			output.Labels[label.Name] = label.Value
+		}
+	}
+	for _, label := range s.BuildOverrides.ImageLabels {
+		output.Labels[label.Name] = label.Value
+	}
+}
+
+// applyResources sets the default resources on the strategy steps which set none, unless they
+// are overridden by the Build or BuildRun
+func (s *Spec) applyResources(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec, steps []buildv1beta1.Step) {
+	resources := s.BuildDefaults.Resources
+	if len(resources.Limits) == 0 && len(resources.Requests) == 0 {
+		return
+	}
+	overridden := func(name string) bool {
+		has := func(o buildv1beta1.StepResourceOverride) bool { return o.Name == name }
+		return slices.ContainsFunc(buildRun.Spec.StepResources, has) ||
+			(build != nil && slices.ContainsFunc(build.Strategy.StepResources, has))
+	}
+	for _, step := range steps {
+		if len(step.Resources.Limits) > 0 || len(step.Resources.Requests) > 0 || overridden(step.Name) {
+			continue
+		}
+		buildRun.Spec.StepResources = append(buildRun.Spec.StepResources, buildv1beta1.StepResourceOverride{
+			Name:      step.Name,
+			Resources: *resources.DeepCopy(),
+		})
+	}
+}
+
+// applyScheduling sets the node selector and tolerations overrides
+func (s *Spec) applyScheduling(buildRun *buildv1beta1.BuildRun) {
+	if len(s.BuildOverrides.NodeSelector) > 0 {
+		if buildRun.Spec.NodeSelector == nil {
+			buildRun.Spec.NodeSelector = map[string]string{}
+		}
+		maps.Copy(buildRun.Spec.NodeSelector, s.BuildOverrides.NodeSelector)
+	}
+	for _, toleration := range s.BuildOverrides.Tolerations {
+		i := slices.IndexFunc(buildRun.Spec.Tolerations, func(t corev1.Toleration) bool { return t.Key == toleration.Key })
+		if i < 0 {
+			buildRun.Spec.Tolerations = append(buildRun.Spec.Tolerations, toleration)
+			continue
+		}
+		buildRun.Spec.Tolerations[i] = toleration
+	}
+}
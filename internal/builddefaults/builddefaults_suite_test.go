@@ -0,0 +1,13 @@
+package builddefaults_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBuildDefaults(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Build Defaults Suite")
+}
@@ -0,0 +1,170 @@
+package builddefaults_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/builddefaults"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+const clusterBuild = `
+apiVersion: config.openshift.io/v1
+kind: Build
+metadata:
+  name: cluster
+spec:
+  additionalTrustedCA:
+    name: trusted-ca
+  buildDefaults:
+    defaultProxy:
+      httpProxy: http://proxy.example.com:3128
+      noProxy: .cluster.local
+    env:
+    - name: GOPROXY
+      value: https://goproxy.example.com
+    - name: MAVEN_MIRROR_URL
+      value: https://maven.example.com
+    imageLabels:
+    - name: vendor
+      value: Example
+    - name: team
+      value: platform
+    resources:
+      requests:
+        cpu: 500m
+  buildOverrides:
+    forcePull: true
+    imageLabels:
+    - name: io.openshift.build.policy
+      value: enforced
+    nodeSelector:
+      node-role.kubernetes.io/builder: ""
+    tolerations:
+    - key: builds
+      operator: Exists
+      effect: NoSchedule
+`
+
+var _ = Describe("Build defaults", Label("builddefaults"), func() {
+	var (
+		ctx  context.Context
+		spec *builddefaults.Spec
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		object := &unstructured.Unstructured{}
+		Expect(yaml.Unmarshal([]byte(clusterBuild), &object.Object)).To(Succeed())
+		reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(object).Build()
+		var err error
+		spec, err = builddefaults.Get(ctx, reader)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Get", func() {
+		It("reads the cluster build configuration", func() {
+			Expect(spec.IsEmpty()).To(BeFalse())
+			Expect(spec.BuildDefaults.DefaultProxy.HTTPProxy).To(Equal("http://proxy.example.com:3128"))
+			Expect(spec.BuildDefaults.Env).To(HaveLen(2))
+			Expect(spec.BuildOverrides.NodeSelector).To(HaveKey("node-role.kubernetes.io/builder"))
+		})
+
+		It("returns an empty specification without cluster build configuration", func() {
+			spec, err := builddefaults.Get(ctx, fake.NewClientBuilder().WithScheme(scheme.Scheme).Build())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(spec.IsEmpty()).To(BeTrue())
+		})
+	})
+
+	Describe("Apply", func() {
+		var (
+			build    *buildv1beta1.BuildSpec
+			buildRun *buildv1beta1.BuildRun
+			steps    []buildv1beta1.Step
+		)
+
+		BeforeEach(func() {
+			build = &buildv1beta1.BuildSpec{
+				Env: []corev1.EnvVar{{Name: "GOPROXY", Value: "direct"}},
+				Output: buildv1beta1.Image{
+					Image:  "image-registry.openshift-image-registry.svc:5000/test/app",
+					Labels: map[string]string{"team": "apps"},
+				},
+			}
+			buildRun = &buildv1beta1.BuildRun{
+				Spec: buildv1beta1.BuildRunSpec{
+					Build:       buildv1beta1.ReferencedBuild{Name: ptr.To("app")},
+					Env:         []corev1.EnvVar{{Name: "NO_PROXY", Value: "example.com"}},
+					Tolerations: []corev1.Toleration{{Key: "builds", Operator: corev1.TolerationOpEqual, Value: "true"}},
+				},
+			}
+			steps = []buildv1beta1.Step{
+				{Name: "build-and-push"},
+				{Name: "push", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				}},
+			}
+		})
+
+		It("adds the default environment variables which are not set", func() {
+			spec.Apply(buildRun, build, steps)
+			Expect(buildRun.Spec.Env).To(Equal([]corev1.EnvVar{
+				{Name: "NO_PROXY", Value: "example.com"},
+				{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"},
+				{Name: "http_proxy", Value: "http://proxy.example.com:3128"},
+				{Name: "no_proxy", Value: ".cluster.local"},
+				{Name: "MAVEN_MIRROR_URL", Value: "https://maven.example.com"},
+			}))
+		})
+
+		It("sets the default and override image labels on the output of the Build", func() {
+			spec.Apply(buildRun, build, steps)
+			Expect(buildRun.Spec.Output).NotTo(BeNil())
+			Expect(buildRun.Spec.Output.Image).To(Equal(build.Output.Image))
+			Expect(buildRun.Spec.Output.Labels).To(Equal(map[string]string{
+				"team":                      "apps",
+				"vendor":                    "Example",
+				"io.openshift.build.policy": "enforced",
+			}))
+			Expect(build.Output.Labels).To(HaveLen(1))
+		})
+
+		It("sets the image labels on the embedded Build", func() {
+			buildRun.Spec.Build = buildv1beta1.ReferencedBuild{Spec: build}
+			spec.Apply(buildRun, nil, steps)
+			Expect(buildRun.Spec.Output).To(BeNil())
+			Expect(buildRun.Spec.Build.Spec.Output.Labels).To(HaveKeyWithValue("io.openshift.build.policy", "enforced"))
+		})
+
+		It("sets the default resources on the steps without resources", func() {
+			spec.Apply(buildRun, build, steps)
+			Expect(buildRun.Spec.StepResources).To(HaveLen(1))
+			Expect(buildRun.Spec.StepResources[0].Name).To(Equal("build-and-push"))
+			Expect(buildRun.Spec.StepResources[0].Resources.Requests.Cpu().String()).To(Equal("500m"))
+		})
+
+		It("does not override the step resources set on the Build", func() {
+			build.Strategy.StepResources = []buildv1beta1.StepResourceOverride{{Name: "build-and-push"}}
+			spec.Apply(buildRun, build, steps)
+			Expect(buildRun.Spec.StepResources).To(BeEmpty())
+		})
+
+		It("overrides the node selector and tolerations", func() {
+			spec.Apply(buildRun, build, steps)
+			Expect(buildRun.Spec.NodeSelector).To(Equal(map[string]string{"node-role.kubernetes.io/builder": ""}))
+			Expect(buildRun.Spec.Tolerations).To(Equal([]corev1.Toleration{
+				{Key: "builds", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+			}))
+		})
+	})
+})
@@ -0,0 +1,257 @@
+package buildpolicy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Rules of the BuildPolicies, used as the rule label of the violation metric
+const (
+	RuleStrategy       = "allowedStrategies"
+	RuleOutputRegistry = "allowedOutputRegistries"
+	RuleGitHost        = "allowedGitHosts"
+	RuleTimeout        = "maxTimeout"
+	RuleLabels         = "requiredLabels"
+)
+
+// ReasonViolation is the reason of the events recorded for the Builds and BuildRuns violating
+// their BuildPolicy
+const ReasonViolation = "BuildPolicyViolation"
+
+// Wildcard allows any value in the lists of the rules
+const Wildcard = "*"
+
+// Violations counts the Builds and BuildRuns rejected for violating a rule of their BuildPolicy
+var Violations = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "openshift_builds_policy_violations_total",
+	Help: "Number of Builds and BuildRuns rejected for violating a BuildPolicy",
+}, []string{"namespace", "kind", "rule"})
+
+func init() {
+	metrics.Registry.MustRegister(Violations)
+}
+
+// Subject is the effective specification of a Build or BuildRun checked against the rules
+type Subject struct {
+	// Kind is the kind of the object, Build or BuildRun.
+	Kind string
+
+	// Strategy is the build strategy of the object.
+	Strategy buildv1beta1.Strategy
+
+	// Output is the image pushed by the object.
+	Output string
+
+	// Source is the source of the object, if any.
+	Source *buildv1beta1.Source
+
+	// Timeout is the timeout of the object, if any.
+	Timeout *metav1.Duration
+
+	// Labels are the labels of the Build, or of the BuildRuns embedding their Build
+	// specification.
+	Labels map[string]string
+}
+
+// Violation is a rule violated by a Build or BuildRun
+type Violation struct {
+	Rule    string
+	Message string
+}
+
+// String returns the message of the violation
+func (v Violation) String() string {
+	return v.Message
+}
+
+// RulesFor returns the rules of the namespace: the rules of the cluster BuildPolicy overridden
+// by the BuildPolicies selecting the namespace, applied in the order of their names. It returns
+// the names of the BuildPolicies applied along with the rules.
+func RulesFor(ctx context.Context, reader client.Reader, namespace *corev1.Namespace) (openshiftv1alpha1.BuildPolicyRules, []string, error) {
+	rules := openshiftv1alpha1.BuildPolicyRules{}
+	list := &openshiftv1alpha1.BuildPolicyList{}
+	if err := reader.List(ctx, list); err != nil {
+		return rules, nil, fmt.Errorf("failed to list the BuildPolicies: %v", err)
+	}
+	policies := list.Items
+	slices.SortFunc(policies, func(a, b openshiftv1alpha1.BuildPolicy) int {
+		if a.Name == openshiftv1alpha1.ClusterBuildPolicyName {
+			return -1
+		}
+		if b.Name == openshiftv1alpha1.ClusterBuildPolicyName {
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	applied := []string{}
+	for _, policy := range policies {
+		if policy.Name != openshiftv1alpha1.ClusterBuildPolicyName {
+			if policy.Spec.NamespaceSelector == nil {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(policy.Spec.NamespaceSelector)
+			if err != nil {
+				return rules, nil, fmt.Errorf("invalid namespace selector of BuildPolicy %s: %v", policy.Name, err)
+			}
+			if !selector.Matches(labels.Set(namespace.Labels)) {
+				continue
+			}
+		}
+		override(&rules, &policy.Spec.BuildPolicyRules)
+		applied = append(applied, policy.Name)
+	}
+	return rules, applied, nil
+}
+
+// override replaces the rules set by the overriding rules
+func override(rules, overriding *openshiftv1alpha1.BuildPolicyRules) {
+	if len(overriding.AllowedStrategies) > 0 {
+		rules.AllowedStrategies = overriding.AllowedStrategies
+	}
+	if len(overriding.AllowedOutputRegistries) > 0 {
+		rules.AllowedOutputRegistries = overriding.AllowedOutputRegistries
+	}
+	if len(overriding.AllowedGitHosts) > 0 {
+		rules.AllowedGitHosts = overriding.AllowedGitHosts
+	}
+	if overriding.MaxTimeout != nil {
+		rules.MaxTimeout = overriding.MaxTimeout
+	}
+	if len(overriding.RequiredLabels) > 0 {
+		rules.RequiredLabels = overriding.RequiredLabels
+	}
+}
+
+// Check returns the rules violated by the subject
+func Check(rules openshiftv1alpha1.BuildPolicyRules, subject Subject) []Violation {
+	violations := []Violation{}
+	if len(rules.AllowedStrategies) > 0 && !strategyAllowed(rules.AllowedStrategies, subject.Strategy) {
+		violations = append(violations, Violation{
+			Rule:    RuleStrategy,
+			Message: fmt.Sprintf("%s %s is not an allowed strategy", strategyKind(subject.Strategy), subject.Strategy.Name),
+		})
+	}
+	if len(rules.AllowedOutputRegistries) > 0 && subject.Output != "" && !registryAllowed(rules.AllowedOutputRegistries, subject.Output) {
+		violations = append(violations, Violation{
+			Rule:    RuleOutputRegistry,
+			Message: fmt.Sprintf("output image %s is not in an allowed registry", subject.Output),
+		})
+	}
+	if len(rules.AllowedGitHosts) > 0 && subject.Source != nil && subject.Source.Git != nil {
+		host := GitHost(subject.Source.Git.URL)
+		if !slices.ContainsFunc(rules.AllowedGitHosts, func(pattern string) bool { return hostMatches(pattern, host) }) {
+			violations = append(violations, Violation{
+				Rule:    RuleGitHost,
+				Message: fmt.Sprintf("Git host %q of %s is not allowed", host, subject.Source.Git.URL),
+			})
+		}
+	}
+	if rules.MaxTimeout != nil && subject.Timeout != nil && subject.Timeout.Duration > rules.MaxTimeout.Duration {
+		violations = append(violations, Violation{
+			Rule:    RuleTimeout,
+			Message: fmt.Sprintf("timeout %s exceeds the maximum of %s", subject.Timeout.Duration, rules.MaxTimeout.Duration),
+		})
+	}
+	for _, key := range rules.RequiredLabels {
+		if _, ok := subject.Labels[key]; !ok {
+			violations = append(violations, Violation{
+				Rule:    RuleLabels,
+				Message: fmt.Sprintf("required label %s is missing", key),
+			})
+		}
+	}
+	return violations
+}
+
+// GitHost returns the host of a Git URL, either a URL or a scp-like "user@host:path" location
+func GitHost(location string) string {
+	if strings.Contains(location, "://") {
+		parsed, err := url.Parse(location)
+		if err != nil {
+			return ""
+		}
+		return strings.ToLower(parsed.Hostname())
+	}
+	host, _, _ := strings.Cut(location, ":")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	return strings.ToLower(host)
+}
+
+// Registry returns the registry host and the repository of an image reference, defaulting to
+// docker.io like the container tools
+func Registry(image string) (string, string) {
+	name, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name = name[:i]
+	}
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return strings.ToLower(first), rest
+	}
+	return "docker.io", name
+}
+
+// strategyAllowed returns whether the strategy is allowed. Strategies without a kind are
+// namespaced BuildStrategies, as in Shipwright.
+func strategyAllowed(allowed []openshiftv1alpha1.BuildPolicyStrategy, strategy buildv1beta1.Strategy) bool {
+	kind := strategyKind(strategy)
+	return slices.ContainsFunc(allowed, func(allowed openshiftv1alpha1.BuildPolicyStrategy) bool {
+		allowedKind := allowed.Kind
+		if allowedKind == "" {
+			allowedKind = string(buildv1beta1.ClusterBuildStrategyKind)
+		}
+		return allowedKind == kind && (allowed.Name == Wildcard || allowed.Name == strategy.Name)
+	})
+}
+
+// strategyKind returns the kind of the strategy
+func strategyKind(strategy buildv1beta1.Strategy) string {
+	if strategy.Kind == nil {
+		return string(buildv1beta1.NamespacedBuildStrategyKind)
+	}
+	return string(*strategy.Kind)
+}
+
+// registryAllowed returns whether the image is pushed to an allowed registry, matching its host
+// and, if any, the repository prefix of the allowed registry
+func registryAllowed(allowed []string, image string) bool {
+	host, repository := Registry(image)
+	return slices.ContainsFunc(allowed, func(pattern string) bool {
+		if pattern == Wildcard {
+			return true
+		}
+		patternHost, prefix, hasPrefix := strings.Cut(pattern, "/")
+		if !hostMatches(patternHost, host) {
+			return false
+		}
+		return !hasPrefix || repository == prefix || strings.HasPrefix(repository, strings.TrimSuffix(prefix, "/")+"/")
+	})
+}
+
+// hostMatches returns whether the host matches the pattern, a host, a "*.example.com" pattern
+// matching the subdomains of example.com, or the wildcard
+func hostMatches(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	if pattern == Wildcard || pattern == host {
+		return true
+	}
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return false
+}
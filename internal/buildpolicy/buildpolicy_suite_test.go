@@ -0,0 +1,13 @@
+package buildpolicy_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBuildPolicy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "BuildPolicy Suite")
+}
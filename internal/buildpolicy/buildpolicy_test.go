@@ -0,0 +1,171 @@
+package buildpolicy_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildpolicy"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BuildPolicy", Label("buildpolicy"), func() {
+	Describe("RulesFor", func() {
+		var (
+			ctx       context.Context
+			k8sClient client.Client
+			namespace *corev1.Namespace
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+			k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				&openshiftv1alpha1.BuildPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+					Spec: openshiftv1alpha1.BuildPolicySpec{BuildPolicyRules: openshiftv1alpha1.BuildPolicyRules{
+						AllowedOutputRegistries: []string{"image-registry.openshift-image-registry.svc:5000"},
+						MaxTimeout:              &metav1.Duration{Duration: time.Hour},
+					}},
+				},
+				&openshiftv1alpha1.BuildPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "team"},
+					Spec: openshiftv1alpha1.BuildPolicySpec{
+						NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "true"}},
+						BuildPolicyRules: openshiftv1alpha1.BuildPolicyRules{
+							AllowedOutputRegistries: []string{"quay.io/team"},
+						},
+					},
+				},
+				&openshiftv1alpha1.BuildPolicy{
+					ObjectMeta: metav1.ObjectMeta{Name: "unselected"},
+					Spec: openshiftv1alpha1.BuildPolicySpec{BuildPolicyRules: openshiftv1alpha1.BuildPolicyRules{
+						RequiredLabels: []string{"owner"},
+					}},
+				},
+			).Build()
+			namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "builds"}}
+		})
+
+		It("returns the rules of the cluster BuildPolicy", func() {
+			rules, applied, err := buildpolicy.RulesFor(ctx, k8sClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal([]string{"cluster"}))
+			Expect(rules.AllowedOutputRegistries).To(Equal([]string{"image-registry.openshift-image-registry.svc:5000"}))
+			Expect(rules.RequiredLabels).To(BeEmpty())
+		})
+
+		It("overrides them with the BuildPolicies selecting the namespace", func() {
+			namespace.Labels = map[string]string{"team": "true"}
+			rules, applied, err := buildpolicy.RulesFor(ctx, k8sClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(applied).To(Equal([]string{"cluster", "team"}))
+			Expect(rules.AllowedOutputRegistries).To(Equal([]string{"quay.io/team"}))
+			Expect(rules.MaxTimeout.Duration).To(Equal(time.Hour))
+		})
+	})
+
+	Describe("Check", func() {
+		var subject buildpolicy.Subject
+
+		rules := func(rules openshiftv1alpha1.BuildPolicyRules) []string {
+			result := []string{}
+			for _, violation := range buildpolicy.Check(rules, subject) {
+				result = append(result, violation.Rule)
+			}
+			return result
+		}
+
+		BeforeEach(func() {
+			subject = buildpolicy.Subject{
+				Kind:     "Build",
+				Strategy: buildv1beta1.Strategy{Name: "buildah", Kind: ptr.To(buildv1beta1.ClusterBuildStrategyKind)},
+				Output:   "quay.io/team/app:latest",
+				Source: &buildv1beta1.Source{
+					Type: buildv1beta1.GitType,
+					Git:  &buildv1beta1.Git{URL: "https://github.com/team/app"},
+				},
+				Timeout: &metav1.Duration{Duration: 30 * time.Minute},
+				Labels:  map[string]string{"owner": "team"},
+			}
+		})
+
+		It("allows everything without rules", func() {
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{})).To(BeEmpty())
+		})
+
+		It("checks the strategy", func() {
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{
+				AllowedStrategies: []openshiftv1alpha1.BuildPolicyStrategy{{Name: "buildah"}},
+			})).To(BeEmpty())
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{
+				AllowedStrategies: []openshiftv1alpha1.BuildPolicyStrategy{{Kind: "BuildStrategy", Name: "*"}},
+			})).To(Equal([]string{buildpolicy.RuleStrategy}))
+
+			subject.Strategy.Kind = nil
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{
+				AllowedStrategies: []openshiftv1alpha1.BuildPolicyStrategy{{Kind: "BuildStrategy", Name: "*"}},
+			})).To(BeEmpty())
+		})
+
+		It("checks the output registry", func() {
+			for _, allowed := range []string{"quay.io", "quay.io/team", "*.io", "*"} {
+				Expect(rules(openshiftv1alpha1.BuildPolicyRules{AllowedOutputRegistries: []string{allowed}})).To(BeEmpty(), allowed)
+			}
+			for _, denied := range []string{"docker.io", "quay.io/other", "quay.io/tea", "*.quay.io"} {
+				Expect(rules(openshiftv1alpha1.BuildPolicyRules{AllowedOutputRegistries: []string{denied}})).
+					To(Equal([]string{buildpolicy.RuleOutputRegistry}), denied)
+			}
+		})
+
+		It("checks the Git host", func() {
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{AllowedGitHosts: []string{"github.com"}})).To(BeEmpty())
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{AllowedGitHosts: []string{"gitlab.com"}})).To(Equal([]string{buildpolicy.RuleGitHost}))
+
+			subject.Source = nil
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{AllowedGitHosts: []string{"gitlab.com"}})).To(BeEmpty())
+		})
+
+		It("checks the timeout", func() {
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{MaxTimeout: &metav1.Duration{Duration: time.Hour}})).To(BeEmpty())
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{MaxTimeout: &metav1.Duration{Duration: 10 * time.Minute}})).
+				To(Equal([]string{buildpolicy.RuleTimeout}))
+		})
+
+		It("checks the required labels", func() {
+			Expect(rules(openshiftv1alpha1.BuildPolicyRules{RequiredLabels: []string{"owner", "cost-center"}})).
+				To(Equal([]string{buildpolicy.RuleLabels}))
+		})
+	})
+
+	Describe("GitHost", func() {
+		It("parses URLs and scp-like locations", func() {
+			Expect(buildpolicy.GitHost("https://GitHub.com/team/app")).To(Equal("github.com"))
+			Expect(buildpolicy.GitHost("ssh://git@git.example.com:2222/team/app.git")).To(Equal("git.example.com"))
+			Expect(buildpolicy.GitHost("git@github.com:team/app.git")).To(Equal("github.com"))
+		})
+	})
+
+	Describe("Registry", func() {
+		It("parses the registry host and repository", func() {
+			host, repository := buildpolicy.Registry("image-registry.openshift-image-registry.svc:5000/team/app:v1")
+			Expect(host).To(Equal("image-registry.openshift-image-registry.svc:5000"))
+			Expect(repository).To(Equal("team/app"))
+			host, repository = buildpolicy.Registry("team/app@sha256:2222")
+			Expect(host).To(Equal("docker.io"))
+			Expect(repository).To(Equal("team/app"))
+		})
+	})
+})
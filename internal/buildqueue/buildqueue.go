@@ -0,0 +1,203 @@
+package buildqueue
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Label marks the BuildRuns admitted into the queue of a namespace with concurrency limits.
+// Shipwright propagates it to the TaskRun and pods of the BuildRun.
+const Label = "operator.openshift.io/build-queue"
+
+// SchedulingGate holds the pods of the queued BuildRuns until the queue starts them
+const SchedulingGate = "operator.openshift.io/build-queue"
+
+// PriorityLabel sets the priority of a BuildRun in the queue of its namespace. BuildRuns with a
+// higher priority start first, and BuildRuns without a valid priority have priority 0.
+const PriorityLabel = "operator.openshift.io/build-priority"
+
+// TimeoutAnnotation records the timeout of a queued BuildRun. Shipwright creates the TaskRun of
+// a BuildRun right away, and Tekton counts its timeout from then, so the queued BuildRuns run
+// without timeout and the queue enforces the recorded timeout from their start instead.
+const TimeoutAnnotation = "operator.openshift.io/build-timeout"
+
+// StartedAnnotation records when the queue started a queued BuildRun
+const StartedAnnotation = "operator.openshift.io/build-started"
+
+// ConditionQueued reports whether a BuildRun is held by the queue of its namespace
+const ConditionQueued buildv1beta1.Type = "Queued"
+
+// Reasons of the Queued condition
+const (
+	// ReasonConcurrencyLimit is the reason of the BuildRuns waiting for the concurrency limits.
+	ReasonConcurrencyLimit = "ConcurrencyLimit"
+
+	// ReasonStarted is the reason of the BuildRuns started by the queue.
+	ReasonStarted = "Started"
+
+	// ReasonTimeout is the reason of the BuildRuns canceled by the queue after their timeout.
+	ReasonTimeout = "Timeout"
+)
+
+// DefaultTimeout is the timeout of the queued BuildRuns without one, the default of Tekton
+const DefaultTimeout = time.Hour
+
+// Metrics of the BuildRuns by namespace and strategy
+var (
+	QueuedBuildRuns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_builds_queued_buildruns",
+		Help: "Number of BuildRuns queued by the concurrency limits of their namespace",
+	}, []string{"namespace", "strategy"})
+
+	RunningBuildRuns = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_builds_running_buildruns",
+		Help: "Number of BuildRuns running in the namespaces with concurrency limits",
+	}, []string{"namespace", "strategy"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(QueuedBuildRuns, RunningBuildRuns)
+}
+
+// Limited returns true when the concurrency limits the BuildRuns
+func Limited(concurrency *openshiftv1alpha1.BuildConcurrency) bool {
+	return concurrency != nil && (concurrency.MaxRunning != nil || len(concurrency.Strategies) > 0)
+}
+
+// Admitted returns true when the BuildRun was admitted into the queue of its namespace
+func Admitted(buildRun *buildv1beta1.BuildRun) bool {
+	_, ok := buildRun.Labels[Label]
+	return ok
+}
+
+// Started returns the time the queue started the BuildRun, or nil while it is queued
+func Started(buildRun *buildv1beta1.BuildRun) *time.Time {
+	started, err := time.Parse(time.RFC3339, buildRun.Annotations[StartedAnnotation])
+	if err != nil {
+		return nil
+	}
+	return &started
+}
+
+// Queued returns true when the BuildRun waits in the queue of its namespace
+func Queued(buildRun *buildv1beta1.BuildRun) bool {
+	return Admitted(buildRun) && Started(buildRun) == nil
+}
+
+// Timeout returns the timeout of a queued BuildRun, counted from its start by the queue. The
+// BuildRuns without a recorded timeout have the default timeout, and a zero timeout disables it.
+func Timeout(buildRun *buildv1beta1.BuildRun) time.Duration {
+	timeout, err := time.ParseDuration(buildRun.Annotations[TimeoutAnnotation])
+	if err != nil {
+		return DefaultTimeout
+	}
+	return timeout
+}
+
+// Gated returns true when the pod is held by the queue
+func Gated(pod *corev1.Pod) bool {
+	return slices.ContainsFunc(pod.Spec.SchedulingGates, isQueueGate)
+}
+
+// Ungate removes the queue scheduling gate from the pod
+func Ungate(pod *corev1.Pod) {
+	pod.Spec.SchedulingGates = slices.DeleteFunc(pod.Spec.SchedulingGates, isQueueGate)
+}
+
+// Strategy returns the name of the build strategy of a BuildRun, from the specification of its
+// Build recorded by Shipwright, or else its embedded specification
+func Strategy(buildRun *buildv1beta1.BuildRun) string {
+	spec := buildRun.Status.BuildSpec
+	if spec == nil {
+		spec = buildRun.Spec.Build.Spec
+	}
+	if spec == nil {
+		return ""
+	}
+	return spec.Strategy.Name
+}
+
+// Priority returns the priority of a BuildRun
+func Priority(buildRun *buildv1beta1.BuildRun) int {
+	priority, err := strconv.Atoi(buildRun.Labels[PriorityLabel])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// Queue is the state of the BuildRuns of a namespace
+type Queue struct {
+	// Running counts the running BuildRuns by strategy.
+	Running map[string]int
+
+	// Released are the queued BuildRuns to start.
+	Released []*buildv1beta1.BuildRun
+
+	// Queued are the BuildRuns remaining in the queue, in order.
+	Queued []*buildv1beta1.BuildRun
+}
+
+// Schedule returns the queued BuildRuns of a namespace to start within the concurrency limits,
+// in priority and FIFO order. A BuildRun held by the limit of its strategy does not hold the
+// BuildRuns of other strategies. All queued BuildRuns are released when the concurrency does not
+// limit the BuildRuns.
+func Schedule(concurrency *openshiftv1alpha1.BuildConcurrency, buildRuns []buildv1beta1.BuildRun) Queue {
+	queue := Queue{Running: map[string]int{}}
+	queued := []*buildv1beta1.BuildRun{}
+	running := 0
+	for i := range buildRuns {
+		buildRun := &buildRuns[i]
+		switch {
+		case !buildRun.DeletionTimestamp.IsZero() || buildRun.IsDone():
+		case Queued(buildRun):
+			queued = append(queued, buildRun)
+		default:
+			queue.Running[Strategy(buildRun)]++
+			running++
+		}
+	}
+	slices.SortFunc(queued, func(a, b *buildv1beta1.BuildRun) int {
+		if priority := Priority(b) - Priority(a); priority != 0 {
+			return priority
+		}
+		if created := a.CreationTimestamp.Compare(b.CreationTimestamp.Time); created != 0 {
+			return created
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	if !Limited(concurrency) {
+		queue.Released = queued
+		return queue
+	}
+
+	strategyLimits := map[string]int{}
+	for _, strategy := range concurrency.Strategies {
+		strategyLimits[strategy.Name] = int(strategy.MaxRunning)
+	}
+	for _, buildRun := range queued {
+		strategy := Strategy(buildRun)
+		limit, limited := strategyLimits[strategy]
+		if (concurrency.MaxRunning != nil && running >= int(*concurrency.MaxRunning)) || (limited && queue.Running[strategy] >= limit) {
+			queue.Queued = append(queue.Queued, buildRun)
+			continue
+		}
+		queue.Released = append(queue.Released, buildRun)
+		queue.Running[strategy]++
+		running++
+	}
+	return queue
+}
+
+// isQueueGate returns true for the queue scheduling gate
+func isQueueGate(gate corev1.PodSchedulingGate) bool {
+	return gate.Name == SchedulingGate
+}
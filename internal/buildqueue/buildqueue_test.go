@@ -0,0 +1,138 @@
+package buildqueue_test
+
+import (
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Build queue", func() {
+	now := time.Now()
+
+	newBuildRun := func(name, strategy string, age time.Duration, queued bool) buildv1beta1.BuildRun {
+		buildRun := buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels:            map[string]string{buildqueue.Label: ""},
+			},
+			Status: buildv1beta1.BuildRunStatus{BuildSpec: &buildv1beta1.BuildSpec{
+				Strategy: buildv1beta1.Strategy{Name: strategy},
+			}},
+		}
+		if !queued {
+			buildRun.Annotations = map[string]string{buildqueue.StartedAnnotation: now.Add(-age).UTC().Format(time.RFC3339)}
+		}
+		return buildRun
+	}
+	names := func(buildRuns []*buildv1beta1.BuildRun) []string {
+		result := []string{}
+		for _, buildRun := range buildRuns {
+			result = append(result, buildRun.Name)
+		}
+		return result
+	}
+
+	Describe("Schedule", func() {
+		It("releases all queued BuildRuns without limits", func() {
+			queue := buildqueue.Schedule(nil, []buildv1beta1.BuildRun{
+				newBuildRun("a", "buildah", time.Minute, true),
+				newBuildRun("b", "buildah", 2*time.Minute, true),
+			})
+			Expect(names(queue.Released)).To(Equal([]string{"b", "a"}))
+			Expect(queue.Queued).To(BeEmpty())
+		})
+
+		It("releases the oldest BuildRuns up to the namespace limit", func() {
+			queue := buildqueue.Schedule(&openshiftv1alpha1.BuildConcurrency{MaxRunning: ptr.To[int32](2)}, []buildv1beta1.BuildRun{
+				newBuildRun("running", "buildah", time.Hour, false),
+				newBuildRun("new", "buildah", time.Minute, true),
+				newBuildRun("old", "buildah", 10*time.Minute, true),
+			})
+			Expect(names(queue.Released)).To(Equal([]string{"old"}))
+			Expect(names(queue.Queued)).To(Equal([]string{"new"}))
+			Expect(queue.Running).To(HaveKeyWithValue("buildah", 2))
+		})
+
+		It("releases the BuildRuns with a higher priority first", func() {
+			urgent := newBuildRun("urgent", "buildah", time.Minute, true)
+			urgent.Labels[buildqueue.PriorityLabel] = strconv.Itoa(10)
+			queue := buildqueue.Schedule(&openshiftv1alpha1.BuildConcurrency{MaxRunning: ptr.To[int32](1)}, []buildv1beta1.BuildRun{
+				newBuildRun("old", "buildah", time.Hour, true),
+				urgent,
+			})
+			Expect(names(queue.Released)).To(Equal([]string{"urgent"}))
+			Expect(names(queue.Queued)).To(Equal([]string{"old"}))
+		})
+
+		It("does not count the completed BuildRuns", func() {
+			completed := newBuildRun("completed", "buildah", time.Hour, false)
+			completed.Status.Conditions = buildv1beta1.Conditions{{Type: buildv1beta1.Succeeded, Status: corev1.ConditionTrue}}
+			queue := buildqueue.Schedule(&openshiftv1alpha1.BuildConcurrency{MaxRunning: ptr.To[int32](1)}, []buildv1beta1.BuildRun{
+				completed,
+				newBuildRun("queued", "buildah", time.Minute, true),
+			})
+			Expect(names(queue.Released)).To(Equal([]string{"queued"}))
+		})
+
+		It("counts the BuildRuns created outside of the queue as running", func() {
+			unqueued := newBuildRun("unqueued", "buildah", time.Hour, false)
+			unqueued.Labels = nil
+			unqueued.Annotations = nil
+			queue := buildqueue.Schedule(&openshiftv1alpha1.BuildConcurrency{MaxRunning: ptr.To[int32](1)}, []buildv1beta1.BuildRun{
+				unqueued,
+				newBuildRun("queued", "buildah", time.Minute, true),
+			})
+			Expect(queue.Released).To(BeEmpty())
+			Expect(names(queue.Queued)).To(Equal([]string{"queued"}))
+		})
+
+		It("limits the strategies without holding the BuildRuns of other strategies", func() {
+			concurrency := &openshiftv1alpha1.BuildConcurrency{
+				MaxRunning: ptr.To[int32](3),
+				Strategies: []openshiftv1alpha1.StrategyConcurrency{{Name: "buildah", MaxRunning: 1}},
+			}
+			queue := buildqueue.Schedule(concurrency, []buildv1beta1.BuildRun{
+				newBuildRun("buildah-running", "buildah", time.Hour, false),
+				newBuildRun("buildah-queued", "buildah", 10*time.Minute, true),
+				newBuildRun("s2i-queued", "source-to-image", time.Minute, true),
+			})
+			Expect(names(queue.Released)).To(Equal([]string{"s2i-queued"}))
+			Expect(names(queue.Queued)).To(Equal([]string{"buildah-queued"}))
+		})
+	})
+
+	Describe("Timeout", func() {
+		It("returns the recorded timeout", func() {
+			buildRun := newBuildRun("a", "buildah", time.Minute, true)
+			buildRun.Annotations = map[string]string{buildqueue.TimeoutAnnotation: "10m0s"}
+			Expect(buildqueue.Timeout(&buildRun)).To(Equal(10 * time.Minute))
+		})
+
+		It("returns the default timeout when none is recorded", func() {
+			buildRun := newBuildRun("a", "buildah", time.Minute, true)
+			Expect(buildqueue.Timeout(&buildRun)).To(Equal(buildqueue.DefaultTimeout))
+		})
+	})
+
+	Describe("Ungate", func() {
+		It("removes only the queue scheduling gate", func() {
+			pod := corev1.Pod{Spec: corev1.PodSpec{SchedulingGates: []corev1.PodSchedulingGate{
+				{Name: buildqueue.SchedulingGate},
+				{Name: "example.com/other"},
+			}}}
+			buildqueue.Ungate(&pod)
+			Expect(buildqueue.Gated(&pod)).To(BeFalse())
+			Expect(pod.Spec.SchedulingGates).To(Equal([]corev1.PodSchedulingGate{{Name: "example.com/other"}}))
+		})
+	})
+})
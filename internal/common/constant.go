@@ -28,6 +28,14 @@ var (
 	}
 )
 
+// InternalRegistryHostname is the service address of the OpenShift internal image registry, used
+// to pull and push images of ImageStreams.
+const InternalRegistryHostname = "image-registry.openshift-image-registry.svc:5000"
+
+var (
+	ShipwrightTriggersManifestPath = filepath.Join("config", "shipwright", "triggers")
+)
+
 var (
 	SharedResourceManifestPath = filepath.Join("config", "sharedresource")
 )
@@ -36,6 +44,35 @@ var (
 	NetworkPolicyManifestPath = filepath.Join("config", "networkpolicies")
 )
 
+// OperandNamespaceManagedByLabel marks the operand namespaces created by the operator. The
+// namespaces without it are not modified by the operator.
+const (
+	OperandNamespaceManagedByLabel = "app.kubernetes.io/managed-by"
+	OperandNamespaceManagedBy      = "openshift-builds-operator"
+)
+
+// OperandNamespaceAnnotation records on the OpenShiftBuild the namespace its operands were last
+// deployed to, so that they are removed from it when the operand namespace changes.
+const OperandNamespaceAnnotation = "operator.openshift.io/operand-namespace"
+
+// The operator reads the name of its service account from the ServiceAccountNameEnv environment
+// variable, set by its deployment, or else uses the name of the service account it is deployed with.
+const (
+	ServiceAccountNameEnv      = "SERVICE_ACCOUNT_NAME"
+	OperatorServiceAccountName = "openshift-builds-operator"
+)
+
 var (
 	CurrentNamespaceName string
+
+	// OperandNamespaceName is the namespace where operands are deployed when it is not set on
+	// the OpenShiftBuild resource.
+	OperandNamespaceName = OpenShiftBuildNamespaceName
+
+	// OperandNamespaceLabels are the labels set by the operator on the operand namespace, when
+	// the operator creates it.
+	OperandNamespaceLabels = map[string]string{
+		OperandNamespaceManagedByLabel:    OperandNamespaceManagedBy,
+		"openshift.io/cluster-monitoring": "true",
+	}
 )
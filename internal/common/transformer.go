@@ -2,6 +2,7 @@ package common
 
 import (
 	"slices"
+	"strings"
 
 	"github.com/manifestival/manifestival"
 	appsv1 "k8s.io/api/apps/v1"
@@ -59,3 +60,36 @@ func InjectFinalizer(finalizer string) manifestival.Transformer {
 		return nil
 	}
 }
+
+// InjectServiceMonitorNamespace is a Manifestival transformer that rewrites the namespace of the
+// service DNS names used as TLS server names in ServiceMonitor endpoints.
+func InjectServiceMonitorNamespace(namespace string) manifestival.Transformer {
+	return func(object *unstructured.Unstructured) error {
+		if object.GetKind() != "ServiceMonitor" {
+			return nil
+		}
+		endpoints, found, err := unstructured.NestedSlice(object.Object, "spec", "endpoints")
+		if err != nil || !found {
+			return err
+		}
+		for i := range endpoints {
+			endpoint, ok := endpoints[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			serverName, found, err := unstructured.NestedString(endpoint, "tlsConfig", "serverName")
+			if err != nil {
+				return err
+			}
+			parts := strings.Split(serverName, ".")
+			if !found || len(parts) < 3 || parts[2] != "svc" {
+				continue
+			}
+			parts[1] = namespace
+			if err := unstructured.SetNestedField(endpoint, strings.Join(parts, "."), "tlsConfig", "serverName"); err != nil {
+				return err
+			}
+		}
+		return unstructured.SetNestedSlice(object.Object, endpoints, "spec", "endpoints")
+	}
+}
@@ -110,4 +110,34 @@ var _ = Describe("Transformer", Label("transformer"), func() {
 			})
 		})
 	})
+
+	Describe("Inject ServiceMonitor namespace", func() {
+		BeforeEach(func() {
+			object = &unstructured.Unstructured{}
+			object.SetAPIVersion("monitoring.coreos.com/v1")
+			object.SetKind("ServiceMonitor")
+			object.SetName("test")
+			Expect(unstructured.SetNestedSlice(object.Object, []interface{}{
+				map[string]interface{}{
+					"port": "metrics",
+					"tlsConfig": map[string]interface{}{
+						"serverName": "test-metrics.openshift-builds.svc",
+					},
+				},
+				map[string]interface{}{
+					"port": "other",
+				},
+			}, "spec", "endpoints")).To(Succeed())
+		})
+		When("the endpoint uses a service DNS name", func() {
+			It("should replace the namespace of the server name", func() {
+				Expect(common.InjectServiceMonitorNamespace("test-namespace")(object)).To(Succeed())
+				endpoints, _, err := unstructured.NestedSlice(object.Object, "spec", "endpoints")
+				Expect(err).ShouldNot(HaveOccurred())
+				serverName, _, _ := unstructured.NestedString(endpoints[0].(map[string]interface{}), "tlsConfig", "serverName")
+				Expect(serverName).To(Equal("test-metrics.test-namespace.svc"))
+				Expect(endpoints[1]).NotTo(HaveKey("tlsConfig"))
+			})
+		})
+	})
 })
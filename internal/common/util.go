@@ -1,6 +1,16 @@
 package common
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // FetchCurrentNamespaceName returns namespace name by using information stored as file
 // Returns default Openshift Builds namespace on error
@@ -14,3 +24,65 @@ func FetchCurrentNamespaceName() string {
 	}
 	return CurrentNamespaceName
 }
+
+// FetchCurrentServiceAccountUsername returns the username the operator authenticates as, the
+// username of its service account
+func FetchCurrentServiceAccountUsername() string {
+	name := os.Getenv(ServiceAccountNameEnv)
+	if name == "" {
+		name = OperatorServiceAccountName
+	}
+	return fmt.Sprintf("system:serviceaccount:%s:%s", FetchCurrentNamespaceName(), name)
+}
+
+// OperandNamespace returns the namespace where the operands of the given OpenShiftBuild are
+// deployed, falling back to OperandNamespaceName when it is not set on the resource.
+func OperandNamespace(owner *openshiftv1alpha1.OpenShiftBuild) string {
+	if owner != nil && owner.Spec.Namespace != "" {
+		return owner.Spec.Namespace
+	}
+	return OperandNamespaceName
+}
+
+// IsSystemNamespace returns true for the namespaces of Kubernetes and OpenShift, which must not
+// hold the operands, apart from the default operand namespace
+func IsSystemNamespace(name string) bool {
+	if name == OpenShiftBuildNamespaceName {
+		return false
+	}
+	return name == "default" || name == "openshift" ||
+		strings.HasPrefix(name, "kube-") || strings.HasPrefix(name, "openshift-")
+}
+
+// DeleteOperands deletes the resources of the manifest deployed in the given namespace and
+// controlled by the owner, after removing their finalizers. Cluster scoped resources and kinds
+// which are not served are skipped.
+func DeleteOperands(manifest manifestival.Manifest, namespace string, owner metav1.Object) error {
+	mfc := manifest.Client
+	for _, res := range manifest.Resources() {
+		if res.GetNamespace() != namespace {
+			continue
+		}
+		obj, err := mfc.Get(&res)
+		if err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		if !metav1.IsControlledBy(obj, owner) {
+			continue
+		}
+
+		if len(obj.GetFinalizers()) > 0 {
+			obj.SetFinalizers([]string{})
+			if err := mfc.Update(obj); err != nil {
+				return err
+			}
+		}
+		if err := mfc.Delete(&res); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package component
+
+import (
+	"context"
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Component is a subsystem deployed and managed by the OpenShiftBuild reconciler.
+type Component interface {
+	// Name returns the unique name of the component, used in logs, conditions and errors.
+	Name() string
+
+	// Setup initializes the component with the manager, e.g. by loading its manifests.
+	Setup(mgr ctrl.Manager) error
+
+	// Reconcile moves the component towards the state desired by the OpenShiftBuild owner.
+	// The owner is defaulted, and must not be updated by the component.
+	Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error
+
+	// Delete cleans up the component when the OpenShiftBuild owner is being deleted.
+	Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error
+
+	// Status returns the condition describing the observed state of the component.
+	Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition
+
+	// WatchedTypes returns the types created and owned by the component which should trigger
+	// a reconciliation of the owner when changed.
+	WatchedTypes() []client.Object
+}
+
+// Watcher is implemented by the components which depend on objects they do not own, such as
+// the namespaces selected by a label selector. Changes to the watched objects trigger a
+// reconciliation of the OpenShiftBuild instances.
+type Watcher interface {
+	// Watches returns the objects watched by the component.
+	Watches() []Watch
+}
+
+// Degradable is implemented by the components which may keep serving in a degraded state, e.g.
+// rolled back to a previous release. The degraded components are reported by the Degraded
+// condition of the OpenShiftBuild.
+type Degradable interface {
+	// Degraded returns why the component is degraded, or an empty string if it is not.
+	Degraded(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) string
+}
+
+// Relocatable is implemented by the components deploying operands in the operand namespace. When
+// the operand namespace changes, the operands are deleted from the previous namespace once the
+// components are reconciled in the new one.
+type Relocatable interface {
+	// Relocate deletes the operands of the component deployed in the previous namespace.
+	Relocate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, previous string) error
+}
+
+// Watch is a type of objects watched by a Watcher component
+type Watch struct {
+	// Object is the type of the watched objects.
+	Object client.Object
+
+	// Predicates filter the events of the watched objects.
+	Predicates []predicate.Predicate
+}
+
+// ConditionType returns the status condition type reported for the given component.
+func ConditionType(c Component) string {
+	return c.Name() + openshiftv1alpha1.ConditionReady
+}
+
+// Registry holds the ordered list of components reconciled by the OpenShiftBuild reconciler.
+type Registry struct {
+	components []Component
+}
+
+// NewRegistry creates new instance of Registry with the given components
+func NewRegistry(components ...Component) (*Registry, error) {
+	registry := &Registry{}
+	if err := registry.Register(components...); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Register appends the components to the registry. Components are reconciled in the order
+// they are registered, and their names must be unique.
+func (r *Registry) Register(components ...Component) error {
+	for _, c := range components {
+		if r.Get(c.Name()) != nil {
+			return fmt.Errorf("component %q is already registered", c.Name())
+		}
+		r.components = append(r.components, c)
+	}
+	return nil
+}
+
+// Get returns the registered component with the given name, or nil if there is none.
+func (r *Registry) Get(name string) Component {
+	for _, c := range r.components {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Components returns the registered components in registration order.
+func (r *Registry) Components() []Component {
+	if r == nil {
+		return nil
+	}
+	return r.components
+}
@@ -0,0 +1,13 @@
+package component_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestComponent(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Component Suite")
+}
@@ -0,0 +1,72 @@
+package component_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type fakeComponent struct {
+	name string
+}
+
+func (f *fakeComponent) Name() string                 { return f.name }
+func (f *fakeComponent) Setup(mgr ctrl.Manager) error { return nil }
+func (f *fakeComponent) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	return nil
+}
+func (f *fakeComponent) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	return nil
+}
+func (f *fakeComponent) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	return metav1.Condition{Type: component.ConditionType(f), Status: metav1.ConditionTrue}
+}
+func (f *fakeComponent) WatchedTypes() []client.Object { return nil }
+
+var _ = Describe("Registry", Label("component"), func() {
+	When("components have unique names", func() {
+		It("should keep the registration order", func() {
+			registry, err := component.NewRegistry(&fakeComponent{name: "b"}, &fakeComponent{name: "a"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registry.Register(&fakeComponent{name: "c"})).To(Succeed())
+
+			names := []string{}
+			for _, c := range registry.Components() {
+				names = append(names, c.Name())
+			}
+			Expect(names).To(Equal([]string{"b", "a", "c"}))
+		})
+
+		It("should find components by name", func() {
+			registry, err := component.NewRegistry(&fakeComponent{name: "a"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(registry.Get("a")).NotTo(BeNil())
+			Expect(registry.Get("b")).To(BeNil())
+		})
+	})
+
+	When("a component name is registered twice", func() {
+		It("should return an error", func() {
+			_, err := component.NewRegistry(&fakeComponent{name: "a"}, &fakeComponent{name: "a"})
+			Expect(err).To(MatchError(ContainSubstring(`component "a" is already registered`)))
+		})
+	})
+
+	When("the registry is nil", func() {
+		It("should not return any components", func() {
+			var registry *component.Registry
+			Expect(registry.Components()).To(BeEmpty())
+		})
+	})
+
+	It("should derive the condition type from the component name", func() {
+		Expect(component.ConditionType(&fakeComponent{name: "Test"})).To(Equal("TestReady"))
+	})
+})
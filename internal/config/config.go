@@ -0,0 +1,437 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigMapKey is the key holding the operator configuration in a ConfigMap
+const ConfigMapKey = "config.yaml"
+
+// Environment variables overriding the operator configuration
+const (
+	OperandNamespaceEnv                    = "OPERAND_NAMESPACE"
+	ShipwrightBuildManifestPathEnv         = common.ShipwrightBuildManifestPathEnv
+	ShipwrightBuildStrategyManifestPathEnv = common.ShipwrightBuildStrategyManifestPathEnv
+	ShipwrightTriggersManifestPathEnv      = "SHIPWRIGHT_TRIGGERS_MANIFEST_PATH"
+	SharedResourceManifestPathEnv          = "SHARED_RESOURCE_MANIFEST_PATH"
+	NetworkPolicyManifestPathEnv           = "NETWORKPOLICY_MANIFEST_PATH"
+	BootstrapOpenShiftBuildEnv             = "BOOTSTRAP_OPENSHIFT_BUILD"
+	CleanupRoleBindingsEnv                 = "CLEANUP_ROLE_BINDINGS"
+	NetworkPolicyEnabledEnv                = "NETWORKPOLICY_ENABLED"
+	WebhooksEnabledEnv                     = "ENABLE_WEBHOOKS"
+	BuildDefaultsEnabledEnv                = "ENABLE_BUILD_DEFAULTS"
+	BuildConfigMigrationEnabledEnv         = "ENABLE_BUILDCONFIG_MIGRATION"
+	ImageStreamOutputsEnabledEnv           = "ENABLE_IMAGESTREAM_OUTPUTS"
+	ImageStreamTriggersEnabledEnv          = "ENABLE_IMAGESTREAM_TRIGGERS"
+	BuilderImageStreamsEnabledEnv          = "ENABLE_BUILDER_IMAGESTREAMS"
+	BuildNamespacesEnabledEnv              = "ENABLE_BUILD_NAMESPACES"
+	BuildPoliciesEnabledEnv                = "ENABLE_BUILD_POLICIES"
+	BuildQueueEnabledEnv                   = "ENABLE_BUILD_QUEUE"
+	StagedUpgradesEnabledEnv               = "ENABLE_STAGED_UPGRADES"
+	RequireImageDigestsEnv                 = "REQUIRE_IMAGE_DIGESTS"
+
+	// RelatedImageEnvPrefix is the prefix of the environment variables overriding operand images,
+	// e.g. RELATED_IMAGE_GIT_CONTAINER_IMAGE.
+	RelatedImageEnvPrefix = images.RelatedImagePrefix
+)
+
+// Config is the configuration of the operator
+type Config struct {
+	// OperandNamespace is the namespace where operands are deployed, unless set on the
+	// OpenShiftBuild resource. Defaults to openshift-builds.
+	OperandNamespace string `json:"operandNamespace,omitempty"`
+
+	// Manifests holds the locations of the manifests applied by the operator.
+	Manifests Manifests `json:"manifests,omitempty"`
+
+	// Bootstrap configures the tasks run when the operator starts.
+	Bootstrap Bootstrap `json:"bootstrap,omitempty"`
+
+	// Features enables or disables optional operator features.
+	Features Features `json:"features,omitempty"`
+
+	// Images configures the images of the operands.
+	Images Images `json:"images,omitempty"`
+}
+
+// Manifests holds the file or directory paths of the manifests applied by the operator
+type Manifests struct {
+	ShipwrightBuild         string `json:"shipwrightBuild,omitempty"`
+	ShipwrightBuildStrategy string `json:"shipwrightBuildStrategy,omitempty"`
+	ShipwrightTriggers      string `json:"shipwrightTriggers,omitempty"`
+	SharedResource          string `json:"sharedResource,omitempty"`
+	NetworkPolicy           string `json:"networkPolicy,omitempty"`
+}
+
+// Bootstrap configures the tasks run when the operator starts
+type Bootstrap struct {
+	// OpenShiftBuild creates the default OpenShiftBuild instance if it does not exist.
+	OpenShiftBuild *bool `json:"openShiftBuild,omitempty"`
+
+	// CleanupRoleBindings removes redundant role bindings created by earlier releases.
+	CleanupRoleBindings *bool `json:"cleanupRoleBindings,omitempty"`
+}
+
+// Features enables or disables optional operator features
+type Features struct {
+	// NetworkPolicy deploys the NetworkPolicies protecting the operands.
+	NetworkPolicy *bool `json:"networkPolicy,omitempty"`
+
+	// Webhooks serves the admission webhooks of the operator APIs. It is enabled by the
+	// deployment manifests installing the webhook configurations.
+	Webhooks *bool `json:"webhooks,omitempty"`
+
+	// BuildDefaults applies the cluster build defaults and overrides of build.config.openshift.io
+	// to the Shipwright BuildRuns. It requires the webhooks.
+	BuildDefaults *bool `json:"buildDefaults,omitempty"`
+
+	// BuildConfigMigration converts the BuildConfigs annotated for conversion to Shipwright
+	// Builds.
+	BuildConfigMigration *bool `json:"buildConfigMigration,omitempty"`
+
+	// ImageStreamOutputs pushes the images of the Builds annotated with an ImageStreamTag output
+	// to the internal registry, and tags them into the ImageStream. It requires the webhooks.
+	ImageStreamOutputs *bool `json:"imageStreamOutputs,omitempty"`
+
+	// ImageStreamTriggers creates a BuildRun of the Builds annotated with ImageStream triggers
+	// when the image of a triggering ImageStreamTag changes.
+	ImageStreamTriggers *bool `json:"imageStreamTriggers,omitempty"`
+
+	// BuilderImageStreams resolves the builder images of the BuildRuns referencing an
+	// ImageStreamTag, such as "nodejs:20-ubi9" of the openshift namespace. It requires the
+	// webhooks.
+	BuilderImageStreams *bool `json:"builderImageStreams,omitempty"`
+
+	// BuildNamespaces onboards the namespaces holding a BuildNamespaceConfig to builds.
+	BuildNamespaces *bool `json:"buildNamespaces,omitempty"`
+
+	// BuildPolicies rejects the Builds and BuildRuns violating the BuildPolicies of their
+	// namespace. It requires the webhooks.
+	BuildPolicies *bool `json:"buildPolicies,omitempty"`
+
+	// BuildQueue queues the BuildRuns exceeding the concurrency limits of their namespace. It
+	// requires the webhooks.
+	BuildQueue *bool `json:"buildQueue,omitempty"`
+
+	// StagedUpgrades verifies the new Shipwright Build releases, and rolls back to the last
+	// verified release when the verification fails.
+	StagedUpgrades *bool `json:"stagedUpgrades,omitempty"`
+}
+
+// Images configures the images of the operands
+type Images struct {
+	// Overrides maps the names of containers, init containers, strategy steps, strategy parameters
+	// and image-valued environment variables to the image replacing their value. Names are
+	// lower case with dashes replaced by underscores, and may be qualified with the name of the
+	// object holding them, e.g. "buildah_build_and_push".
+	Overrides map[string]string `json:"overrides,omitempty"`
+
+	// RequireDigests refuses to deploy operand images which are not pinned by digest.
+	RequireDigests *bool `json:"requireDigests,omitempty"`
+}
+
+// Default returns the default operator configuration
+func Default() *Config {
+	return &Config{
+		Manifests: Manifests{
+			ShipwrightBuild:         common.ShipwrightBuildManifestPath,
+			ShipwrightBuildStrategy: common.ShipwrightBuildStrategyManifestPath,
+			ShipwrightTriggers:      common.ShipwrightTriggersManifestPath,
+			SharedResource:          common.SharedResourceManifestPath,
+			NetworkPolicy:           common.NetworkPolicyManifestPath,
+		},
+		Bootstrap: Bootstrap{
+			OpenShiftBuild:      ptr.To(true),
+			CleanupRoleBindings: ptr.To(true),
+		},
+		Features: Features{
+			NetworkPolicy:        ptr.To(true),
+			Webhooks:             ptr.To(false),
+			BuildDefaults:        ptr.To(false),
+			BuildConfigMigration: ptr.To(false),
+			ImageStreamOutputs:   ptr.To(false),
+			ImageStreamTriggers:  ptr.To(false),
+			BuilderImageStreams:  ptr.To(false),
+			BuildNamespaces:      ptr.To(false),
+			BuildPolicies:        ptr.To(false),
+			BuildQueue:           ptr.To(false),
+			StagedUpgrades:       ptr.To(false),
+		},
+		Images: Images{
+			RequireDigests: ptr.To(false),
+		},
+	}
+}
+
+// Enabled returns the value of an optional switch, treating unset as disabled
+func Enabled(value *bool) bool {
+	return value != nil && *value
+}
+
+// Validate checks the configuration, returning all the problems found
+func (c *Config) Validate() error {
+	errs := []error{}
+	if c.OperandNamespace != "" {
+		if msgs := validation.IsDNS1123Label(c.OperandNamespace); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("operandNamespace: invalid namespace %q: %s", c.OperandNamespace, strings.Join(msgs, ", ")))
+		}
+	}
+	paths := []struct {
+		field string
+		path  string
+	}{
+		{"manifests.shipwrightBuild", c.Manifests.ShipwrightBuild},
+		{"manifests.shipwrightBuildStrategy", c.Manifests.ShipwrightBuildStrategy},
+		{"manifests.shipwrightTriggers", c.Manifests.ShipwrightTriggers},
+		{"manifests.sharedResource", c.Manifests.SharedResource},
+		{"manifests.networkPolicy", c.Manifests.NetworkPolicy},
+	}
+	for _, p := range paths {
+		if p.path == "" {
+			errs = append(errs, fmt.Errorf("%s: path must not be empty", p.field))
+			continue
+		}
+		if _, err := os.Stat(p.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", p.field, err))
+		}
+	}
+	if Enabled(c.Images.RequireDigests) {
+		for name, image := range c.Images.Overrides {
+			if !images.IsPinned(image) {
+				errs = append(errs, fmt.Errorf("images.overrides.%s: image %q is not pinned by digest", name, image))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Options defines where the operator configuration is loaded from, and the command line
+// flags overriding it.
+type Options struct {
+	// File is the path of a YAML file holding the operator configuration.
+	File string
+
+	// ConfigMap is the "namespace/name" of a ConfigMap holding the operator configuration
+	// under the ConfigMapKey key.
+	ConfigMap string
+
+	overrides map[string]func(*Config, string) error
+	flags     *flag.FlagSet
+}
+
+// BindFlags registers the configuration flags on the given flag set. Flags take precedence
+// over environment variables, which take precedence over the configuration file or ConfigMap.
+func (o *Options) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.File, "config", "",
+		"Path of a YAML file holding the operator configuration.")
+	fs.StringVar(&o.ConfigMap, "config-configmap", "",
+		"Namespace and name of a ConfigMap holding the operator configuration under the "+ConfigMapKey+" key, "+
+			"in the form <namespace>/<name>.")
+
+	o.flags = fs
+	o.overrides = map[string]func(*Config, string) error{}
+	o.stringFlag("operand-namespace", "The namespace where operands are deployed, unless set on the OpenShiftBuild resource. "+
+		"Defaults to openshift-builds.",
+		func(c *Config) *string { return &c.OperandNamespace })
+	o.stringFlag("shipwright-build-manifest-path", "Path of the Shipwright Build release manifests.",
+		func(c *Config) *string { return &c.Manifests.ShipwrightBuild })
+	o.stringFlag("shipwright-build-strategy-manifest-path", "Path of the Shipwright Build strategy manifests.",
+		func(c *Config) *string { return &c.Manifests.ShipwrightBuildStrategy })
+	o.stringFlag("shipwright-triggers-manifest-path", "Path of the Shipwright Triggers manifests.",
+		func(c *Config) *string { return &c.Manifests.ShipwrightTriggers })
+	o.stringFlag("shared-resource-manifest-path", "Path of the Shared Resource CSI Driver manifests.",
+		func(c *Config) *string { return &c.Manifests.SharedResource })
+	o.stringFlag("networkpolicy-manifest-path", "Path of the NetworkPolicy manifests.",
+		func(c *Config) *string { return &c.Manifests.NetworkPolicy })
+	o.boolFlag("bootstrap-openshift-build", true, "Create the default OpenShiftBuild instance on startup.",
+		func(c *Config) **bool { return &c.Bootstrap.OpenShiftBuild })
+	o.boolFlag("cleanup-role-bindings", true, "Remove redundant role bindings created by earlier releases on startup.",
+		func(c *Config) **bool { return &c.Bootstrap.CleanupRoleBindings })
+	o.boolFlag("enable-networkpolicy", true, "Deploy the NetworkPolicies protecting the operands.",
+		func(c *Config) **bool { return &c.Features.NetworkPolicy })
+	o.boolFlag("enable-webhooks", false, "Serve the admission webhooks of the operator APIs.",
+		func(c *Config) **bool { return &c.Features.Webhooks })
+	o.boolFlag("enable-build-defaults", false, "Apply the cluster build defaults and overrides to the Shipwright BuildRuns.",
+		func(c *Config) **bool { return &c.Features.BuildDefaults })
+	o.boolFlag("enable-buildconfig-migration", false, "Convert the BuildConfigs annotated for conversion to Shipwright Builds.",
+		func(c *Config) **bool { return &c.Features.BuildConfigMigration })
+	o.boolFlag("enable-imagestream-outputs", false, "Push the images of the Builds with an ImageStreamTag output to the internal registry.",
+		func(c *Config) **bool { return &c.Features.ImageStreamOutputs })
+	o.boolFlag("enable-imagestream-triggers", false, "Rebuild the Builds with ImageStream triggers when the triggering images change.",
+		func(c *Config) **bool { return &c.Features.ImageStreamTriggers })
+	o.boolFlag("enable-builder-imagestreams", false, "Resolve the builder images of the BuildRuns referencing an ImageStreamTag.",
+		func(c *Config) **bool { return &c.Features.BuilderImageStreams })
+	o.boolFlag("enable-build-namespaces", false, "Onboard the namespaces holding a BuildNamespaceConfig to builds.",
+		func(c *Config) **bool { return &c.Features.BuildNamespaces })
+	o.boolFlag("enable-build-policies", false, "Reject the Builds and BuildRuns violating the BuildPolicies of their namespace.",
+		func(c *Config) **bool { return &c.Features.BuildPolicies })
+	o.boolFlag("enable-build-queue", false, "Queue the BuildRuns exceeding the concurrency limits of their namespace.",
+		func(c *Config) **bool { return &c.Features.BuildQueue })
+	o.boolFlag("enable-staged-upgrades", false, "Verify the new Shipwright Build releases and roll back the releases failing verification.",
+		func(c *Config) **bool { return &c.Features.StagedUpgrades })
+	o.boolFlag("require-image-digests", false, "Refuse to deploy operand images which are not pinned by digest.",
+		func(c *Config) **bool { return &c.Images.RequireDigests })
+}
+
+// Load builds the operator configuration from the defaults, the configuration file or ConfigMap,
+// the environment and the command line flags, and validates the result.
+func (o *Options) Load(ctx context.Context, reader client.Reader) (*Config, error) {
+	config := Default()
+
+	if o.File != "" {
+		data, err := os.ReadFile(o.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read operator configuration: %v", err)
+		}
+		if err := yaml.UnmarshalStrict(data, config); err != nil {
+			return nil, fmt.Errorf("failed to parse operator configuration %s: %v", o.File, err)
+		}
+		normalizeImageOverrides(config)
+	}
+
+	if o.ConfigMap != "" {
+		namespace, name, ok := strings.Cut(o.ConfigMap, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("invalid operator configuration ConfigMap %q, expected <namespace>/<name>", o.ConfigMap)
+		}
+		configMap := &corev1.ConfigMap{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap); err != nil {
+			return nil, fmt.Errorf("failed to get operator configuration ConfigMap %s: %v", o.ConfigMap, err)
+		}
+		data, ok := configMap.Data[ConfigMapKey]
+		if !ok {
+			return nil, fmt.Errorf("operator configuration ConfigMap %s has no %s key", o.ConfigMap, ConfigMapKey)
+		}
+		if err := yaml.UnmarshalStrict([]byte(data), config); err != nil {
+			return nil, fmt.Errorf("failed to parse operator configuration ConfigMap %s: %v", o.ConfigMap, err)
+		}
+		normalizeImageOverrides(config)
+	}
+
+	if err := applyEnv(config); err != nil {
+		return nil, err
+	}
+
+	if o.flags != nil {
+		var errs []error
+		o.flags.Visit(func(f *flag.Flag) {
+			if override, ok := o.overrides[f.Name]; ok {
+				if err := override(config, f.Value.String()); err != nil {
+					errs = append(errs, fmt.Errorf("--%s: %v", f.Name, err))
+				}
+			}
+		})
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid operator configuration: %w", err)
+	}
+	return config, nil
+}
+
+// applyEnv overrides the configuration with the values of the environment variables
+func applyEnv(config *Config) error {
+	values := map[string]*string{
+		OperandNamespaceEnv:                    &config.OperandNamespace,
+		ShipwrightBuildManifestPathEnv:         &config.Manifests.ShipwrightBuild,
+		ShipwrightBuildStrategyManifestPathEnv: &config.Manifests.ShipwrightBuildStrategy,
+		ShipwrightTriggersManifestPathEnv:      &config.Manifests.ShipwrightTriggers,
+		SharedResourceManifestPathEnv:          &config.Manifests.SharedResource,
+		NetworkPolicyManifestPathEnv:           &config.Manifests.NetworkPolicy,
+	}
+	for env, field := range values {
+		if value, ok := os.LookupEnv(env); ok {
+			*field = value
+		}
+	}
+
+	bools := map[string]**bool{
+		BootstrapOpenShiftBuildEnv:     &config.Bootstrap.OpenShiftBuild,
+		CleanupRoleBindingsEnv:         &config.Bootstrap.CleanupRoleBindings,
+		NetworkPolicyEnabledEnv:        &config.Features.NetworkPolicy,
+		WebhooksEnabledEnv:             &config.Features.Webhooks,
+		BuildDefaultsEnabledEnv:        &config.Features.BuildDefaults,
+		BuildConfigMigrationEnabledEnv: &config.Features.BuildConfigMigration,
+		ImageStreamOutputsEnabledEnv:   &config.Features.ImageStreamOutputs,
+		ImageStreamTriggersEnabledEnv:  &config.Features.ImageStreamTriggers,
+		BuilderImageStreamsEnabledEnv:  &config.Features.BuilderImageStreams,
+		BuildNamespacesEnabledEnv:      &config.Features.BuildNamespaces,
+		BuildPoliciesEnabledEnv:        &config.Features.BuildPolicies,
+		BuildQueueEnabledEnv:           &config.Features.BuildQueue,
+		StagedUpgradesEnabledEnv:       &config.Features.StagedUpgrades,
+		RequireImageDigestsEnv:         &config.Images.RequireDigests,
+	}
+	for env, field := range bools {
+		if value, ok := os.LookupEnv(env); ok {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid value %q for environment variable %s: %v", value, env, err)
+			}
+			*field = &parsed
+		}
+	}
+
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if key, ok := strings.CutPrefix(name, RelatedImageEnvPrefix); ok && key != "" {
+			if config.Images.Overrides == nil {
+				config.Images.Overrides = map[string]string{}
+			}
+			config.Images.Overrides[images.Key(key)] = value
+		}
+	}
+	return nil
+}
+
+// normalizeImageOverrides normalizes the names of the image overrides, which may be written
+// with dashes or in upper case in the configuration
+func normalizeImageOverrides(config *Config) {
+	if config.Images.Overrides == nil {
+		return
+	}
+	overrides := map[string]string{}
+	for name, image := range config.Images.Overrides {
+		overrides[images.Key(name)] = image
+	}
+	config.Images.Overrides = overrides
+}
+
+// stringFlag registers a flag overriding a string field of the configuration
+func (o *Options) stringFlag(name, usage string, field func(*Config) *string) {
+	o.flags.String(name, "", usage)
+	o.overrides[name] = func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// boolFlag registers a flag overriding an optional switch of the configuration
+func (o *Options) boolFlag(name string, value bool, usage string, field func(*Config) **bool) {
+	o.flags.Bool(name, value, usage)
+	o.overrides[name] = func(c *Config, value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = &parsed
+		return nil
+	}
+}
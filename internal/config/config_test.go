@@ -0,0 +1,187 @@
+package config_test
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Config", Label("config"), func() {
+	var (
+		ctx       context.Context
+		options   *config.Options
+		flags     *flag.FlagSet
+		manifests string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		manifests = GinkgoT().TempDir()
+		for _, dir := range []string{"release", "strategy", "triggers", "sharedresource", "networkpolicies"} {
+			Expect(os.Mkdir(filepath.Join(manifests, dir), 0o755)).To(Succeed())
+		}
+		GinkgoT().Setenv(config.ShipwrightBuildManifestPathEnv, filepath.Join(manifests, "release"))
+		GinkgoT().Setenv(config.ShipwrightBuildStrategyManifestPathEnv, filepath.Join(manifests, "strategy"))
+		GinkgoT().Setenv(config.ShipwrightTriggersManifestPathEnv, filepath.Join(manifests, "triggers"))
+		GinkgoT().Setenv(config.SharedResourceManifestPathEnv, filepath.Join(manifests, "sharedresource"))
+		GinkgoT().Setenv(config.NetworkPolicyManifestPathEnv, filepath.Join(manifests, "networkpolicies"))
+
+		options = &config.Options{}
+		flags = flag.NewFlagSet("test", flag.ContinueOnError)
+		options.BindFlags(flags)
+	})
+
+	writeConfig := func(content string) string {
+		path := filepath.Join(manifests, "config.yaml")
+		Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+		return path
+	}
+
+	When("no configuration is provided", func() {
+		It("should use the defaults and the environment", func() {
+			Expect(flags.Parse(nil)).To(Succeed())
+			cfg, err := options.Load(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.OperandNamespace).To(BeEmpty())
+			Expect(cfg.Manifests.ShipwrightTriggers).To(Equal(filepath.Join(manifests, "triggers")))
+			Expect(cfg.Manifests.SharedResource).To(Equal(filepath.Join(manifests, "sharedresource")))
+			Expect(config.Enabled(cfg.Bootstrap.OpenShiftBuild)).To(BeTrue())
+			Expect(config.Enabled(cfg.Bootstrap.CleanupRoleBindings)).To(BeTrue())
+			Expect(config.Enabled(cfg.Features.NetworkPolicy)).To(BeTrue())
+			Expect(config.Enabled(cfg.Features.Webhooks)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.BuildDefaults)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.BuildConfigMigration)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.ImageStreamOutputs)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.ImageStreamTriggers)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.BuilderImageStreams)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.BuildNamespaces)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.BuildPolicies)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.BuildQueue)).To(BeFalse())
+			Expect(config.Enabled(cfg.Features.StagedUpgrades)).To(BeFalse())
+		})
+	})
+
+	When("a configuration file is provided", func() {
+		It("should load the configuration file", func() {
+			Expect(flags.Parse([]string{"--config", writeConfig(`
+operandNamespace: test-namespace
+bootstrap:
+  openShiftBuild: false
+features:
+  networkPolicy: false
+`)})).To(Succeed())
+			cfg, err := options.Load(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.OperandNamespace).To(Equal("test-namespace"))
+			Expect(config.Enabled(cfg.Bootstrap.OpenShiftBuild)).To(BeFalse())
+			Expect(config.Enabled(cfg.Bootstrap.CleanupRoleBindings)).To(BeTrue())
+			Expect(config.Enabled(cfg.Features.NetworkPolicy)).To(BeFalse())
+		})
+
+		It("should reject unknown fields", func() {
+			Expect(flags.Parse([]string{"--config", writeConfig("unknown: true\n")})).To(Succeed())
+			_, err := options.Load(ctx, nil)
+			Expect(err).To(MatchError(ContainSubstring("unknown")))
+		})
+
+		It("should let environment variables and flags override the file", func() {
+			GinkgoT().Setenv(config.OperandNamespaceEnv, "env-namespace")
+			GinkgoT().Setenv(config.NetworkPolicyEnabledEnv, "false")
+			Expect(flags.Parse([]string{
+				"--config", writeConfig("operandNamespace: file-namespace\n"),
+				"--enable-networkpolicy=true",
+			})).To(Succeed())
+			cfg, err := options.Load(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.OperandNamespace).To(Equal("env-namespace"))
+			Expect(config.Enabled(cfg.Features.NetworkPolicy)).To(BeTrue())
+
+			Expect(flags.Set("operand-namespace", "flag-namespace")).To(Succeed())
+			cfg, err = options.Load(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.OperandNamespace).To(Equal("flag-namespace"))
+		})
+	})
+
+	When("a configuration ConfigMap is provided", func() {
+		It("should load the configuration from the ConfigMap", func() {
+			reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "operator-config"},
+				Data:       map[string]string{config.ConfigMapKey: "operandNamespace: configmap-namespace\n"},
+			}).Build()
+			Expect(flags.Parse([]string{"--config-configmap", "test/operator-config"})).To(Succeed())
+			cfg, err := options.Load(ctx, reader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.OperandNamespace).To(Equal("configmap-namespace"))
+		})
+
+		It("should reject a malformed reference", func() {
+			Expect(flags.Parse([]string{"--config-configmap", "operator-config"})).To(Succeed())
+			_, err := options.Load(ctx, nil)
+			Expect(err).To(MatchError(ContainSubstring("expected <namespace>/<name>")))
+		})
+	})
+
+	When("image overrides are provided", func() {
+		It("should load RELATED_IMAGE environment variables over the file", func() {
+			GinkgoT().Setenv(config.RelatedImageEnvPrefix+"SHIPWRIGHT_BUILD", "registry.example.com/build:env")
+			Expect(flags.Parse([]string{"--config", writeConfig(`
+images:
+  overrides:
+    shipwright-build: registry.example.com/build:file
+    hostpath: registry.example.com/hostpath:file
+`)})).To(Succeed())
+			cfg, err := options.Load(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cfg.Images.Overrides).To(Equal(map[string]string{
+				"shipwright_build": "registry.example.com/build:env",
+				"hostpath":         "registry.example.com/hostpath:file",
+			}))
+			Expect(config.Enabled(cfg.Images.RequireDigests)).To(BeFalse())
+		})
+
+		It("should reject overrides not pinned by digest when digests are required", func() {
+			GinkgoT().Setenv(config.RelatedImageEnvPrefix+"HOSTPATH", "registry.example.com/hostpath:latest")
+			Expect(flags.Parse([]string{"--require-image-digests"})).To(Succeed())
+			_, err := options.Load(ctx, nil)
+			Expect(err).To(MatchError(ContainSubstring("images.overrides.hostpath")))
+		})
+
+		It("should accept overrides pinned by digest when digests are required", func() {
+			GinkgoT().Setenv(config.RequireImageDigestsEnv, "true")
+			GinkgoT().Setenv(config.RelatedImageEnvPrefix+"HOSTPATH",
+				"registry.example.com/hostpath@sha256:798e0e860df8e3ca1ff5f8aa796940d6dbd4b739ccfc35c6f4c9bc046d5f0c12")
+			Expect(flags.Parse(nil)).To(Succeed())
+			cfg, err := options.Load(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Enabled(cfg.Images.RequireDigests)).To(BeTrue())
+		})
+	})
+
+	When("the configuration is invalid", func() {
+		It("should report every invalid field", func() {
+			GinkgoT().Setenv(config.SharedResourceManifestPathEnv, filepath.Join(manifests, "missing"))
+			Expect(flags.Parse([]string{"--operand-namespace", "Invalid_Namespace"})).To(Succeed())
+			_, err := options.Load(ctx, nil)
+			Expect(err).To(MatchError(ContainSubstring("operandNamespace: invalid namespace")))
+			Expect(err).To(MatchError(ContainSubstring("manifests.sharedResource")))
+		})
+
+		It("should reject invalid boolean environment variables", func() {
+			GinkgoT().Setenv(config.CleanupRoleBindingsEnv, "maybe")
+			Expect(flags.Parse(nil)).To(Succeed())
+			_, err := options.Load(ctx, nil)
+			Expect(err).To(MatchError(ContainSubstring(config.CleanupRoleBindingsEnv)))
+		})
+	})
+})
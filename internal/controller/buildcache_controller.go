@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildcache"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds,verbs=get;list;watch
+
+// BuildCacheRequeueInterval is the interval at which the watch of the Builds is retried while
+// Shipwright is not installed.
+var BuildCacheRequeueInterval = 5 * time.Minute
+
+// BuildCacheReconciler creates the build cache claims of the Builds annotated with
+// operator.openshift.io/build-cache. The OpenShiftBuild instance is reconciled to watch the
+// Builds once Shipwright is installed, and to create the claims of all the annotated Builds.
+type BuildCacheReconciler struct {
+	Client client.Client
+
+	watcher kindWatcher
+}
+
+// Reconcile creates the build cache claim of the requested Build, or of all the Builds
+func (r *BuildCacheReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	openShiftBuild := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: common.OpenShiftBuildResourceName}, openShiftBuild); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if req.Namespace == "" {
+		return r.reconcileCluster(ctx, openShiftBuild)
+	}
+	if !buildcache.Enabled(openShiftBuild) {
+		return ctrl.Result{}, nil
+	}
+
+	build := &buildv1beta1.Build{}
+	if err := r.Client.Get(ctx, req.NamespacedName, build); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	return ctrl.Result{}, r.createClaim(ctx, openShiftBuild, build)
+}
+
+// reconcileCluster watches the Builds once their kind is served, and creates the claims of all
+// the annotated Builds
+func (r *BuildCacheReconciler) reconcileCluster(ctx context.Context, openShiftBuild *openshiftv1alpha1.OpenShiftBuild) (ctrl.Result, error) {
+	build := &buildv1beta1.Build{}
+	build.SetGroupVersionKind(buildv1beta1.SchemeGroupVersion.WithKind("Build"))
+	requested := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return isBuildCacheRequested(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isBuildCacheRequested(e.ObjectNew) && !isBuildCacheRequested(e.ObjectOld)
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	watched, err := r.watcher.watch(ctx, r.Client.RESTMapper(), build, &handler.EnqueueRequestForObject{}, requested)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !watched {
+		return ctrl.Result{RequeueAfter: BuildCacheRequeueInterval}, nil
+	}
+	if !buildcache.Enabled(openShiftBuild) {
+		return ctrl.Result{}, nil
+	}
+
+	builds := &buildv1beta1.BuildList{}
+	if err := r.Client.List(ctx, builds); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list the Builds: %v", err)
+	}
+	for i := range builds.Items {
+		if !isBuildCacheRequested(&builds.Items[i]) {
+			continue
+		}
+		if err := r.createClaim(ctx, openShiftBuild, &builds.Items[i]); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// createClaim creates the build cache claim of the Build if it requests the cache and the claim
+// does not exist. The claims of a Build are controlled by the Build and removed with it, while
+// the claims shared by a namespace are kept. Existing claims are left unchanged, as most of their
+// spec is immutable.
+func (r *BuildCacheReconciler) createClaim(ctx context.Context, openShiftBuild *openshiftv1alpha1.OpenShiftBuild, build *buildv1beta1.Build) error {
+	logger := log.FromContext(ctx).WithValues("namespace", build.Namespace, "build", build.Name)
+	if !buildcache.Requested(nil, build) || !build.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	name, err := buildcache.ClaimName(openShiftBuild, build)
+	if err != nil {
+		logger.Info("Not creating the build cache claim", "reason", err.Error())
+		return nil
+	}
+
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: build.Namespace, Name: name}, &corev1.PersistentVolumeClaim{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	claim := buildcache.Claim(openShiftBuild, build.Namespace, name)
+	if name != buildcache.NamespaceClaimName {
+		if err := ctrl.SetControllerReference(build, claim, r.Client.Scheme()); err != nil {
+			return err
+		}
+	}
+	if err := r.Client.Create(ctx, claim); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the build cache claim %s/%s: %v", build.Namespace, name, err)
+	}
+	logger.Info("Build cache claim created", "claim", name)
+	return nil
+}
+
+// isBuildCacheRequested returns true for the Builds annotated to mount the build cache
+func isBuildCacheRequested(object client.Object) bool {
+	build, ok := object.(*buildv1beta1.Build)
+	return ok && buildcache.Requested(nil, build)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildCacheReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	r.watcher.cache = mgr.GetCache()
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("buildcache").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.watcher.controller = c
+	return nil
+}
@@ -0,0 +1,121 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildcache"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BuildCache controller", Label("buildcache"), func() {
+	var (
+		ctx            context.Context
+		fakeClient     client.Client
+		reconciler     *BuildCacheReconciler
+		openShiftBuild *openshiftv1alpha1.OpenShiftBuild
+		build          *buildv1beta1.Build
+		objects        []client.Object
+	)
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(build)})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	claim := func(name string) (*corev1.PersistentVolumeClaim, error) {
+		object := &corev1.PersistentVolumeClaim{}
+		err := fakeClient.Get(ctx, types.NamespacedName{Namespace: "team", Name: name}, object)
+		return object, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		openShiftBuild = &openshiftv1alpha1.OpenShiftBuild{ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName}}
+		openShiftBuild.Default()
+		openShiftBuild.Spec.BuildCache.State = openshiftv1alpha1.Enabled
+		build = &buildv1beta1.Build{ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "team",
+			Name:        "app",
+			UID:         "build-uid",
+			Annotations: map[string]string{buildcache.Annotation: "true"},
+		}}
+		objects = []client.Object{build}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, openShiftBuild)...).Build()
+		reconciler = &BuildCacheReconciler{Client: fakeClient}
+	})
+
+	It("creates the claim of the Build, controlled by the Build", func() {
+		reconcile()
+		object, err := claim("build-cache-app")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(metav1.IsControlledBy(object, build)).To(BeTrue())
+		Expect(object.Spec.Resources.Requests.Storage().String()).To(Equal("10Gi"))
+	})
+
+	When("the cache is shared by the namespace", func() {
+		BeforeEach(func() {
+			openShiftBuild.Spec.BuildCache.Scope = openshiftv1alpha1.BuildCacheScopeNamespace
+		})
+
+		It("creates the claim of the namespace, which outlives the Build", func() {
+			reconcile()
+			object, err := claim(buildcache.NamespaceClaimName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(object.OwnerReferences).To(BeEmpty())
+		})
+	})
+
+	When("the claim exists", func() {
+		BeforeEach(func() {
+			objects = append(objects, &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "build-cache-app"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			})
+		})
+
+		It("leaves it unchanged", func() {
+			reconcile()
+			object, err := claim("build-cache-app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(object.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+		})
+	})
+
+	When("the build cache is disabled", func() {
+		BeforeEach(func() {
+			openShiftBuild.Spec.BuildCache.State = openshiftv1alpha1.Disabled
+		})
+
+		It("creates no claim", func() {
+			reconcile()
+			_, err := claim("build-cache-app")
+			Expect(err).To(Satisfy(apierrors.IsNotFound))
+		})
+	})
+})
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+
+	"github.com/redhat-openshift-builds/operator/internal/migration"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups=build.openshift.io,resources=buildconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds;buildstrategies,verbs=get;list;watch;create;update
+
+// ConvertAnnotation requests the conversion of a BuildConfig to a Shipwright Build when set to "true"
+const ConvertAnnotation = "operator.openshift.io/convert-to-shipwright"
+
+// Reasons of the events recorded on the converted BuildConfigs
+const (
+	ReasonConverted        = "Converted"
+	ReasonConversionIssue  = "ConversionIssue"
+	ReasonConversionFailed = "ConversionFailed"
+)
+
+// BuildConfigMigrationReconciler converts the annotated BuildConfigs to Shipwright Builds. The
+// outcome of the conversion is recorded as events on the BuildConfig.
+type BuildConfigMigrationReconciler struct {
+	// Client writes the converted objects.
+	Client client.Client
+
+	// APIReader reads the BuildConfigs, of which only the metadata is cached.
+	APIReader client.Reader
+
+	// Recorder records the conversion events.
+	Recorder record.EventRecorder
+}
+
+// Reconcile converts the BuildConfig and applies the resulting objects
+func (r *BuildConfigMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name)
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(migration.BuildConfigGVK)
+	if err := r.APIReader.Get(ctx, req.NamespacedName, object); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !object.GetDeletionTimestamp().IsZero() || object.GetAnnotations()[ConvertAnnotation] != "true" {
+		return ctrl.Result{}, nil
+	}
+
+	buildConfig, err := migration.FromUnstructured(object)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	result, err := migration.Convert(buildConfig)
+	if err != nil {
+		// The conversion is retried when the BuildConfig changes
+		r.Recorder.Event(object, corev1.EventTypeWarning, ReasonConversionFailed, err.Error())
+		logger.Info("BuildConfig cannot be converted", "reason", err.Error())
+		return ctrl.Result{}, nil
+	}
+	if err := migration.Apply(ctx, r.Client, result); err != nil {
+		r.Recorder.Event(object, corev1.EventTypeWarning, ReasonConversionFailed, err.Error())
+		return ctrl.Result{}, err
+	}
+	for _, issue := range result.Issues {
+		r.Recorder.Event(object, corev1.EventTypeWarning, ReasonConversionIssue, issue.String())
+	}
+	r.Recorder.Eventf(object, corev1.EventTypeNormal, ReasonConverted, "Converted to the Shipwright Build %s", result.Build.Name)
+	logger.Info("BuildConfig converted", "build", result.Build.Name, "issues", len(result.Issues))
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. The controller is not started on
+// clusters which do not serve BuildConfigs.
+func (r *BuildConfigMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	gvk := migration.BuildConfigGVK
+	if _, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if apimeta.IsNoMatchError(err) {
+			mgr.GetLogger().Info("BuildConfigs are not served, the BuildConfig migration controller is disabled")
+			return nil
+		}
+		return err
+	}
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("openshift-builds-operator")
+	}
+
+	buildConfig := &metav1.PartialObjectMetadata{}
+	buildConfig.SetGroupVersionKind(gvk)
+	annotated := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetAnnotations()[ConvertAnnotation] == "true"
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("buildconfig-migration").
+		For(buildConfig, builder.WithPredicates(annotated, predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Complete(r)
+}
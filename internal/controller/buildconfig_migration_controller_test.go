@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/migration"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BuildConfig migration controller", Label("buildconfig-migration"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		recorder   *record.FakeRecorder
+		reconciler *BuildConfigMigrationReconciler
+		request    ctrl.Request
+	)
+
+	newBuildConfig := func(annotations map[string]string, strategy map[string]interface{}) *unstructured.Unstructured {
+		object := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"source":   map[string]interface{}{"git": map[string]interface{}{"uri": "https://github.com/example/app"}},
+				"strategy": strategy,
+				"output":   map[string]interface{}{"to": map[string]interface{}{"kind": "DockerImage", "name": "quay.io/example/app"}},
+			},
+		}}
+		object.SetGroupVersionKind(migration.BuildConfigGVK)
+		object.SetNamespace("test")
+		object.SetName("app")
+		object.SetAnnotations(annotations)
+		return object
+	}
+	dockerStrategy := map[string]interface{}{"dockerStrategy": map[string]interface{}{}}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &BuildConfigMigrationReconciler{Client: fakeClient, APIReader: fakeClient, Recorder: recorder}
+		request = ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "test", Name: "app"}}
+	})
+
+	It("converts the annotated BuildConfigs", func() {
+		Expect(fakeClient.Create(ctx, newBuildConfig(map[string]string{ConvertAnnotation: "true"}, dockerStrategy))).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		build := &buildv1beta1.Build{}
+		Expect(fakeClient.Get(ctx, request.NamespacedName, build)).To(Succeed())
+		Expect(build.Spec.Output.Image).To(Equal("quay.io/example/app"))
+		Expect(recorder.Events).To(Receive(HavePrefix("Normal Converted")))
+	})
+
+	It("ignores the BuildConfigs which are not annotated", func() {
+		Expect(fakeClient.Create(ctx, newBuildConfig(nil, dockerStrategy))).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, request.NamespacedName, &buildv1beta1.Build{})).NotTo(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("records the conversion failures", func() {
+		jenkins := map[string]interface{}{"jenkinsPipelineStrategy": map[string]interface{}{}}
+		Expect(fakeClient.Create(ctx, newBuildConfig(map[string]string{ConvertAnnotation: "true"}, jenkins))).To(Succeed())
+
+		_, err := reconciler.Reconcile(ctx, request)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events).To(Receive(HavePrefix("Warning ConversionFailed")))
+	})
+})
@@ -0,0 +1,268 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/scc"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildnamespaceconfigs,verbs=get;list;watch
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildnamespaceconfigs/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=sharedresource.openshift.io,resources=sharedconfigmaps;sharedsecrets,verbs=use
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames={"system:image-builder","shipwright-build-aggregate-edit","shipwright-build-aggregate-view","openshift-builds-buildah","openshift-builds-buildpacks","openshift-builds-source-to-image"},verbs=bind
+
+// Names of the objects created in the namespaces onboarded to builds
+const (
+	BuildNamespaceImageBuilderName   = "openshift-builds-image-builder"
+	BuildNamespaceEditorsName        = "openshift-builds-editors"
+	BuildNamespaceViewersName        = "openshift-builds-viewers"
+	BuildNamespaceSharedResourceName = "openshift-builds-shared-resources"
+	BuildNamespaceQuotaName          = "openshift-builds"
+)
+
+// ClusterRoles bound in the namespaces onboarded to builds
+const (
+	imageBuilderClusterRole = "system:image-builder"
+	buildEditClusterRole    = "shipwright-build-aggregate-edit"
+	buildViewClusterRole    = "shipwright-build-aggregate-view"
+)
+
+// BuildNamespaceConfigReconciler onboards the namespaces holding a BuildNamespaceConfig to
+// builds. The objects it creates are controlled by the BuildNamespaceConfig, and removed by the
+// garbage collector when the namespace opts out by deleting it. A pre-existing service account
+// is used as is, and kept.
+type BuildNamespaceConfigReconciler struct {
+	Client client.Client
+}
+
+// Reconcile creates the service account, RoleBindings and quota of the BuildNamespaceConfig
+func (r *BuildNamespaceConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name)
+
+	config := &openshiftv1alpha1.BuildNamespaceConfig{}
+	if err := r.Client.Get(ctx, req.NamespacedName, config); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !config.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	pushSecret, err := r.reconcileNamespace(ctx, config)
+	condition := metav1.Condition{
+		Type:               openshiftv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Onboarded",
+		Message:            fmt.Sprintf("Namespace is onboarded to builds with service account %s", serviceAccountName(config)),
+		ObservedGeneration: config.Generation,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReconcileFailed"
+		condition.Message = err.Error()
+	}
+	apimeta.SetStatusCondition(&config.Status.Conditions, condition)
+	config.Status.PushSecret = pushSecret
+	if statusErr := r.Client.Status().Update(ctx, config); statusErr != nil {
+		logger.Error(statusErr, "Failed to update status")
+		if err == nil {
+			err = statusErr
+		}
+	}
+	return ctrl.Result{}, err
+}
+
+// reconcileNamespace creates the objects onboarding the namespace, and returns the push secret of
+// the service account
+func (r *BuildNamespaceConfigReconciler) reconcileNamespace(ctx context.Context, config *openshiftv1alpha1.BuildNamespaceConfig) (string, error) {
+	name := serviceAccountName(config)
+	if err := r.reconcileServiceAccount(ctx, config, name); err != nil {
+		return "", err
+	}
+	serviceAccount := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: config.Namespace, Name: name}}
+
+	// The image builder role allows the service account to push to the internal registry with
+	// the credentials generated by OpenShift
+	if err := r.reconcileRoleBinding(ctx, config, BuildNamespaceImageBuilderName, "ClusterRole", imageBuilderClusterRole, serviceAccount); err != nil {
+		return "", err
+	}
+	if err := r.reconcileRoleBinding(ctx, config, BuildNamespaceEditorsName, "ClusterRole", buildEditClusterRole, config.Spec.Editors); err != nil {
+		return "", err
+	}
+	if err := r.reconcileRoleBinding(ctx, config, BuildNamespaceViewersName, "ClusterRole", buildViewClusterRole, config.Spec.Viewers); err != nil {
+		return "", err
+	}
+	if err := r.reconcileSharedResourceRole(ctx, config); err != nil {
+		return "", err
+	}
+	subjects := serviceAccount
+	if len(config.Spec.SharedSecrets) == 0 && len(config.Spec.SharedConfigMaps) == 0 {
+		subjects = nil
+	}
+	if err := r.reconcileRoleBinding(ctx, config, BuildNamespaceSharedResourceName, "Role", BuildNamespaceSharedResourceName, subjects); err != nil {
+		return "", err
+	}
+	// The SecurityContextConstraints of the strategies allow the service account to run them
+	for _, strategy := range scc.Strategies {
+		subjects := serviceAccount
+		if !slices.Contains(config.Spec.Strategies, openshiftv1alpha1.BuildStrategyName(strategy)) {
+			subjects = nil
+		}
+		if err := r.reconcileRoleBinding(ctx, config, scc.Name(strategy), "ClusterRole", scc.Name(strategy), subjects); err != nil {
+			return "", err
+		}
+	}
+	if err := r.reconcileQuota(ctx, config); err != nil {
+		return "", err
+	}
+	return imagestreams.PushSecret(ctx, r.Client, config.Namespace, ptr.To(name))
+}
+
+// reconcileServiceAccount creates the service account running the builds if it does not exist.
+// Only the service accounts created by the operator are controlled by the BuildNamespaceConfig.
+func (r *BuildNamespaceConfigReconciler) reconcileServiceAccount(ctx context.Context, config *openshiftv1alpha1.BuildNamespaceConfig, name string) error {
+	serviceAccount := &corev1.ServiceAccount{}
+	err := r.Client.Get(ctx, types.NamespacedName{Namespace: config.Namespace, Name: name}, serviceAccount)
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+	serviceAccount = &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: config.Namespace, Name: name}}
+	if err := ctrl.SetControllerReference(config, serviceAccount, r.Client.Scheme()); err != nil {
+		return err
+	}
+	if err := r.Client.Create(ctx, serviceAccount); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create ServiceAccount %s: %v", name, err)
+	}
+	return nil
+}
+
+// reconcileRoleBinding binds the role to the subjects, or deletes the RoleBinding when there are
+// no subjects
+func (r *BuildNamespaceConfigReconciler) reconcileRoleBinding(ctx context.Context, config *openshiftv1alpha1.BuildNamespaceConfig, name, kind, role string, subjects []rbacv1.Subject) error {
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: config.Namespace, Name: name}}
+	if len(subjects) == 0 {
+		return r.deleteOwned(ctx, config, binding)
+	}
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, binding, func() error {
+		if binding.CreationTimestamp.IsZero() {
+			binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: kind, Name: role}
+		}
+		binding.Subjects = subjects
+		return ctrl.SetControllerReference(config, binding, r.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile RoleBinding %s: %v", name, err)
+	}
+	return nil
+}
+
+// reconcileSharedResourceRole allows the use of the SharedSecrets and SharedConfigMaps of the
+// BuildNamespaceConfig, or deletes the Role when there are none
+func (r *BuildNamespaceConfigReconciler) reconcileSharedResourceRole(ctx context.Context, config *openshiftv1alpha1.BuildNamespaceConfig) error {
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: config.Namespace, Name: BuildNamespaceSharedResourceName}}
+	rules := []rbacv1.PolicyRule{}
+	if len(config.Spec.SharedSecrets) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"sharedresource.openshift.io"},
+			Resources:     []string{"sharedsecrets"},
+			ResourceNames: config.Spec.SharedSecrets,
+			Verbs:         []string{"use"},
+		})
+	}
+	if len(config.Spec.SharedConfigMaps) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"sharedresource.openshift.io"},
+			Resources:     []string{"sharedconfigmaps"},
+			ResourceNames: config.Spec.SharedConfigMaps,
+			Verbs:         []string{"use"},
+		})
+	}
+	if len(rules) == 0 {
+		return r.deleteOwned(ctx, config, role)
+	}
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Rules = rules
+		return ctrl.SetControllerReference(config, role, r.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile Role %s: %v", BuildNamespaceSharedResourceName, err)
+	}
+	return nil
+}
+
+// reconcileQuota applies the quota of the BuildNamespaceConfig, or deletes it when omitted
+func (r *BuildNamespaceConfigReconciler) reconcileQuota(ctx context.Context, config *openshiftv1alpha1.BuildNamespaceConfig) error {
+	quota := &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: config.Namespace, Name: BuildNamespaceQuotaName}}
+	if config.Spec.Quota == nil {
+		return r.deleteOwned(ctx, config, quota)
+	}
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, quota, func() error {
+		quota.Spec = *config.Spec.Quota.DeepCopy()
+		return ctrl.SetControllerReference(config, quota, r.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ResourceQuota %s: %v", BuildNamespaceQuotaName, err)
+	}
+	return nil
+}
+
+// deleteOwned deletes the object if it is controlled by the BuildNamespaceConfig
+func (r *BuildNamespaceConfigReconciler) deleteOwned(ctx context.Context, config *openshiftv1alpha1.BuildNamespaceConfig, object client.Object) error {
+	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(object, config) {
+		return nil
+	}
+	if err := r.Client.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %T %s: %v", object, object.GetName(), err)
+	}
+	return nil
+}
+
+// buildNamespaceConfig returns the BuildNamespaceConfig of the namespace of the object, so that
+// the push secret is reported once OpenShift generates it for the service account
+func (r *BuildNamespaceConfigReconciler) buildNamespaceConfig(_ context.Context, object client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{
+		Namespace: object.GetNamespace(),
+		Name:      openshiftv1alpha1.BuildNamespaceConfigName,
+	}}}
+}
+
+// serviceAccountName returns the service account running the builds of the namespace
+func serviceAccountName(config *openshiftv1alpha1.BuildNamespaceConfig) string {
+	if config.Spec.ServiceAccountName != "" {
+		return config.Spec.ServiceAccountName
+	}
+	return imagestreams.DefaultServiceAccounts[0]
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildNamespaceConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&openshiftv1alpha1.BuildNamespaceConfig{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&corev1.ResourceQuota{}).
+		Watches(&corev1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(r.buildNamespaceConfig)).
+		Complete(r)
+}
@@ -0,0 +1,171 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BuildNamespaceConfig controller", Label("buildnamespaceconfig"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *BuildNamespaceConfigReconciler
+		config     *openshiftv1alpha1.BuildNamespaceConfig
+		objects    []client.Object
+	)
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(config)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(config), config)).To(Succeed())
+	}
+
+	key := func(name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: "team", Name: name}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		config = &openshiftv1alpha1.BuildNamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: openshiftv1alpha1.BuildNamespaceConfigName, UID: "config-uid"},
+			Spec: openshiftv1alpha1.BuildNamespaceConfigSpec{
+				ServiceAccountName: "pipeline",
+				Editors:            []rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "developers"}},
+			},
+		}
+		objects = []client.Object{config}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+			WithStatusSubresource(&openshiftv1alpha1.BuildNamespaceConfig{}).Build()
+		reconciler = &BuildNamespaceConfigReconciler{Client: fakeClient}
+	})
+
+	It("onboards the namespace", func() {
+		reconcile()
+
+		serviceAccount := &corev1.ServiceAccount{}
+		Expect(fakeClient.Get(ctx, key("pipeline"), serviceAccount)).To(Succeed())
+		Expect(metav1.IsControlledBy(serviceAccount, config)).To(BeTrue())
+
+		binding := &rbacv1.RoleBinding{}
+		Expect(fakeClient.Get(ctx, key(BuildNamespaceImageBuilderName), binding)).To(Succeed())
+		Expect(binding.RoleRef.Name).To(Equal("system:image-builder"))
+		Expect(binding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "team", Name: "pipeline"}}))
+		Expect(fakeClient.Get(ctx, key(BuildNamespaceEditorsName), binding)).To(Succeed())
+		Expect(binding.RoleRef.Name).To(Equal("shipwright-build-aggregate-edit"))
+		Expect(binding.Subjects[0].Name).To(Equal("developers"))
+
+		Expect(fakeClient.Get(ctx, key(BuildNamespaceViewersName), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		Expect(fakeClient.Get(ctx, key(BuildNamespaceSharedResourceName), &rbacv1.Role{})).To(Satisfy(apierrors.IsNotFound))
+		Expect(fakeClient.Get(ctx, key(BuildNamespaceQuotaName), &corev1.ResourceQuota{})).To(Satisfy(apierrors.IsNotFound))
+		Expect(apimeta.IsStatusConditionTrue(config.Status.Conditions, openshiftv1alpha1.ConditionReady)).To(BeTrue())
+	})
+
+	When("the service account exists", func() {
+		BeforeEach(func() {
+			objects = append(objects, &corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Namespace: "team", Name: "pipeline"},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pipeline-dockercfg-abcde"}},
+			})
+		})
+
+		It("keeps it and reports its push secret", func() {
+			reconcile()
+			serviceAccount := &corev1.ServiceAccount{}
+			Expect(fakeClient.Get(ctx, key("pipeline"), serviceAccount)).To(Succeed())
+			Expect(serviceAccount.OwnerReferences).To(BeEmpty())
+			Expect(config.Status.PushSecret).To(Equal("pipeline-dockercfg-abcde"))
+		})
+	})
+
+	When("shared resources and a quota are requested", func() {
+		BeforeEach(func() {
+			config.Spec.SharedSecrets = []string{"openshift-etc-pki-entitlement"}
+			config.Spec.Quota = &corev1.ResourceQuotaSpec{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("8")},
+			}
+		})
+
+		It("grants their use and applies the quota", func() {
+			reconcile()
+			role := &rbacv1.Role{}
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceSharedResourceName), role)).To(Succeed())
+			Expect(role.Rules).To(HaveLen(1))
+			Expect(role.Rules[0].ResourceNames).To(Equal([]string{"openshift-etc-pki-entitlement"}))
+			Expect(role.Rules[0].Verbs).To(Equal([]string{"use"}))
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceSharedResourceName), &rbacv1.RoleBinding{})).To(Succeed())
+
+			quota := &corev1.ResourceQuota{}
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceQuotaName), quota)).To(Succeed())
+			Expect(quota.Spec.Hard.Name(corev1.ResourceRequestsCPU, resource.DecimalSI).String()).To(Equal("8"))
+		})
+
+		It("removes them when no longer requested", func() {
+			reconcile()
+			config.Spec.SharedSecrets = nil
+			config.Spec.Quota = nil
+			Expect(fakeClient.Update(ctx, config)).To(Succeed())
+
+			reconcile()
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceSharedResourceName), &rbacv1.Role{})).To(Satisfy(apierrors.IsNotFound))
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceSharedResourceName), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceQuotaName), &corev1.ResourceQuota{})).To(Satisfy(apierrors.IsNotFound))
+		})
+	})
+
+	When("strategies are requested", func() {
+		BeforeEach(func() {
+			config.Spec.Strategies = []openshiftv1alpha1.BuildStrategyName{openshiftv1alpha1.BuildahStrategy}
+		})
+
+		It("grants the use of their SecurityContextConstraints to the service account", func() {
+			reconcile()
+			binding := &rbacv1.RoleBinding{}
+			Expect(fakeClient.Get(ctx, key("openshift-builds-buildah"), binding)).To(Succeed())
+			Expect(binding.RoleRef).To(Equal(rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "openshift-builds-buildah"}))
+			Expect(binding.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "team", Name: "pipeline"}}))
+			Expect(fakeClient.Get(ctx, key("openshift-builds-buildpacks"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("revokes it when no longer requested", func() {
+			reconcile()
+			config.Spec.Strategies = nil
+			Expect(fakeClient.Update(ctx, config)).To(Succeed())
+
+			reconcile()
+			Expect(fakeClient.Get(ctx, key("openshift-builds-buildah"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+	})
+
+	When("a quota of the same name exists", func() {
+		BeforeEach(func() {
+			objects = append(objects, &corev1.ResourceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: BuildNamespaceQuotaName}})
+		})
+
+		It("leaves the objects it does not control", func() {
+			reconcile()
+			Expect(fakeClient.Get(ctx, key(BuildNamespaceQuotaName), &corev1.ResourceQuota{})).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,317 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+//+kubebuilder:rbac:groups=shipwright.io,resources=buildruns,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=shipwright.io,resources=buildruns/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildnamespaceconfigs,verbs=get;list;watch
+
+// BuildQueueRequeueInterval is the interval at which the watch of the BuildRuns is retried while
+// Shipwright is not installed.
+var BuildQueueRequeueInterval = 5 * time.Minute
+
+// BuildQueueReconciler starts the queued BuildRuns of a namespace within the concurrency limits of
+// its BuildNamespaceConfig, reports their Queued condition, and cancels them once their timeout
+// elapses from their start. The pods of the queued BuildRuns are held by a scheduling gate, removed
+// when they start. The OpenShiftBuild instance is reconciled to watch the BuildRuns once Shipwright
+// is installed, and the BuildRun, pod and BuildNamespaceConfig events are reconciled as a request
+// for their namespace, without a name.
+type BuildQueueReconciler struct {
+	Client client.Client
+
+	// APIReader lists the BuildRuns from the API server, so that the BuildRuns started by the
+	// previous reconcile are counted as running even when the cache lags.
+	APIReader client.Reader
+
+	// Enabled enforces the concurrency limits. All queued BuildRuns are started when disabled.
+	Enabled bool
+
+	watcher kindWatcher
+}
+
+// Reconcile starts the queued BuildRuns of the requested namespace
+func (r *BuildQueueReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Namespace == "" {
+		return r.reconcileWatch(ctx)
+	}
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace)
+
+	var concurrency *openshiftv1alpha1.BuildConcurrency
+	if r.Enabled {
+		config := &openshiftv1alpha1.BuildNamespaceConfig{}
+		err := r.Client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: openshiftv1alpha1.BuildNamespaceConfigName}, config)
+		if client.IgnoreNotFound(err) != nil {
+			return ctrl.Result{}, err
+		}
+		if err == nil && config.DeletionTimestamp.IsZero() {
+			concurrency = config.Spec.Concurrency
+		}
+	}
+
+	buildRuns := &buildv1beta1.BuildRunList{}
+	if err := r.APIReader.List(ctx, buildRuns, client.InNamespace(req.Namespace)); err != nil {
+		if apimeta.IsNoMatchError(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to list the BuildRuns of namespace %s: %v", req.Namespace, err)
+	}
+	queue := buildqueue.Schedule(concurrency, buildRuns.Items)
+
+	now := time.Now()
+	for _, buildRun := range queue.Released {
+		if err := r.start(ctx, buildRun, now); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("BuildRun started", "buildrun", buildRun.Name)
+	}
+	queued := map[string]bool{}
+	for _, buildRun := range queue.Queued {
+		queued[buildRun.Name] = true
+		err := r.setQueuedCondition(ctx, buildRun, corev1.ConditionTrue, buildqueue.ReasonConcurrencyLimit,
+			"The BuildRun waits for the concurrency limits of the namespace")
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.ungate(ctx, req.Namespace, queued); err != nil {
+		return ctrl.Result{}, err
+	}
+	result, err := r.enforceTimeouts(ctx, buildRuns.Items, now)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	buildqueue.QueuedBuildRuns.DeletePartialMatch(prometheus.Labels{"namespace": req.Namespace})
+	buildqueue.RunningBuildRuns.DeletePartialMatch(prometheus.Labels{"namespace": req.Namespace})
+	if buildqueue.Limited(concurrency) {
+		for _, buildRun := range queue.Queued {
+			buildqueue.QueuedBuildRuns.WithLabelValues(req.Namespace, buildqueue.Strategy(buildRun)).Inc()
+		}
+		for strategy, running := range queue.Running {
+			buildqueue.RunningBuildRuns.WithLabelValues(req.Namespace, strategy).Set(float64(running))
+		}
+	}
+	return result, nil
+}
+
+// reconcileWatch watches the BuildRuns once their kind is served
+func (r *BuildQueueReconciler) reconcileWatch(ctx context.Context) (ctrl.Result, error) {
+	buildRun := &buildv1beta1.BuildRun{}
+	buildRun.SetGroupVersionKind(buildv1beta1.SchemeGroupVersion.WithKind("BuildRun"))
+	// Queued BuildRuns enter the queue or learn their strategy, and any BuildRun frees a slot
+	// when it completes or is deleted
+	queueChanged := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return isAdmitted(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*buildv1beta1.BuildRun)
+			buildRun, ok2 := e.ObjectNew.(*buildv1beta1.BuildRun)
+			if !ok || !ok2 {
+				return false
+			}
+			return old.IsDone() != buildRun.IsDone() ||
+				old.DeletionTimestamp.IsZero() != buildRun.DeletionTimestamp.IsZero() ||
+				(buildqueue.Admitted(buildRun) && (buildqueue.Queued(old) != buildqueue.Queued(buildRun) ||
+					buildqueue.Strategy(old) != buildqueue.Strategy(buildRun)))
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	watched, err := r.watcher.watch(ctx, r.Client.RESTMapper(), buildRun, handler.EnqueueRequestsFromMapFunc(namespaceRequest), queueChanged)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !watched {
+		return ctrl.Result{RequeueAfter: BuildQueueRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// start records the start of a queued BuildRun, which starts counting its timeout, and reports it
+// in its Queued condition. Its pods are ungated afterwards.
+func (r *BuildQueueReconciler) start(ctx context.Context, buildRun *buildv1beta1.BuildRun, now time.Time) error {
+	patch := client.MergeFrom(buildRun.DeepCopy())
+	metav1.SetMetaDataAnnotation(&buildRun.ObjectMeta, buildqueue.StartedAnnotation, now.UTC().Format(time.RFC3339))
+	if err := r.Client.Patch(ctx, buildRun, patch); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to start the BuildRun %s/%s: %v", buildRun.Namespace, buildRun.Name, err)
+	}
+	return r.setQueuedCondition(ctx, buildRun, corev1.ConditionFalse, buildqueue.ReasonStarted,
+		"The BuildRun was started within the concurrency limits of the namespace")
+}
+
+// ungate removes the scheduling gate of the queued BuildRun pods of the namespace, except for the
+// BuildRuns still queued
+func (r *BuildQueueReconciler) ungate(ctx context.Context, namespace string, queued map[string]bool) error {
+	pods := &corev1.PodList{}
+	if err := r.APIReader.List(ctx, pods, client.InNamespace(namespace), client.HasLabels{buildqueue.Label}); err != nil {
+		return fmt.Errorf("failed to list the BuildRun pods of namespace %s: %v", namespace, err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if !buildqueue.Gated(pod) || queued[pod.Labels[buildv1beta1.LabelBuildRun]] {
+			continue
+		}
+		buildqueue.Ungate(pod)
+		err := r.Client.Update(ctx, pod)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to start the pod %s/%s: %v", namespace, pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// enforceTimeouts cancels the BuildRuns started by the queue once their timeout elapses, as their
+// TaskRuns run without timeout, and returns the result requeueing the next timeout
+func (r *BuildQueueReconciler) enforceTimeouts(ctx context.Context, buildRuns []buildv1beta1.BuildRun, now time.Time) (ctrl.Result, error) {
+	result := ctrl.Result{}
+	for i := range buildRuns {
+		buildRun := &buildRuns[i]
+		started := buildqueue.Started(buildRun)
+		if !buildqueue.Admitted(buildRun) || started == nil || buildRun.IsDone() || buildRun.IsCanceled() ||
+			!buildRun.DeletionTimestamp.IsZero() {
+			continue
+		}
+		timeout := buildqueue.Timeout(buildRun)
+		if timeout <= 0 {
+			continue
+		}
+		if next := started.Add(timeout).Sub(now); next > 0 {
+			if result.RequeueAfter == 0 || next < result.RequeueAfter {
+				result.RequeueAfter = next
+			}
+			continue
+		}
+
+		patch := client.MergeFrom(buildRun.DeepCopy())
+		buildRun.Spec.State = ptr.To[buildv1beta1.BuildRunRequestedState](buildv1beta1.BuildRunStateCancel)
+		if err := r.Client.Patch(ctx, buildRun, patch); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return ctrl.Result{}, fmt.Errorf("failed to cancel the BuildRun %s/%s: %v", buildRun.Namespace, buildRun.Name, err)
+		}
+		err := r.setQueuedCondition(ctx, buildRun, corev1.ConditionFalse, buildqueue.ReasonTimeout,
+			fmt.Sprintf("The BuildRun was canceled after running for its timeout of %s", timeout))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		log.FromContext(ctx).Info("BuildRun timed out", "namespace", buildRun.Namespace, "buildrun", buildRun.Name, "timeout", timeout.String())
+	}
+	return result, nil
+}
+
+// setQueuedCondition patches the Queued condition of the BuildRun. Shipwright updates the status
+// of the BuildRun concurrently, so the patch fails on conflict and the namespace is reconciled again.
+func (r *BuildQueueReconciler) setQueuedCondition(ctx context.Context, buildRun *buildv1beta1.BuildRun, status corev1.ConditionStatus, reason, message string) error {
+	condition := buildRun.Status.GetCondition(buildqueue.ConditionQueued)
+	if condition != nil && condition.Status == status && condition.Reason == reason {
+		return nil
+	}
+	patch := client.MergeFromWithOptions(buildRun.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	buildRun.Status.SetCondition(&buildv1beta1.Condition{
+		Type:               buildqueue.ConditionQueued,
+		Status:             status,
+		LastTransitionTime: metav1.Now(),
+		Reason:             reason,
+		Message:            message,
+	})
+	err := r.Client.Status().Patch(ctx, buildRun, patch)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update the Queued condition of BuildRun %s/%s: %v", buildRun.Namespace, buildRun.Name, err)
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildQueueReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+	r.watcher.cache = mgr.GetCache()
+
+	// The pods of the queued BuildRuns are watched through their own cache, so that the manager
+	// cache neither holds all the pods of the cluster nor filters the pods read by other components
+	queuedPod, err := labels.NewRequirement(buildqueue.Label, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	podCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		HTTPClient: mgr.GetHTTPClient(),
+		Scheme:     mgr.GetScheme(),
+		Mapper:     mgr.GetRESTMapper(),
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Pod{}: {Label: labels.NewSelector().Add(*queuedPod)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(podCache); err != nil {
+		return err
+	}
+
+	// The pods of the queued BuildRuns are gated on create, and are ungated if their BuildRun
+	// already started
+	podQueued := predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isAdmitted(e.Object) },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("buildqueue").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WatchesRawSource(source.Kind(podCache, client.Object(&corev1.Pod{}), handler.EnqueueRequestsFromMapFunc(namespaceRequest), podQueued)).
+		Watches(&openshiftv1alpha1.BuildNamespaceConfig{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.watcher.controller = c
+	return nil
+}
+
+// isAdmitted returns true for the BuildRuns admitted into a queue, and their pods
+func isAdmitted(object client.Object) bool {
+	_, ok := object.GetLabels()[buildqueue.Label]
+	return ok
+}
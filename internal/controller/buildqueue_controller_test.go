@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Build queue controller", Label("buildqueue"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *BuildQueueReconciler
+		config     *openshiftv1alpha1.BuildNamespaceConfig
+		objects    []client.Object
+	)
+
+	buildRun := func(name string, age time.Duration, started bool) *buildv1beta1.BuildRun {
+		buildRun := &buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "team",
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+				Labels:            map[string]string{buildqueue.Label: ""},
+				Annotations:       map[string]string{buildqueue.TimeoutAnnotation: "1h0m0s"},
+			},
+			Spec: buildv1beta1.BuildRunSpec{Timeout: &metav1.Duration{}},
+			Status: buildv1beta1.BuildRunStatus{BuildSpec: &buildv1beta1.BuildSpec{
+				Strategy: buildv1beta1.Strategy{Name: "buildah"},
+			}},
+		}
+		if started {
+			buildRun.Annotations[buildqueue.StartedAnnotation] = time.Now().Add(-age).UTC().Format(time.RFC3339)
+		}
+		return buildRun
+	}
+
+	pod := func(name string, gated bool) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "team",
+			Name:      name + "-pod",
+			Labels: map[string]string{
+				buildqueue.Label:           "",
+				buildv1beta1.LabelBuildRun: name,
+			},
+		}}
+		if gated {
+			pod.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: buildqueue.SchedulingGate}}
+		}
+		return pod
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team"}})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	get := func(name string) *buildv1beta1.BuildRun {
+		buildRun := &buildv1beta1.BuildRun{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team", Name: name}, buildRun)).To(Succeed())
+		return buildRun
+	}
+
+	queued := func(name string) *buildv1beta1.Condition {
+		return get(name).Status.GetCondition(buildqueue.ConditionQueued)
+	}
+
+	gated := func(name string) bool {
+		pod := &corev1.Pod{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "team", Name: name + "-pod"}, pod)).To(Succeed())
+		return buildqueue.Gated(pod)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		config = &openshiftv1alpha1.BuildNamespaceConfig{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: openshiftv1alpha1.BuildNamespaceConfigName},
+			Spec: openshiftv1alpha1.BuildNamespaceConfigSpec{
+				Concurrency: &openshiftv1alpha1.BuildConcurrency{MaxRunning: ptr.To[int32](2)},
+			},
+		}
+		objects = []client.Object{
+			config,
+			buildRun("running", 10*time.Minute, true),
+			buildRun("first", 5*time.Minute, false),
+			buildRun("second", time.Minute, false),
+			pod("running", false),
+			pod("first", true),
+			pod("second", true),
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+			WithStatusSubresource(&buildv1beta1.BuildRun{}).Build()
+		reconciler = &BuildQueueReconciler{Client: fakeClient, APIReader: fakeClient, Enabled: true}
+	})
+
+	It("starts the queued BuildRuns within the concurrency limit", func() {
+		reconcile()
+		Expect(buildqueue.Started(get("first"))).NotTo(BeNil())
+		Expect(queued("first").Status).To(Equal(corev1.ConditionFalse))
+		Expect(queued("first").Reason).To(Equal(buildqueue.ReasonStarted))
+		Expect(gated("first")).To(BeFalse())
+
+		Expect(buildqueue.Started(get("second"))).To(BeNil())
+		Expect(queued("second").Status).To(Equal(corev1.ConditionTrue))
+		Expect(queued("second").Reason).To(Equal(buildqueue.ReasonConcurrencyLimit))
+		Expect(gated("second")).To(BeTrue())
+	})
+
+	It("starts the next queued BuildRun when a running BuildRun completes", func() {
+		reconcile()
+
+		running := get("running")
+		running.Status.SetCondition(&buildv1beta1.Condition{Type: buildv1beta1.Succeeded, Status: corev1.ConditionTrue})
+		Expect(fakeClient.Status().Update(ctx, running)).To(Succeed())
+
+		reconcile()
+		Expect(queued("second").Status).To(Equal(corev1.ConditionFalse))
+		Expect(gated("second")).To(BeFalse())
+	})
+
+	It("starts all queued BuildRuns once the limits are removed", func() {
+		config.Spec.Concurrency = nil
+		Expect(fakeClient.Update(ctx, config)).To(Succeed())
+		reconcile()
+		Expect(gated("first")).To(BeFalse())
+		Expect(gated("second")).To(BeFalse())
+	})
+
+	It("requeues the namespace for the timeout of the started BuildRuns", func() {
+		result := reconcile()
+		Expect(result.RequeueAfter).To(BeNumerically("~", 50*time.Minute, time.Minute))
+	})
+
+	When("a started BuildRun exceeds its timeout", func() {
+		BeforeEach(func() {
+			objects[1] = buildRun("running", 2*time.Hour, true)
+		})
+
+		It("cancels it", func() {
+			reconcile()
+			Expect(get("running").IsCanceled()).To(BeTrue())
+			Expect(queued("running").Reason).To(Equal(buildqueue.ReasonTimeout))
+		})
+	})
+
+	When("a BuildRun was created outside of the queue", func() {
+		BeforeEach(func() {
+			unqueued := buildRun("unqueued", time.Hour, false)
+			unqueued.Labels = nil
+			objects = append(objects, unqueued)
+		})
+
+		It("counts it as running without enforcing its timeout", func() {
+			reconcile()
+			Expect(gated("first")).To(BeTrue())
+			Expect(get("unqueued").Status.GetCondition(buildqueue.ConditionQueued)).To(BeNil())
+		})
+	})
+
+	When("the queue is disabled", func() {
+		JustBeforeEach(func() {
+			reconciler.Enabled = false
+		})
+
+		It("starts all queued BuildRuns", func() {
+			reconcile()
+			Expect(gated("first")).To(BeFalse())
+			Expect(gated("second")).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,240 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=image.openshift.io,resources=imagestreams,verbs=get;list;watch
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds,verbs=get;list;watch;update
+//+kubebuilder:rbac:groups=shipwright.io,resources=buildruns,verbs=get;list;watch;create
+
+// BuildTriggerRequeueInterval is the interval at which the watches of the Builds and ImageStreams
+// are retried while their kinds are not served.
+var BuildTriggerRequeueInterval = 5 * time.Minute
+
+// BuildTriggerReconciler creates a BuildRun of the Builds annotated with ImageStream triggers when
+// the image of a triggering ImageStreamTag changes. The images which triggered the Build are
+// recorded in an annotation of the Build, as the Build status is owned by Shipwright. The first
+// image seen for a tag is recorded without triggering a BuildRun.
+type BuildTriggerReconciler struct {
+	Client client.Client
+
+	watcher kindWatcher
+}
+
+// Reconcile triggers a BuildRun of the Build when the images of its triggers changed
+func (r *BuildTriggerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Namespace == "" {
+		return r.reconcileWatches(ctx)
+	}
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name)
+
+	build := &buildv1beta1.Build{}
+	if err := r.Client.Get(ctx, req.NamespacedName, build); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	value, ok := build.Annotations[imagestreams.TriggersAnnotation]
+	if !ok || !build.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	triggers, err := imagestreams.ParseTriggers(build.Namespace, value)
+	if err != nil {
+		logger.Info("Ignoring invalid ImageStream triggers", "reason", err.Error())
+	}
+
+	now := metav1.Now()
+	status := imagestreams.ParseTriggersStatus(build.Annotations[imagestreams.TriggersStatusAnnotation])
+	updated := []imagestreams.TriggerStatus{}
+	changed := []string{}
+	changedFrom := map[string]bool{}
+	params := []buildv1beta1.ParamValue{}
+	for _, trigger := range triggers {
+		from := trigger.From()
+		i := slices.IndexFunc(status, func(s imagestreams.TriggerStatus) bool { return s.From == from })
+		var last *imagestreams.TriggerStatus
+		if i >= 0 {
+			last = &status[i]
+		}
+
+		image, reference, err := r.resolve(ctx, &trigger)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if image == "" {
+			// Keep the last image until the tag is resolved again
+			if last != nil {
+				updated = append(updated, *last)
+			}
+			continue
+		}
+		if trigger.Param != "" {
+			params = append(params, buildv1beta1.ParamValue{
+				Name:        trigger.Param,
+				SingleValue: &buildv1beta1.SingleValue{Value: ptr.To(reference)},
+			})
+		}
+		switch {
+		case last == nil:
+			updated = append(updated, imagestreams.TriggerStatus{From: from, LastTriggeredImageID: image, Time: now})
+		case last.LastTriggeredImageID != image:
+			changed = append(changed, from+"@"+image)
+			changedFrom[from] = true
+			updated = append(updated, imagestreams.TriggerStatus{From: from, LastTriggeredImageID: image, Time: now})
+		default:
+			updated = append(updated, *last)
+		}
+	}
+
+	if len(changed) > 0 {
+		name, err := r.createBuildRun(ctx, build, changed, params)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		for i := range updated {
+			if changedFrom[updated[i].From] {
+				updated[i].BuildRun = name
+			}
+		}
+		logger.Info("BuildRun triggered by ImageStream change", "buildrun", name, "images", changed)
+	}
+	return ctrl.Result{}, r.updateStatus(ctx, build, updated)
+}
+
+// resolve returns the digest and pull specification of the current image of the trigger, or
+// empty strings if the tag has no image
+func (r *BuildTriggerReconciler) resolve(ctx context.Context, trigger *imagestreams.Trigger) (string, string, error) {
+	imageStream := &unstructured.Unstructured{}
+	imageStream.SetGroupVersionKind(imagestreams.ImageStreamGVK)
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: trigger.Namespace, Name: trigger.ImageStream}, imageStream); err != nil {
+		return "", "", client.IgnoreNotFound(err)
+	}
+	image, reference, _ := imagestreams.ResolveTag(imageStream, trigger.Tag.Tag)
+	return image, reference, nil
+}
+
+// createBuildRun creates the BuildRun triggered by the changed images. The BuildRun name is
+// derived from the images, so that a change already handled does not trigger another BuildRun.
+func (r *BuildTriggerReconciler) createBuildRun(ctx context.Context, build *buildv1beta1.Build, changed []string, params []buildv1beta1.ParamValue) (string, error) {
+	buildRun := &buildv1beta1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   build.Namespace,
+			Name:        imagestreams.BuildRunName(build.Name, changed),
+			Annotations: map[string]string{imagestreams.TriggeredByAnnotation: strings.Join(changed, ",")},
+		},
+		Spec: buildv1beta1.BuildRunSpec{
+			Build:       buildv1beta1.ReferencedBuild{Name: ptr.To(build.Name)},
+			ParamValues: params,
+		},
+	}
+	if err := r.Client.Create(ctx, buildRun); err != nil && !apierrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create BuildRun %s/%s: %v", buildRun.Namespace, buildRun.Name, err)
+	}
+	return buildRun.Name, nil
+}
+
+// updateStatus records the trigger status in the annotation of the Build
+func (r *BuildTriggerReconciler) updateStatus(ctx context.Context, build *buildv1beta1.Build, status []imagestreams.TriggerStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if build.Annotations[imagestreams.TriggersStatusAnnotation] == string(data) {
+		return nil
+	}
+	build.Annotations[imagestreams.TriggersStatusAnnotation] = string(data)
+	return r.Client.Update(ctx, build)
+}
+
+// reconcileWatches watches the annotated Builds and the ImageStreams once their kinds are served
+func (r *BuildTriggerReconciler) reconcileWatches(ctx context.Context) (ctrl.Result, error) {
+	build := &buildv1beta1.Build{}
+	build.SetGroupVersionKind(buildv1beta1.SchemeGroupVersion.WithKind("Build"))
+	annotated := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		_, found := object.GetAnnotations()[imagestreams.TriggersAnnotation]
+		return found
+	})
+	triggersChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetAnnotations()[imagestreams.TriggersAnnotation] != e.ObjectNew.GetAnnotations()[imagestreams.TriggersAnnotation]
+		},
+	}
+	buildsWatched, err := r.watcher.watch(ctx, r.Client.RESTMapper(), build, &handler.EnqueueRequestForObject{}, annotated, triggersChanged)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	imageStream := &unstructured.Unstructured{}
+	imageStream.SetGroupVersionKind(imagestreams.ImageStreamGVK)
+	imageStreamsWatched := false
+	if buildsWatched {
+		imageStreamsWatched, err = r.watcher.watch(ctx, r.Client.RESTMapper(), imageStream, handler.EnqueueRequestsFromMapFunc(r.triggeredBuilds))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if !buildsWatched || !imageStreamsWatched {
+		return ctrl.Result{RequeueAfter: BuildTriggerRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// triggeredBuilds returns the Builds with a trigger on the ImageStream
+func (r *BuildTriggerReconciler) triggeredBuilds(ctx context.Context, imageStream client.Object) []reconcile.Request {
+	builds := &buildv1beta1.BuildList{}
+	if err := r.Client.List(ctx, builds); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list the Builds triggered by the ImageStream")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, build := range builds.Items {
+		value, ok := build.Annotations[imagestreams.TriggersAnnotation]
+		if !ok {
+			continue
+		}
+		triggers, _ := imagestreams.ParseTriggers(build.Namespace, value)
+		if imagestreams.References(triggers, imageStream.GetNamespace(), imageStream.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&build)})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *BuildTriggerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	r.watcher.cache = mgr.GetCache()
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("buildtrigger").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.watcher.controller = c
+	return nil
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("BuildTrigger controller", Label("buildtrigger"), func() {
+	var (
+		ctx         context.Context
+		fakeClient  client.Client
+		reconciler  *BuildTriggerReconciler
+		build       *buildv1beta1.Build
+		imageStream *unstructured.Unstructured
+	)
+
+	setImage := func(image string) {
+		Expect(unstructured.SetNestedSlice(imageStream.Object, []interface{}{
+			map[string]interface{}{"tag": "20", "items": []interface{}{
+				map[string]interface{}{"image": image, "dockerImageReference": "registry.example.com/nodejs@" + image},
+			}},
+		}, "status", "tags")).To(Succeed())
+	}
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(build)})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	buildRuns := func() []buildv1beta1.BuildRun {
+		list := &buildv1beta1.BuildRunList{}
+		Expect(fakeClient.List(ctx, list)).To(Succeed())
+		return list.Items
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		build = &buildv1beta1.Build{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "app",
+				Annotations: map[string]string{imagestreams.TriggersAnnotation: "openshift/nodejs:20=builder-image"},
+			},
+		}
+		imageStream = &unstructured.Unstructured{}
+		imageStream.SetGroupVersionKind(imagestreams.ImageStreamGVK)
+		imageStream.SetNamespace("openshift")
+		imageStream.SetName("nodejs")
+		setImage("sha256:1111")
+
+		scheme := runtime.NewScheme()
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(build, imageStream).Build()
+		reconciler = &BuildTriggerReconciler{Client: fakeClient}
+	})
+
+	It("records the first image without triggering a BuildRun", func() {
+		reconcile()
+		Expect(buildRuns()).To(BeEmpty())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(build), build)).To(Succeed())
+		status := imagestreams.ParseTriggersStatus(build.Annotations[imagestreams.TriggersStatusAnnotation])
+		Expect(status).To(HaveLen(1))
+		Expect(status[0].LastTriggeredImageID).To(Equal("sha256:1111"))
+	})
+
+	It("triggers a single BuildRun when the image changes", func() {
+		reconcile()
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(imageStream), imageStream)).To(Succeed())
+		setImage("sha256:2222")
+		Expect(fakeClient.Update(ctx, imageStream)).To(Succeed())
+
+		reconcile()
+		items := buildRuns()
+		Expect(items).To(HaveLen(1))
+		Expect(*items[0].Spec.Build.Name).To(Equal("app"))
+		Expect(items[0].Annotations).To(HaveKeyWithValue(imagestreams.TriggeredByAnnotation, "openshift/nodejs:20@sha256:2222"))
+		Expect(items[0].Spec.ParamValues).To(HaveLen(1))
+		Expect(*items[0].Spec.ParamValues[0].Value).To(Equal("registry.example.com/nodejs@sha256:2222"))
+
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(build), build)).To(Succeed())
+		status := imagestreams.ParseTriggersStatus(build.Annotations[imagestreams.TriggersStatusAnnotation])
+		Expect(status[0].LastTriggeredImageID).To(Equal("sha256:2222"))
+		Expect(status[0].BuildRun).To(Equal(items[0].Name))
+
+		By("not triggering again for the same image")
+		reconcile()
+		Expect(buildRuns()).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,9 @@
+package controller
+
+// The cluster entitlement is shared as a SharedSecret by the Entitlements component. The operator
+// holds the permissions it grants: reading the entitlement secret for the Shared Resource CSI
+// Driver, and using the SharedSecret for the service accounts of the selected namespaces.
+
+// +kubebuilder:rbac:groups=sharedresource.openshift.io,resources=sharedsecrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=sharedresource.openshift.io,resources=sharedsecrets,resourceNames=openshift-etc-pki-entitlement,verbs=use
+// +kubebuilder:rbac:groups=core,resources=secrets,resourceNames=etc-pki-entitlement,verbs=get;list;watch
@@ -0,0 +1,115 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+//+kubebuilder:rbac:groups=image.openshift.io,resources=imagestreams,verbs=get;list;watch;create;update
+//+kubebuilder:rbac:groups=image.openshift.io,resources=imagestreams/layers,verbs=get
+//+kubebuilder:rbac:groups=shipwright.io,resources=buildruns,verbs=get;list;watch
+
+// ImageStreamRequeueInterval is the interval at which the watch of the BuildRuns is retried
+// while Shipwright is not installed.
+var ImageStreamRequeueInterval = 5 * time.Minute
+
+// ImageStreamReconciler tags the images pushed by the BuildRuns with an ImageStreamTag output
+// into their ImageStream. The OpenShiftBuild instance is reconciled to watch the BuildRuns once
+// Shipwright is installed.
+type ImageStreamReconciler struct {
+	Client client.Client
+
+	watcher kindWatcher
+}
+
+// Reconcile tags the image of a successful BuildRun
+func (r *ImageStreamReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if req.Namespace == "" {
+		return r.reconcileWatch(ctx)
+	}
+	logger := log.FromContext(ctx).WithValues("namespace", req.Namespace, "name", req.Name)
+
+	buildRun := &buildv1beta1.BuildRun{}
+	if err := r.Client.Get(ctx, req.NamespacedName, buildRun); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	value, ok := buildRun.Annotations[imagestreams.OutputAnnotation]
+	if !ok || !buildRun.IsSuccessful() {
+		return ctrl.Result{}, nil
+	}
+	tag, err := imagestreams.ParseTag(buildRun.Namespace, value)
+	if err != nil {
+		logger.Info("Ignoring BuildRun with an invalid ImageStreamTag output", "reason", err.Error())
+		return ctrl.Result{}, nil
+	}
+	changed, err := imagestreams.TagImage(ctx, r.Client, tag, buildRun)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if changed {
+		logger.Info("ImageStreamTag updated", "imagestreamtag", tag.String(), "digest", buildRun.Status.Output.Digest)
+	}
+	return ctrl.Result{}, nil
+}
+
+// reconcileWatch watches the BuildRuns once their kind is served
+func (r *ImageStreamReconciler) reconcileWatch(ctx context.Context) (ctrl.Result, error) {
+	buildRun := &buildv1beta1.BuildRun{}
+	buildRun.SetGroupVersionKind(buildv1beta1.SchemeGroupVersion.WithKind("BuildRun"))
+	completed := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return isImageStreamOutput(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*buildv1beta1.BuildRun)
+			return ok && isImageStreamOutput(e.ObjectNew) && !old.IsSuccessful()
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	ok, err := r.watcher.watch(ctx, r.Client.RESTMapper(), buildRun, &handler.EnqueueRequestForObject{}, completed)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ok {
+		return ctrl.Result{RequeueAfter: ImageStreamRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// isImageStreamOutput returns true for the successful BuildRuns with an ImageStreamTag output
+func isImageStreamOutput(object client.Object) bool {
+	buildRun, ok := object.(*buildv1beta1.BuildRun)
+	if !ok {
+		return false
+	}
+	_, found := buildRun.Annotations[imagestreams.OutputAnnotation]
+	return found && buildRun.IsSuccessful()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ImageStreamReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	r.watcher.cache = mgr.GetCache()
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("imagestream").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.watcher.controller = c
+	return nil
+}
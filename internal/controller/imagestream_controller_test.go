@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ImageStream controller", Label("imagestream"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *ImageStreamReconciler
+		buildRun   *buildv1beta1.BuildRun
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		buildRun = &buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "test",
+				Name:        "app-1",
+				Annotations: map[string]string{imagestreams.OutputAnnotation: "app:v1"},
+			},
+			Status: buildv1beta1.BuildRunStatus{
+				Output:         &buildv1beta1.Output{Digest: "sha256:1111"},
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(buildRun).Build()
+		reconciler = &ImageStreamReconciler{Client: fakeClient}
+	})
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(buildRun)})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	imageStream := func() error {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(imagestreams.ImageStreamGVK)
+		return fakeClient.Get(ctx, types.NamespacedName{Namespace: "test", Name: "app"}, object)
+	}
+
+	When("the BuildRun succeeded", func() {
+		BeforeEach(func() {
+			buildRun.Status.SetCondition(&buildv1beta1.Condition{Type: buildv1beta1.Succeeded, Status: corev1.ConditionTrue})
+		})
+
+		It("tags the image into the ImageStream", func() {
+			reconcile()
+			Expect(imageStream()).To(Succeed())
+		})
+	})
+
+	When("the BuildRun failed", func() {
+		BeforeEach(func() {
+			buildRun.Status.SetCondition(&buildv1beta1.Condition{Type: buildv1beta1.Succeeded, Status: corev1.ConditionFalse})
+		})
+
+		It("leaves the ImageStream untouched", func() {
+			reconcile()
+			Expect(imageStream()).NotTo(Succeed())
+		})
+	})
+})
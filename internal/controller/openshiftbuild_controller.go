@@ -18,14 +18,20 @@ package controller
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
 	rbacv1 "k8s.io/api/rbac/v1"
-	"os"
 
 	"github.com/go-logr/logr"
-	manifestivalclient "github.com/manifestival/controller-runtime-client"
-	"github.com/manifestival/manifestival"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/config"
+	"github.com/redhat-openshift-builds/operator/internal/entitlements"
+	"github.com/redhat-openshift-builds/operator/internal/health"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"github.com/redhat-openshift-builds/operator/internal/namespace"
 	"github.com/redhat-openshift-builds/operator/internal/networkpolicy"
 	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -33,34 +39,80 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
 	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
-	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/scc"
+	shipwrighttriggers "github.com/redhat-openshift-builds/operator/internal/shipwright/triggers"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/variants"
 )
 
 // OpenShiftBuildReconciler reconciles a OpenShiftBuild object
 type OpenShiftBuildReconciler struct {
-	APIReader      client.Reader
-	Client         client.Client
-	Scheme         *apiruntime.Scheme
-	Logger         logr.Logger
-	SharedResource *sharedresource.SharedResource
-	Shipwright     *shipwrightbuild.ShipwrightBuild
-	NetworkPolicy  *networkpolicy.NetworkPolicy
+	APIReader  client.Reader
+	Client     client.Client
+	Scheme     *apiruntime.Scheme
+	Logger     logr.Logger
+	Components *component.Registry
+	Health     *health.Tracker
+}
+
+// DefaultComponents returns the components deployed by the operator out of the box, in the
+// order they are reconciled.
+func DefaultComponents(client client.Client, logger logr.Logger, cfg *config.Config) []component.Component {
+	triggers := &shipwrighttriggers.Triggers{
+		Client:       client,
+		Logger:       logger,
+		ManifestPath: cfg.Manifests.ShipwrightTriggers,
+		Images:       images.New(cfg.Images.Overrides, config.Enabled(cfg.Images.RequireDigests)),
+	}
+	if config.Enabled(cfg.Features.NetworkPolicy) {
+		triggers.NetworkPolicyManifestPath = filepath.Join(cfg.Manifests.NetworkPolicy, "triggers")
+	}
+	components := []component.Component{
+		namespace.New(client, common.OperandNamespaceLabels),
+		shipwrightbuild.NewComponent(client),
+		triggers,
+		&variants.Variants{
+			Client:       client,
+			Logger:       logger,
+			ManifestPath: cfg.Manifests.ShipwrightBuildStrategy,
+			Images:       images.New(cfg.Images.Overrides, config.Enabled(cfg.Images.RequireDigests)),
+		},
+		scc.New(client),
+		&sharedresource.SharedResource{
+			Client:       client,
+			ManifestPath: cfg.Manifests.SharedResource,
+			Images:       images.New(cfg.Images.Overrides, config.Enabled(cfg.Images.RequireDigests)),
+		},
+		entitlements.New(client),
+	}
+	if config.Enabled(cfg.Features.NetworkPolicy) {
+		components = append(components, &networkpolicy.NetworkPolicy{
+			Client:       client,
+			Logger:       logger,
+			ManifestPath: cfg.Manifests.NetworkPolicy,
+		})
+	}
+	return components
 }
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
 	logger := log.FromContext(ctx).WithValues("name", req.Name)
 	logger.Info("Starting reconciliation")
+	r.Health.ReconcileStarted()
+	defer func() { r.Health.ReconcileFinished(err) }()
 
 	// Get OpenShiftBuild resource from cache
 	openShiftBuild := &openshiftv1alpha1.OpenShiftBuild{}
@@ -93,58 +145,74 @@ func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, r.HandleDeletion(ctx, openShiftBuild)
 	}
 
-	// Reconcile Shipwright Build
-	if shipwrightErr := r.ReconcileShipwrightBuild(ctx, openShiftBuild); shipwrightErr != nil {
-		logger.Error(shipwrightErr, "Failed to reconcile ShipwrightBuild")
-		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
-			Type:    openshiftv1alpha1.ConditionReady,
-			Status:  metav1.ConditionFalse,
-			Reason:  "ShipwrightReconcileFailed",
-			Message: fmt.Sprintf("Failed to reconcile ShipwrightBuild: %v", shipwrightErr),
-		})
+	// Objects created before the defaulting webhook was installed may lack component stanzas.
+	// Default them in memory only, leaving the persisted spec to the webhook.
+	openShiftBuild.Default()
 
-		if statusUpdateErr := r.Client.Status().Update(ctx, openShiftBuild); statusUpdateErr != nil {
-			logger.Error(statusUpdateErr, "Failed to update status after ShipwrightReconcileFailed", shipwrightErr)
-		}
+	// Reconcile components in registration order
+	for _, c := range r.Components.Components() {
+		logger.Info("Reconciling component", "component", c.Name())
+		started := time.Now()
+		err := c.Reconcile(ctx, openShiftBuild)
+		r.Health.ComponentReconciled(c.Name(), started, err)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile component", "component", c.Name())
+			apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+				Type:    openshiftv1alpha1.ConditionReady,
+				Status:  metav1.ConditionFalse,
+				Reason:  c.Name() + "ReconcileFailed",
+				Message: fmt.Sprintf("Failed to reconcile %s: %v", c.Name(), err),
+			})
+
+			if statusUpdateErr := r.Client.Status().Update(ctx, openShiftBuild); statusUpdateErr != nil {
+				logger.Error(statusUpdateErr, "Failed to update status after component reconcile failure", "component", c.Name())
+			}
 
-		return ctrl.Result{}, fmt.Errorf("ShipwrightBuild reconciliation failed : %v", shipwrightErr)
+			return ctrl.Result{}, fmt.Errorf("%s reconciliation failed : %v", c.Name(), err)
+		}
 	}
 
-	// Reconcile Shared Resources
-	if sharedResourcesErr := r.ReconcileSharedResource(ctx, openShiftBuild); sharedResourcesErr != nil {
-		logger.Error(sharedResourcesErr, "Failed to reconcile SharedResource")
+	// Remove the operands left in the previous operand namespace
+	operandNamespace := common.OperandNamespace(openShiftBuild)
+	if err := r.relocateOperands(ctx, openShiftBuild, operandNamespace); err != nil {
+		logger.Error(err, "Failed to remove the operands of the previous operand namespace")
 		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
 			Type:    openshiftv1alpha1.ConditionReady,
 			Status:  metav1.ConditionFalse,
-			Reason:  "SharedResourceReconcileFailed",
-			Message: fmt.Sprintf("Failed to reconcile SharedResource: %v", sharedResourcesErr),
+			Reason:  "RelocationFailed",
+			Message: fmt.Sprintf("Failed to remove the operands of the previous operand namespace: %v", err),
 		})
-
 		if statusUpdateErr := r.Client.Status().Update(ctx, openShiftBuild); statusUpdateErr != nil {
-			logger.Error(statusUpdateErr, "Failed to update status after SharedResourceReconcileFailed", sharedResourcesErr)
+			logger.Error(statusUpdateErr, "Failed to update status after relocation failure")
 		}
-
-		return ctrl.Result{}, fmt.Errorf("SharedResources reconciliation failed : %v", sharedResourcesErr)
+		return ctrl.Result{}, err
 	}
 
-	// Reconcile NetworkPolicies
-	if networkPolicyErr := r.ReconcileNetworkPolicy(ctx, openShiftBuild); networkPolicyErr != nil {
-		logger.Error(networkPolicyErr, "Failed to reconcile NetworkPolicy")
+	// Update status
+	degraded := []string{}
+	for _, c := range r.Components.Components() {
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, c.Status(ctx, openShiftBuild))
+		if d, ok := c.(component.Degradable); ok {
+			if message := d.Degraded(ctx, openShiftBuild); message != "" {
+				degraded = append(degraded, fmt.Sprintf("%s: %s", c.Name(), message))
+			}
+		}
+	}
+	if len(degraded) > 0 {
 		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
-			Type:    openshiftv1alpha1.ConditionReady,
+			Type:    openshiftv1alpha1.ConditionDegraded,
+			Status:  metav1.ConditionTrue,
+			Reason:  "ComponentsDegraded",
+			Message: strings.Join(degraded, "; "),
+		})
+	} else {
+		apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
+			Type:    openshiftv1alpha1.ConditionDegraded,
 			Status:  metav1.ConditionFalse,
-			Reason:  "NetworkPolicyReconcileFailed",
-			Message: fmt.Sprintf("Failed to reconcile NetworkPolicy: %v", networkPolicyErr),
+			Reason:  "AsExpected",
+			Message: "No component is degraded",
 		})
-
-		if statusUpdateErr := r.Client.Status().Update(ctx, openShiftBuild); statusUpdateErr != nil {
-			logger.Error(statusUpdateErr, "Failed to update status after NetworkPolicyReconcileFailed", networkPolicyErr)
-		}
-
-		return ctrl.Result{}, fmt.Errorf("NetworkPolicy reconciliation failed : %v", networkPolicyErr)
 	}
-
-	// Update status
 	apimeta.SetStatusCondition(&openShiftBuild.Status.Conditions, metav1.Condition{
 		Type:    openshiftv1alpha1.ConditionReady,
 		Status:  metav1.ConditionTrue,
@@ -156,10 +224,41 @@ func (r *OpenShiftBuildReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	// Record the operand namespace, once the operands of the previous one are removed
+	if openShiftBuild.Annotations[common.OperandNamespaceAnnotation] != operandNamespace {
+		patch := client.MergeFrom(openShiftBuild.DeepCopy())
+		metav1.SetMetaDataAnnotation(&openShiftBuild.ObjectMeta, common.OperandNamespaceAnnotation, operandNamespace)
+		if err := r.Client.Patch(ctx, openShiftBuild, patch); err != nil {
+			logger.Error(err, "Failed to record the operand namespace")
+			return ctrl.Result{}, err
+		}
+	}
+
 	logger.Info("Finished reconciliation")
 	return ctrl.Result{}, nil
 }
 
+// relocateOperands deletes the operands of the relocatable components from the namespace recorded
+// on the owner when it differs from the current operand namespace. The instances reconciled before
+// the namespace was recorded deployed their operands in openshift-builds.
+func (r *OpenShiftBuildReconciler) relocateOperands(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, operandNamespace string) error {
+	previous, ok := owner.Annotations[common.OperandNamespaceAnnotation]
+	if !ok {
+		previous = common.OpenShiftBuildNamespaceName
+	}
+	if previous == "" || previous == operandNamespace {
+		return nil
+	}
+	for _, c := range r.Components.Components() {
+		if rc, ok := c.(component.Relocatable); ok {
+			if err := rc.Relocate(ctx, owner, previous); err != nil {
+				return fmt.Errorf("%s relocation failed: %v", c.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
 // BootstrapOpenShiftBuild creates the default OpenShiftBuild instance ("cluster") if it is not
 // present on the cluster.
 func (r *OpenShiftBuildReconciler) BootstrapOpenShiftBuild(ctx context.Context, client client.Client) error {
@@ -185,108 +284,18 @@ func (r *OpenShiftBuildReconciler) BootstrapOpenShiftBuild(ctx context.Context,
 func (r *OpenShiftBuildReconciler) CreateOrUpdate(ctx context.Context, client client.Client, object *openshiftv1alpha1.OpenShiftBuild) (controllerutil.OperationResult, error) {
 	return ctrl.CreateOrUpdate(ctx, client, object, func() error {
 		controllerutil.AddFinalizer(object, common.OpenShiftBuildFinalizerName)
-		if object.Spec.Shipwright == nil {
-			object.Spec.Shipwright = &openshiftv1alpha1.Shipwright{
-				Build: &openshiftv1alpha1.ShipwrightBuild{
-					State: openshiftv1alpha1.Enabled,
-				},
-			}
-		}
-		if object.Spec.SharedResource == nil {
-			object.Spec.SharedResource = &openshiftv1alpha1.SharedResource{
-				State: openshiftv1alpha1.Enabled,
-			}
-		}
 		return nil
 	})
 }
 
-// ReconcileSharedResource creates and updates SharedResource objects
-func (r *OpenShiftBuildReconciler) ReconcileSharedResource(ctx context.Context, openshiftBuild *openshiftv1alpha1.OpenShiftBuild) error {
-	logger := log.FromContext(ctx).WithValues("name", openshiftBuild.ObjectMeta.Name)
-
-	logger.Info("Reconciling SharedResource...")
-	if err := r.SharedResource.Reconcile(ctx, openshiftBuild); err != nil {
-		logger.Error(err, "Failed reconciling SharedResource...")
-		return err
-	}
-
-	return nil
-}
-
-// ReconcileNetworkPolicy reconciles NetworkPolicy resources
-func (r *OpenShiftBuildReconciler) ReconcileNetworkPolicy(ctx context.Context, openshiftBuild *openshiftv1alpha1.OpenShiftBuild) error {
-	logger := log.FromContext(ctx).WithValues("name", openshiftBuild.ObjectMeta.Name)
-
-	logger.Info("Reconciling NetworkPolicy...")
-	if err := r.NetworkPolicy.Reconcile(ctx, openshiftBuild); err != nil {
-		logger.Error(err, "Failed reconciling NetworkPolicy...")
-		return err
-	}
-
-	return nil
-}
-
-// BootStrapSharedResource initializes the manifestival to apply Shared Resources
-func (r *OpenShiftBuildReconciler) setupSharedResource(mgr ctrl.Manager) error {
-	// Initialize Manifestival
-	manifestivalOptions := []manifestival.Option{
-		manifestival.UseLogger(r.Logger),
-		manifestival.UseClient(manifestivalclient.NewClient(mgr.GetClient())),
-	}
-
-	// Shared Resource manifests
-	sharedManifestPath := common.SharedResourceManifestPath
-	if path, ok := os.LookupEnv(common.SharedResourceManifestPath); ok {
-		sharedManifestPath = path
-	}
-	sharedManifest, err := manifestival.NewManifest(sharedManifestPath, manifestivalOptions...)
-	if err != nil {
-		return err
-	}
-
-	// Initialize Shared Resource
-	r.SharedResource = sharedresource.New(mgr.GetClient(), sharedManifest)
-	return nil
-}
-
-// setupNetworkPolicy initializes the manifestival to apply NetworkPolicy resources
-func (r *OpenShiftBuildReconciler) setupNetworkPolicy(mgr ctrl.Manager) error {
-	// Initialize Manifestival
-	manifestivalOptions := []manifestival.Option{
-		manifestival.UseLogger(r.Logger),
-		manifestival.UseClient(manifestivalclient.NewClient(mgr.GetClient())),
-	}
-
-	// NetworkPolicy manifests
-	networkPolicyManifestPath := common.NetworkPolicyManifestPath
-	if path, ok := os.LookupEnv("NETWORKPOLICY_MANIFEST_PATH"); ok {
-		networkPolicyManifestPath = path
-	}
-	networkPolicyManifest, err := manifestival.NewManifest(networkPolicyManifestPath, manifestivalOptions...)
-	if err != nil {
-		return err
-	}
-
-	// Initialize NetworkPolicy
-	r.NetworkPolicy = networkpolicy.New(mgr.GetClient(), networkPolicyManifest, r.Logger)
-	return nil
-}
-
 // HandleDeletion deletes objects created by the controller
 func (r *OpenShiftBuildReconciler) HandleDeletion(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
 	logger := log.FromContext(ctx).WithValues("name", owner.Name)
-	if err := r.Shipwright.Delete(ctx, owner); err != nil && !apierrors.IsNotFound(err) {
-		logger.Error(err, "Failed to delete Shipwright Build")
-		return err
-	}
-	if err := r.SharedResource.Reconcile(ctx, owner); err != nil {
-		logger.Error(err, "Failed to delete SharedResource")
-		return err
-	}
-	if err := r.NetworkPolicy.Reconcile(ctx, owner); err != nil {
-		logger.Error(err, "Failed to delete NetworkPolicy")
-		return err
+	for _, c := range r.Components.Components() {
+		if err := c.Delete(ctx, owner); err != nil {
+			logger.Error(err, "Failed to delete component", "component", c.Name())
+			return err
+		}
 	}
 	if controllerutil.ContainsFinalizer(owner, common.OpenShiftBuildFinalizerName) {
 		if ok := controllerutil.RemoveFinalizer(owner, common.OpenShiftBuildFinalizerName); ok {
@@ -296,76 +305,53 @@ func (r *OpenShiftBuildReconciler) HandleDeletion(ctx context.Context, owner *op
 	return nil
 }
 
-// ReconcileShipwrightBuild creates or deletes ShipwrightBuild object
-func (r *OpenShiftBuildReconciler) ReconcileShipwrightBuild(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
-	logger := log.FromContext(ctx).WithValues("name", owner.Name)
-
-	updateOwner := false
-	if owner.Spec.Shipwright == nil {
-		owner.Spec.Shipwright = &openshiftv1alpha1.Shipwright{
-			Build: &openshiftv1alpha1.ShipwrightBuild{
-				State: openshiftv1alpha1.Enabled,
-			},
-		}
-		updateOwner = true
-	}
-	if owner.Spec.Shipwright.Build == nil {
-		owner.Spec.Shipwright.Build = &openshiftv1alpha1.ShipwrightBuild{
-			State: openshiftv1alpha1.Enabled,
+// SetupWithManager sets up the controller with the Manager.
+func (r *OpenShiftBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// bootstrap components
+	for _, c := range r.Components.Components() {
+		if err := c.Setup(mgr); err != nil {
+			return fmt.Errorf("failed to set up component %s: %v", c.Name(), err)
 		}
-		updateOwner = true
 	}
 
-	if updateOwner {
-		if err := r.Client.Update(ctx, owner); err != nil {
-			return fmt.Errorf("failed to update OpenShiftBuild with default values: %v", err)
-		}
+	// Reconcile on spec changes of the OpenShiftBuild and of the objects owned by the components
+	changed := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return !e.DeleteStateUnknown
+		},
 	}
-
-	switch owner.Spec.Shipwright.Build.State {
-	case openshiftv1alpha1.Enabled:
-		result, err := r.Shipwright.CreateOrUpdate(ctx, owner)
-		if err != nil {
-			return err
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(changed))
+	for _, c := range r.Components.Components() {
+		for _, object := range c.WatchedTypes() {
+			controllerBuilder = controllerBuilder.Owns(object, builder.WithPredicates(changed))
 		}
-		logger.Info("ShipwrightBuild resource", "result", result)
-	case openshiftv1alpha1.Disabled:
-		if err := r.Shipwright.Delete(ctx, owner); err != nil && !apierrors.IsNotFound(err) {
-			return err
+		if watcher, ok := c.(component.Watcher); ok {
+			for _, watch := range watcher.Watches() {
+				controllerBuilder = controllerBuilder.Watches(watch.Object,
+					handler.EnqueueRequestsFromMapFunc(r.openShiftBuilds), builder.WithPredicates(watch.Predicates...))
+			}
 		}
-		logger.Info("ShipwrightBuild resource", "result", "deleted")
-	default:
-		return errors.New("unknown component state")
 	}
-
-	return nil
+	return controllerBuilder.Complete(r)
 }
 
-// SetupWithManager sets up the controller with the Manager.
-func (r *OpenShiftBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
-
-	// bootstrap Shared Resources
-	if err := r.setupSharedResource(mgr); err != nil {
-		return err
+// openShiftBuilds returns the OpenShiftBuild instances, reconciled when an object watched by a
+// component changes
+func (r *OpenShiftBuildReconciler) openShiftBuilds(ctx context.Context, _ client.Object) []reconcile.Request {
+	list := &openshiftv1alpha1.OpenShiftBuildList{}
+	if err := r.Client.List(ctx, list); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list the OpenShiftBuild instances")
+		return nil
 	}
-
-	// bootstrap NetworkPolicy
-	if err := r.setupNetworkPolicy(mgr); err != nil {
-		return err
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&item)})
 	}
-
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&openshiftv1alpha1.OpenShiftBuild{}).
-		Owns(&shipwrightv1alpha1.ShipwrightBuild{}).
-		WithEventFilter(predicate.Funcs{
-			UpdateFunc: func(e event.UpdateEvent) bool {
-				return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
-			},
-			DeleteFunc: func(e event.DeleteEvent) bool {
-				return !e.DeleteStateUnknown
-			},
-		}).
-		Complete(r)
+	return requests
 }
 
 // CleanupRoleBindings is a temporary method to clean up the redundant role binding created from incorrect configuration.
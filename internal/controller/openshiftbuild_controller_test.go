@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"time"
 
 	manifestivalclient "github.com/manifestival/controller-runtime-client"
@@ -32,11 +33,17 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/config"
+	"github.com/redhat-openshift-builds/operator/internal/networkpolicy"
 	"github.com/redhat-openshift-builds/operator/internal/sharedresource"
 	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/testutil"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -158,7 +165,7 @@ var _ = Describe("OpenShiftBuild controller", Label("integration", "openshiftbui
 
 var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func() {
 	const (
-		CRName      = "test-shipwright-build"
+		CRName      = common.OpenShiftBuildResourceName
 		CRNamespace = "openshift-builds"
 		timeout     = time.Second * 10
 		interval    = time.Millisecond * 250
@@ -168,17 +175,30 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 
 	var openShiftBuildReconciler *OpenShiftBuildReconciler
 
+	// OpenShiftBuild is a singleton, wait for any previous instance to be finalized
+	waitForDeletion := func() {
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, types.NamespacedName{Name: CRName}, &operatorv1alpha1.OpenShiftBuild{})
+			return errors.IsNotFound(err)
+		}, timeout, interval).Should(BeTrue())
+	}
+
 	Context("When ShipwrightBuild sub-component reconciliation fails", func() {
 		It("Should requeue the main operator reconciliation", func() {
 			By("Creating an OpenShiftBuild instance with invalid Shipwright Build state")
 
+			components, err := component.NewRegistry(
+				shipwrightbuild.NewComponent(k8sClient),
+				&sharedresource.SharedResource{},
+			)
+			Expect(err).NotTo(HaveOccurred())
+
 			openShiftBuildReconciler = &OpenShiftBuildReconciler{
-				Client:         k8sClient,
-				Scheme:         testEnv.Scheme,
-				Logger:         ctrl.Log.WithName("test-openshiftbuild-reconciler"),
-				APIReader:      k8sClient,
-				SharedResource: &sharedresource.SharedResource{},
-				Shipwright:     shipwrightbuild.New(k8sClient, CRNamespace),
+				Client:     k8sClient,
+				Scheme:     testEnv.Scheme,
+				Logger:     ctrl.Log.WithName("test-openshiftbuild-reconciler"),
+				APIReader:  k8sClient,
+				Components: components,
 			}
 
 			cr := &operatorv1alpha1.OpenShiftBuild{
@@ -205,6 +225,7 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 				},
 			}
 
+			waitForDeletion()
 			Expect(k8sClient.Create(ctx, cr)).Should(Succeed())
 
 			// Ensure the CR is retrievable before reconciling, so the Get in Reconcile works
@@ -230,14 +251,15 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 			// Cleanup
 			By("Deleting the CR for SharedResource failure test")
 			Expect(k8sClient.Delete(ctx, cr)).Should(Succeed())
+			waitForDeletion()
 		})
 	})
 
 	Context("When SharedResource sub-component reconciliation fails", func() {
 		It("Should requeue the main operator reconciliation", func() {
 			By("Creating an OpenShiftBuild instance configured to make SharedResource reconciler fail")
-			sharedManifestPath := common.SharedResourceManifestPath
-			if path, ok := os.LookupEnv(common.SharedResourceManifestPath); ok {
+			sharedManifestPath := filepath.Join("..", "..", "config", "sharedresource")
+			if path, ok := os.LookupEnv(config.SharedResourceManifestPathEnv); ok {
 				sharedManifestPath = path
 			}
 			sharedManifest, err := manifestival.NewManifest(sharedManifestPath, []manifestival.Option{
@@ -248,13 +270,18 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 				fmt.Println("Error creating shared manifest", err)
 			}
 
+			components, err := component.NewRegistry(
+				shipwrightbuild.NewComponent(k8sClient),
+				sharedresource.New(k8sClient, sharedManifest),
+			)
+			Expect(err).NotTo(HaveOccurred())
+
 			openShiftBuildReconciler = &OpenShiftBuildReconciler{
-				Client:         k8sClient,
-				Scheme:         testEnv.Scheme,
-				Logger:         ctrl.Log.WithName("test-openshiftbuild-reconciler"),
-				APIReader:      k8sClient,
-				SharedResource: sharedresource.New(k8sClient, sharedManifest),
-				Shipwright:     shipwrightbuild.New(k8sClient, CRNamespace),
+				Client:     k8sClient,
+				Scheme:     testEnv.Scheme,
+				Logger:     ctrl.Log.WithName("test-openshiftbuild-reconciler"),
+				APIReader:  k8sClient,
+				Components: components,
 			}
 
 			cr := &operatorv1alpha1.OpenShiftBuild{
@@ -281,6 +308,7 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 				},
 			}
 
+			waitForDeletion()
 			Expect(k8sClient.Create(ctx, cr)).Should(Succeed())
 
 			crKey := types.NamespacedName{Name: CRName, Namespace: CRNamespace}
@@ -298,7 +326,7 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 			By("Asserting that an error was returned (triggering requeue)")
 			Expect(err).To(HaveOccurred(), "Main Reconcile should return an error when SharedResource sub-component fails")
 
-			Expect(err.Error()).To(ContainSubstring("SharedResources reconciliation failed"))
+			Expect(err.Error()).To(ContainSubstring("SharedResource reconciliation failed"))
 
 			By("Asserting that the result does not ask for RequeueAfter (default requeue-on-error)")
 			Expect(result.Requeue).To(BeFalse(), "Result.Requeue should be false when an error is returned for default backoff requeue")
@@ -307,6 +335,88 @@ var _ = Describe("Main Operator Controller with Sub-Reconciler Failure", func()
 			// Cleanup
 			By("Deleting the CR for SharedResource failure test")
 			Expect(k8sClient.Delete(ctx, cr)).Should(Succeed())
+			waitForDeletion()
 		})
 	})
 })
+
+var _ = Describe("OpenShiftBuild operand namespace changes", Label("openshiftbuild", "relocation"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *OpenShiftBuildReconciler
+		owner      *operatorv1alpha1.OpenShiftBuild
+	)
+
+	networkPolicies := func(namespace string) []networkingv1.NetworkPolicy {
+		list := &networkingv1.NetworkPolicyList{}
+		Expect(fakeClient.List(ctx, list, client.InNamespace(namespace))).To(Succeed())
+		return list.Items
+	}
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: owner.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(owner), owner)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		owner = testutil.NewOwner()
+		fakeClient = fake.NewClientBuilder().
+			WithScheme(testutil.NewScheme()).
+			WithObjects(owner).
+			WithStatusSubresource(&operatorv1alpha1.OpenShiftBuild{}).
+			Build()
+
+		policy := unstructured.Unstructured{}
+		policy.SetAPIVersion("networking.k8s.io/v1")
+		policy.SetKind("NetworkPolicy")
+		policy.SetName("default-deny-ingress")
+		manifest, err := manifestival.ManifestFrom(
+			manifestival.Slice([]unstructured.Unstructured{policy}),
+			manifestival.UseClient(manifestivalclient.NewClient(fakeClient)),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		components, err := component.NewRegistry(networkpolicy.New(fakeClient, manifest, ctrl.Log.WithName("test")))
+		Expect(err).NotTo(HaveOccurred())
+		reconciler = &OpenShiftBuildReconciler{
+			Client:     fakeClient,
+			Scheme:     fakeClient.Scheme(),
+			Logger:     ctrl.Log.WithName("test-openshiftbuild-reconciler"),
+			APIReader:  fakeClient,
+			Components: components,
+		}
+	})
+
+	It("records the operand namespace", func() {
+		reconcile()
+		Expect(owner.Annotations).To(HaveKeyWithValue(common.OperandNamespaceAnnotation, common.OpenShiftBuildNamespaceName))
+		Expect(networkPolicies(common.OpenShiftBuildNamespaceName)).To(HaveLen(1))
+	})
+
+	It("deletes the operands of the previous namespace", func() {
+		reconcile()
+
+		owner.Spec.Namespace = "builds"
+		Expect(fakeClient.Update(ctx, owner)).To(Succeed())
+		reconcile()
+
+		Expect(owner.Annotations).To(HaveKeyWithValue(common.OperandNamespaceAnnotation, "builds"))
+		Expect(networkPolicies("builds")).To(HaveLen(1))
+		Expect(networkPolicies(common.OpenShiftBuildNamespaceName)).To(BeEmpty())
+	})
+
+	It("deletes the operands deployed in openshift-builds before the namespace was recorded", func() {
+		Expect(reconciler.Components.Get(networkpolicy.ComponentName).Reconcile(ctx, owner)).To(Succeed())
+		Expect(networkPolicies(common.OpenShiftBuildNamespaceName)).To(HaveLen(1))
+
+		owner.Spec.Namespace = "builds"
+		Expect(fakeClient.Update(ctx, owner)).To(Succeed())
+		reconcile()
+
+		Expect(networkPolicies("builds")).To(HaveLen(1))
+		Expect(networkPolicies(common.OpenShiftBuildNamespaceName)).To(BeEmpty())
+	})
+})
@@ -0,0 +1,210 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/pruning"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=shipwright.io,resources=buildruns,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+
+// PruningRequeueInterval is the interval at which the watch of the BuildRuns is retried while
+// Shipwright is not installed.
+var PruningRequeueInterval = 5 * time.Minute
+
+// PruningReconciler deletes the completed BuildRuns exceeding the retention policy of their
+// namespace, with their TaskRuns and pods. The OpenShiftBuild instance is reconciled to watch the
+// BuildRuns once Shipwright is installed and to prune all namespaces, and the BuildRun and
+// namespace events are reconciled as a request for their namespace, without a name.
+type PruningReconciler struct {
+	Client client.Client
+
+	watcher kindWatcher
+}
+
+// Reconcile prunes the BuildRuns of the requested namespace, or of all namespaces
+func (r *PruningReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	openShiftBuild := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: common.OpenShiftBuildResourceName}, openShiftBuild); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if req.Namespace == "" {
+		return r.reconcileCluster(ctx, openShiftBuild)
+	}
+	if !pruning.Enabled(openShiftBuild) {
+		return ctrl.Result{}, nil
+	}
+	next, err := r.prune(ctx, openShiftBuild, req.Namespace)
+	return ctrl.Result{RequeueAfter: next}, err
+}
+
+// reconcileCluster watches the BuildRuns once their kind is served, and prunes the BuildRuns of
+// all namespaces
+func (r *PruningReconciler) reconcileCluster(ctx context.Context, openShiftBuild *openshiftv1alpha1.OpenShiftBuild) (ctrl.Result, error) {
+	buildRun := &buildv1beta1.BuildRun{}
+	buildRun.SetGroupVersionKind(buildv1beta1.SchemeGroupVersion.WithKind("BuildRun"))
+	completed := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool { return isCompleted(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*buildv1beta1.BuildRun)
+			return ok && isCompleted(e.ObjectNew) && !old.IsDone()
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	watched, err := r.watcher.watch(ctx, r.Client.RESTMapper(), buildRun, handler.EnqueueRequestsFromMapFunc(namespaceRequest), completed)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !watched {
+		return ctrl.Result{RequeueAfter: PruningRequeueInterval}, nil
+	}
+	if !pruning.Enabled(openShiftBuild) {
+		return ctrl.Result{}, nil
+	}
+
+	buildRuns := &buildv1beta1.BuildRunList{}
+	if err := r.Client.List(ctx, buildRuns); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list the BuildRuns: %v", err)
+	}
+	namespaces := map[string]bool{}
+	for _, buildRun := range buildRuns.Items {
+		namespaces[buildRun.Namespace] = true
+	}
+	result := ctrl.Result{}
+	for namespace := range namespaces {
+		next, err := r.prune(ctx, openShiftBuild, namespace)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if next > 0 && (result.RequeueAfter == 0 || next < result.RequeueAfter) {
+			result.RequeueAfter = next
+		}
+	}
+	return result, nil
+}
+
+// prune deletes the completed BuildRuns of the namespace exceeding its retention policy, and
+// returns the duration until the next completed BuildRun expires
+func (r *PruningReconciler) prune(ctx context.Context, openShiftBuild *openshiftv1alpha1.OpenShiftBuild, name string) (time.Duration, error) {
+	logger := log.FromContext(ctx).WithValues("namespace", name)
+
+	namespace := &corev1.Namespace{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Name: name}, namespace); err != nil {
+		return 0, client.IgnoreNotFound(err)
+	}
+	if !namespace.DeletionTimestamp.IsZero() {
+		return 0, nil
+	}
+	policy, err := pruning.PolicyFor(openShiftBuild, namespace)
+	if err != nil {
+		logger.Info("Ignoring the invalid retention annotations", "reason", err.Error())
+	}
+	if policy.Empty() {
+		return 0, nil
+	}
+
+	buildRuns := &buildv1beta1.BuildRunList{}
+	if err := r.Client.List(ctx, buildRuns, client.InNamespace(name)); err != nil {
+		return 0, fmt.Errorf("failed to list the BuildRuns of namespace %s: %v", name, err)
+	}
+	builds := &buildv1beta1.BuildList{}
+	if err := r.Client.List(ctx, builds, client.InNamespace(name)); err != nil {
+		return 0, fmt.Errorf("failed to list the Builds of namespace %s: %v", name, err)
+	}
+	retained := map[string]bool{}
+	for _, build := range builds.Items {
+		retained[build.Name] = build.Spec.Retention != nil
+	}
+	// Shipwright enforces the retention set by the BuildRuns and their Builds
+	retains := func(buildRun *buildv1beta1.BuildRun) bool {
+		if buildRun.Spec.Retention != nil {
+			return true
+		}
+		if buildRun.Spec.Build.Spec != nil {
+			return buildRun.Spec.Build.Spec.Retention != nil
+		}
+		return retained[buildRun.Spec.BuildName()]
+	}
+
+	pruned, next := policy.Prune(buildRuns.Items, retains, time.Now())
+	for _, p := range pruned {
+		err := r.Client.Delete(ctx, p.BuildRun, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to delete BuildRun %s/%s: %v", name, p.BuildRun.Name, err)
+		}
+		pruning.PrunedBuildRuns.WithLabelValues(name, p.Status(), p.Reason).Inc()
+		logger.Info("BuildRun pruned", "buildrun", p.BuildRun.Name, "status", p.Status(), "reason", p.Reason)
+	}
+	return next, nil
+}
+
+// isCompleted returns true for the completed BuildRuns
+func isCompleted(object client.Object) bool {
+	buildRun, ok := object.(*buildv1beta1.BuildRun)
+	return ok && buildRun.IsDone()
+}
+
+// namespaceRequest returns the request pruning the namespace of the object
+func namespaceRequest(_ context.Context, object client.Object) []reconcile.Request {
+	name := object.GetNamespace()
+	if name == "" {
+		name = object.GetName()
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: name}}}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PruningReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	r.watcher.cache = mgr.GetCache()
+
+	// Namespaces override the retention policy with annotations
+	annotationsChanged := predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			for _, annotation := range pruning.Annotations {
+				if e.ObjectOld.GetAnnotations()[annotation] != e.ObjectNew.GetAnnotations()[annotation] {
+					return true
+				}
+			}
+			return false
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("pruning").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(namespaceRequest), builder.WithPredicates(annotationsChanged)).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.watcher.controller = c
+	return nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/pruning"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Pruning controller", Label("pruning"), func() {
+	var (
+		ctx            context.Context
+		fakeClient     client.Client
+		reconciler     *PruningReconciler
+		openShiftBuild *openshiftv1alpha1.OpenShiftBuild
+		namespace      *corev1.Namespace
+		objects        []client.Object
+	)
+
+	buildRun := func(name, build string, age time.Duration) *buildv1beta1.BuildRun {
+		buildRun := &buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: name},
+			Spec:       buildv1beta1.BuildRunSpec{Build: buildv1beta1.ReferencedBuild{Name: ptr.To(build)}},
+			Status:     buildv1beta1.BuildRunStatus{CompletionTime: &metav1.Time{Time: time.Now().Add(-age)}},
+		}
+		buildRun.Status.SetCondition(&buildv1beta1.Condition{Type: buildv1beta1.Succeeded, Status: corev1.ConditionTrue})
+		return buildRun
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: "team"}})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	remaining := func() []string {
+		buildRuns := &buildv1beta1.BuildRunList{}
+		Expect(fakeClient.List(ctx, buildRuns, client.InNamespace("team"))).To(Succeed())
+		names := []string{}
+		for _, buildRun := range buildRuns.Items {
+			names = append(names, buildRun.Name)
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		openShiftBuild = &openshiftv1alpha1.OpenShiftBuild{ObjectMeta: metav1.ObjectMeta{Name: common.OpenShiftBuildResourceName}}
+		openShiftBuild.Default()
+		openShiftBuild.Spec.Shipwright.Pruning = &openshiftv1alpha1.ShipwrightPruning{
+			State:             openshiftv1alpha1.Enabled,
+			SucceededLimit:    ptr.To[int32](1),
+			TTLAfterSucceeded: &metav1.Duration{Duration: 24 * time.Hour},
+		}
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team"}}
+		objects = []client.Object{
+			namespace,
+			&buildv1beta1.Build{ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: "app"}},
+			buildRun("app-1", "app", 3*time.Hour),
+			buildRun("app-2", "app", time.Hour),
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objects, openShiftBuild)...).Build()
+		reconciler = &PruningReconciler{Client: fakeClient}
+	})
+
+	It("prunes the BuildRuns exceeding the cluster policy", func() {
+		result := reconcile()
+		Expect(remaining()).To(ConsistOf("app-2"))
+		Expect(result.RequeueAfter).To(BeNumerically("~", 23*time.Hour, time.Minute))
+	})
+
+	When("pruning is disabled", func() {
+		BeforeEach(func() {
+			openShiftBuild.Spec.Shipwright.Pruning.State = openshiftv1alpha1.Disabled
+		})
+
+		It("keeps the BuildRuns", func() {
+			reconcile()
+			Expect(remaining()).To(ConsistOf("app-1", "app-2"))
+		})
+	})
+
+	When("the namespace overrides the policy", func() {
+		BeforeEach(func() {
+			namespace.Annotations = map[string]string{pruning.SucceededLimitAnnotation: "5"}
+		})
+
+		It("applies the namespace policy", func() {
+			reconcile()
+			Expect(remaining()).To(ConsistOf("app-1", "app-2"))
+		})
+	})
+
+	When("the Build sets a retention", func() {
+		BeforeEach(func() {
+			objects[1].(*buildv1beta1.Build).Spec.Retention = &buildv1beta1.BuildRetention{SucceededLimit: ptr.To[uint](5)}
+		})
+
+		It("leaves its BuildRuns to Shipwright", func() {
+			reconcile()
+			Expect(remaining()).To(ConsistOf("app-1", "app-2"))
+		})
+	})
+})
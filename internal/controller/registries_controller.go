@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/registries"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=imagedigestmirrorsets;imagetagmirrorsets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds,verbs=get;list;watch
+
+// RegistriesRequeueInterval is the interval at which the registries configuration is
+// reconciled while the mirror sets or the Shipwright Build API are not available.
+var RegistriesRequeueInterval = 5 * time.Minute
+
+// BuildGVK is the kind of the Shipwright Builds, whose namespaces receive a copy of the
+// registries configuration.
+var BuildGVK = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "Build"}
+
+// RegistriesReconciler renders the cluster image mirror sets into a registries.conf ConfigMap,
+// in the operand namespace and in every namespace holding Shipwright Builds.
+type RegistriesReconciler struct {
+	// Client writes the ConfigMaps and reads the watched kinds from the cache.
+	Client client.Client
+
+	// APIReader reads the ConfigMaps, which are not cached.
+	APIReader client.Reader
+
+	watcher kindWatcher
+}
+
+// Reconcile renders the registries configuration and applies it to the build namespaces
+func (r *RegistriesReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.Name)
+
+	owner := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := r.Client.Get(ctx, req.NamespacedName, owner); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !owner.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	available := true
+	objects := map[schema.GroupVersionKind][]unstructured.Unstructured{}
+	for _, gvk := range []schema.GroupVersionKind{registries.ImageDigestMirrorSetGVK, registries.ImageTagMirrorSetGVK} {
+		ok, err := r.watch(ctx, gvk)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !ok {
+			available = false
+			continue
+		}
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := r.Client.List(ctx, list); err != nil {
+			return ctrl.Result{}, err
+		}
+		objects[gvk] = list.Items
+	}
+	content, err := registries.Render(objects[registries.ImageDigestMirrorSetGVK], objects[registries.ImageTagMirrorSetGVK])
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	namespaces := map[string]bool{common.OperandNamespace(owner): true}
+	ok, err := r.watch(ctx, BuildGVK)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if ok {
+		builds := &metav1.PartialObjectMetadataList{}
+		builds.SetGroupVersionKind(BuildGVK.GroupVersion().WithKind(BuildGVK.Kind + "List"))
+		if err := r.Client.List(ctx, builds); err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, build := range builds.Items {
+			namespaces[build.Namespace] = true
+		}
+	} else {
+		available = false
+	}
+
+	for namespace := range namespaces {
+		if err := r.apply(ctx, owner, namespace, content); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.prune(ctx, namespaces); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("Registries configuration reconciled", "namespaces", len(namespaces))
+
+	if !available {
+		return ctrl.Result{RequeueAfter: RegistriesRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// apply creates or updates the registries ConfigMap in the namespace. Namespaces being
+// terminated are skipped.
+func (r *RegistriesReconciler) apply(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, namespace, content string) error {
+	object := &corev1.ConfigMap{}
+	err := r.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: registries.ConfigMapName}, object)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	found := err == nil
+	if !found {
+		object = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: registries.ConfigMapName},
+		}
+	}
+	desired := object.DeepCopy()
+	labels := desired.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, registries.Labels)
+	desired.SetLabels(labels)
+	desired.Data = map[string]string{registries.ConfigMapKey: content}
+	if err := controllerutil.SetControllerReference(owner, desired, r.Client.Scheme()); err != nil {
+		return err
+	}
+
+	switch {
+	case !found:
+		err = r.Client.Create(ctx, desired)
+	case !equality.Semantic.DeepEqual(object, desired):
+		err = r.Client.Update(ctx, desired)
+	}
+	if apierrors.HasStatusCause(err, corev1.NamespaceTerminatingCause) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ConfigMap %s/%s: %v", namespace, registries.ConfigMapName, err)
+	}
+	return nil
+}
+
+// prune deletes the registries ConfigMaps from the namespaces which no longer hold Builds
+func (r *RegistriesReconciler) prune(ctx context.Context, namespaces map[string]bool) error {
+	list := &corev1.ConfigMapList{}
+	if err := r.APIReader.List(ctx, list, client.MatchingLabels(registries.Labels)); err != nil {
+		return err
+	}
+	for i := range list.Items {
+		object := &list.Items[i]
+		if object.Name != registries.ConfigMapName || namespaces[object.Namespace] {
+			continue
+		}
+		if err := r.Client.Delete(ctx, object); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watch starts watching the given kind if it is served by the cluster, and returns whether it
+// is watched
+func (r *RegistriesReconciler) watch(ctx context.Context, gvk schema.GroupVersionKind) (bool, error) {
+	var object client.Object
+	predicates := []predicate.Predicate{}
+	if gvk == BuildGVK {
+		// Only the namespaces of the Builds matter
+		build := &metav1.PartialObjectMetadata{}
+		build.SetGroupVersionKind(gvk)
+		object = build
+		predicates = append(predicates, predicate.Funcs{
+			UpdateFunc: func(e event.UpdateEvent) bool { return false },
+		})
+	} else {
+		set := &unstructured.Unstructured{}
+		set.SetGroupVersionKind(gvk)
+		object = set
+		predicates = append(predicates, predicate.GenerationChangedPredicate{})
+	}
+	return r.watcher.watch(ctx, r.Client.RESTMapper(), object, handler.EnqueueRequestsFromMapFunc(enqueueOpenShiftBuild), predicates...)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RegistriesReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+	r.watcher.cache = mgr.GetCache()
+
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		Named("registries").
+		For(&openshiftv1alpha1.OpenShiftBuild{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.watcher.controller = c
+	return nil
+}
+
+func enqueueOpenShiftBuild(context.Context, client.Object) []reconcile.Request {
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: common.OpenShiftBuildResourceName}}}
+}
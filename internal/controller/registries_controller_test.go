@@ -0,0 +1,56 @@
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/registries"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Registries controller", Label("integration", "registries"), Serial, func() {
+
+	When("an OpenShiftBuild is created", func() {
+
+		var openshiftBuild *operatorv1alpha1.OpenShiftBuild
+
+		BeforeEach(func(ctx SpecContext) {
+			openshiftBuild = &operatorv1alpha1.OpenShiftBuild{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: common.OpenShiftBuildResourceName,
+				},
+			}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(openshiftBuild), openshiftBuild)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+			if errors.IsNotFound(err) {
+				Expect(k8sClient.Create(ctx, openshiftBuild)).To(Succeed())
+			}
+		})
+
+		AfterEach(func(ctx SpecContext) {
+			Expect(k8sClient.Delete(ctx, openshiftBuild)).To(Succeed())
+			Eventually(func() bool {
+				return errors.IsNotFound(k8sClient.Get(ctx, client.ObjectKeyFromObject(openshiftBuild), &operatorv1alpha1.OpenShiftBuild{}))
+			}).Should(BeTrue())
+		})
+
+		It("generates the registries configuration in the operand namespace", func(ctx SpecContext) {
+			configMap := &corev1.ConfigMap{}
+			Eventually(func() error {
+				return k8sClient.Get(ctx, types.NamespacedName{
+					Namespace: common.OpenShiftBuildNamespaceName,
+					Name:      registries.ConfigMapName,
+				}, configMap)
+			}).Should(Succeed())
+			Expect(configMap.Labels).To(HaveKeyWithValue("app.kubernetes.io/component", "registries"))
+			Expect(configMap.Data).To(HaveKeyWithValue(registries.ConfigMapKey, HavePrefix("# Generated by the OpenShift Builds operator")))
+			Expect(metav1.IsControlledBy(configMap, openshiftBuild)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,9 @@
+package controller
+
+// The SecurityContextConstraints of the build strategies are created by the
+// SecurityContextConstraints component. The operator holds the permission to use them, which it
+// grants to the build service accounts of the namespaces opting in through their
+// BuildNamespaceConfig.
+
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=openshift-builds-buildah;openshift-builds-buildpacks;openshift-builds-source-to-image,verbs=use
@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=sharedresourcegrants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=sharedresourcegrants/status,verbs=get;update;patch
+
+// SharedResourceGrantLabel is set on the RoleBindings materializing the SharedResourceGrants
+const SharedResourceGrantLabel = "operator.openshift.io/shared-resource-grant"
+
+// SharedResourceGrantReconciler materializes the SharedResourceGrants as a ClusterRole allowing
+// the use of the shared resources, bound in each selected namespace. The ClusterRole and
+// RoleBindings are controlled by the SharedResourceGrant, and removed with it.
+type SharedResourceGrantReconciler struct {
+	Client client.Client
+}
+
+// Reconcile binds the use of the shared resources to the grantees, and prunes the RoleBindings
+// of the namespaces without grantees
+func (r *SharedResourceGrantReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.Name)
+
+	grant := &openshiftv1alpha1.SharedResourceGrant{}
+	if err := r.Client.Get(ctx, req.NamespacedName, grant); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !grant.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	grantees, err := r.reconcileGrant(ctx, grant)
+	condition := metav1.Condition{
+		Type:               openshiftv1alpha1.ConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Granted",
+		Message:            fmt.Sprintf("Shared resources are granted to %d grantees", len(grantees)),
+		ObservedGeneration: grant.Generation,
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReconcileFailed"
+		condition.Message = err.Error()
+	} else {
+		grant.Status.Grantees = grantees
+	}
+	apimeta.SetStatusCondition(&grant.Status.Conditions, condition)
+	if statusErr := r.Client.Status().Update(ctx, grant); statusErr != nil {
+		logger.Error(statusErr, "Failed to update status")
+		if err == nil {
+			err = statusErr
+		}
+	}
+	return ctrl.Result{}, err
+}
+
+// reconcileGrant creates the ClusterRole and RoleBindings of the grant, and returns the grantees
+func (r *SharedResourceGrantReconciler) reconcileGrant(ctx context.Context, grant *openshiftv1alpha1.SharedResourceGrant) ([]string, error) {
+	name := sharedResourceGrantRoleName(grant)
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	_, err := ctrl.CreateOrUpdate(ctx, r.Client, role, func() error {
+		role.Rules = sharedResourceGrantRules(grant)
+		return ctrl.SetControllerReference(grant, role, r.Client.Scheme())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile ClusterRole %s: %v", name, err)
+	}
+
+	namespaceSelector, err := metav1.LabelSelectorAsSelector(grant.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %v", err)
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := r.Client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: namespaceSelector}); err != nil {
+		return nil, fmt.Errorf("failed to list the namespaces of the grantees: %v", err)
+	}
+
+	grantees := []string{}
+	granted := map[string]bool{}
+	for _, namespace := range namespaces.Items {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		subjects, err := r.subjects(ctx, grant, namespace.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(subjects) == 0 {
+			continue
+		}
+		binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: name}}
+		_, err = ctrl.CreateOrUpdate(ctx, r.Client, binding, func() error {
+			binding.Labels = labels.Merge(binding.Labels, map[string]string{SharedResourceGrantLabel: "true"})
+			binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: name}
+			binding.Subjects = subjects
+			return ctrl.SetControllerReference(grant, binding, r.Client.Scheme())
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile RoleBinding %s/%s: %v", namespace.Name, name, err)
+		}
+		granted[namespace.Name] = true
+		for _, subject := range subjects {
+			grantees = append(grantees, granteeName(subject))
+		}
+	}
+
+	if err := r.prune(ctx, grant, granted); err != nil {
+		return nil, err
+	}
+	slices.Sort(grantees)
+	return grantees, nil
+}
+
+// subjects returns the grantees of the namespace: all its service accounts unless the grant
+// selects them, or else the selected service accounts
+func (r *SharedResourceGrantReconciler) subjects(ctx context.Context, grant *openshiftv1alpha1.SharedResourceGrant, namespace string) ([]rbacv1.Subject, error) {
+	if grant.Spec.ServiceAccountSelector == nil && len(grant.Spec.ServiceAccountNames) == 0 {
+		return []rbacv1.Subject{{
+			APIGroup: rbacv1.GroupName,
+			Kind:     rbacv1.GroupKind,
+			Name:     "system:serviceaccounts:" + namespace,
+		}}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(grant.Spec.ServiceAccountSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account selector: %v", err)
+	}
+	if grant.Spec.ServiceAccountSelector == nil {
+		selector = labels.Everything()
+	}
+	serviceAccounts := &corev1.ServiceAccountList{}
+	if err := r.Client.List(ctx, serviceAccounts, client.InNamespace(namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, fmt.Errorf("failed to list the service accounts of namespace %s: %v", namespace, err)
+	}
+	subjects := []rbacv1.Subject{}
+	for _, sa := range serviceAccounts.Items {
+		if len(grant.Spec.ServiceAccountNames) > 0 && !slices.Contains(grant.Spec.ServiceAccountNames, sa.Name) {
+			continue
+		}
+		subjects = append(subjects, rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: sa.Name})
+	}
+	return subjects, nil
+}
+
+// prune deletes the RoleBindings of the grant in the namespaces without grantees
+func (r *SharedResourceGrantReconciler) prune(ctx context.Context, grant *openshiftv1alpha1.SharedResourceGrant, granted map[string]bool) error {
+	bindings := &rbacv1.RoleBindingList{}
+	if err := r.Client.List(ctx, bindings, client.HasLabels{SharedResourceGrantLabel}); err != nil {
+		return fmt.Errorf("failed to list the RoleBindings of the grant: %v", err)
+	}
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if granted[binding.Namespace] || !metav1.IsControlledBy(binding, grant) {
+			continue
+		}
+		if err := r.Client.Delete(ctx, binding); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete RoleBinding %s/%s: %v", binding.Namespace, binding.Name, err)
+		}
+		log.FromContext(ctx).Info("Shared resources no longer granted to namespace", "name", grant.Name, "namespace", binding.Namespace)
+	}
+	return nil
+}
+
+// sharedResourceGrants returns all the SharedResourceGrants, as the namespaces and service
+// accounts they select may have changed
+func (r *SharedResourceGrantReconciler) sharedResourceGrants(ctx context.Context, _ client.Object) []reconcile.Request {
+	grants := &openshiftv1alpha1.SharedResourceGrantList{}
+	if err := r.Client.List(ctx, grants); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list the SharedResourceGrants")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, grant := range grants.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&grant)})
+	}
+	return requests
+}
+
+// sharedResourceGrantRoleName returns the name of the ClusterRole and RoleBindings of the grant
+func sharedResourceGrantRoleName(grant *openshiftv1alpha1.SharedResourceGrant) string {
+	return "shared-resource-grant-" + grant.Name
+}
+
+// sharedResourceGrantRules returns the rules allowing the use of the granted shared resources
+func sharedResourceGrantRules(grant *openshiftv1alpha1.SharedResourceGrant) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{}
+	if len(grant.Spec.SharedSecrets) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"sharedresource.openshift.io"},
+			Resources:     []string{"sharedsecrets"},
+			ResourceNames: grant.Spec.SharedSecrets,
+			Verbs:         []string{"use"},
+		})
+	}
+	if len(grant.Spec.SharedConfigMaps) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{"sharedresource.openshift.io"},
+			Resources:     []string{"sharedconfigmaps"},
+			ResourceNames: grant.Spec.SharedConfigMaps,
+			Verbs:         []string{"use"},
+		})
+	}
+	return rules
+}
+
+// granteeName returns the user or group name of the subject
+func granteeName(subject rbacv1.Subject) string {
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		return "system:serviceaccount:" + subject.Namespace + ":" + subject.Name
+	}
+	return subject.Name
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SharedResourceGrantReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Client == nil {
+		r.Client = mgr.GetClient()
+	}
+	// Namespaces and service accounts are selected by their labels
+	labelsChanged := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !labels.Equals(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&openshiftv1alpha1.SharedResourceGrant{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Owns(&rbacv1.ClusterRole{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.sharedResourceGrants), builder.WithPredicates(labelsChanged)).
+		Watches(&corev1.ServiceAccount{}, handler.EnqueueRequestsFromMapFunc(r.sharedResourceGrants), builder.WithPredicates(labelsChanged)).
+		Complete(r)
+}
@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("SharedResourceGrant controller", Label("sharedresourcegrant"), func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		reconciler *SharedResourceGrantReconciler
+		grant      *openshiftv1alpha1.SharedResourceGrant
+		objects    []client.Object
+	)
+
+	const roleName = "shared-resource-grant-certificates"
+
+	reconcile := func() {
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(grant)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(grant), grant)).To(Succeed())
+	}
+
+	binding := func(namespace string) (*rbacv1.RoleBinding, error) {
+		object := &rbacv1.RoleBinding{}
+		err := fakeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: roleName}, object)
+		return object, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		grant = &openshiftv1alpha1.SharedResourceGrant{
+			ObjectMeta: metav1.ObjectMeta{Name: "certificates", UID: "grant-uid"},
+			Spec: openshiftv1alpha1.SharedResourceGrantSpec{
+				SharedSecrets:    []string{"team-ca"},
+				SharedConfigMaps: []string{"team-settings"},
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"team": "a"},
+				},
+			},
+		}
+		objects = []client.Object{
+			grant,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Labels: map[string]string{"team": "a"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Labels: map[string]string{"team": "b"}}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "pipeline", Labels: map[string]string{"builds": "true"}}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "default"}},
+		}
+	})
+
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+			WithStatusSubresource(&openshiftv1alpha1.SharedResourceGrant{}).Build()
+		reconciler = &SharedResourceGrantReconciler{Client: fakeClient}
+	})
+
+	It("grants the use of the shared resources to the service accounts of the selected namespaces", func() {
+		reconcile()
+
+		role := &rbacv1.ClusterRole{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Name: roleName}, role)).To(Succeed())
+		Expect(metav1.IsControlledBy(role, grant)).To(BeTrue())
+		Expect(role.Rules).To(ConsistOf(
+			rbacv1.PolicyRule{APIGroups: []string{"sharedresource.openshift.io"}, Resources: []string{"sharedsecrets"}, ResourceNames: []string{"team-ca"}, Verbs: []string{"use"}},
+			rbacv1.PolicyRule{APIGroups: []string{"sharedresource.openshift.io"}, Resources: []string{"sharedconfigmaps"}, ResourceNames: []string{"team-settings"}, Verbs: []string{"use"}},
+		))
+
+		object, err := binding("team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(object.RoleRef.Name).To(Equal(roleName))
+		Expect(object.Subjects).To(Equal([]rbacv1.Subject{{APIGroup: rbacv1.GroupName, Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:team-a"}}))
+		_, err = binding("team-b")
+		Expect(err).To(Satisfy(apierrors.IsNotFound))
+
+		Expect(grant.Status.Grantees).To(Equal([]string{"system:serviceaccounts:team-a"}))
+		Expect(apimeta.IsStatusConditionTrue(grant.Status.Conditions, openshiftv1alpha1.ConditionReady)).To(BeTrue())
+	})
+
+	It("grants the selected service accounts only", func() {
+		grant.Spec.ServiceAccountSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"builds": "true"}}
+		Expect(fakeClient.Update(ctx, grant)).To(Succeed())
+		reconcile()
+
+		object, err := binding("team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(object.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "team-a", Name: "pipeline"}}))
+		Expect(grant.Status.Grantees).To(Equal([]string{"system:serviceaccount:team-a:pipeline"}))
+	})
+
+	It("grants the named service accounts only", func() {
+		grant.Spec.ServiceAccountNames = []string{"default"}
+		Expect(fakeClient.Update(ctx, grant)).To(Succeed())
+		reconcile()
+
+		object, err := binding("team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(object.Subjects).To(Equal([]rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Namespace: "team-a", Name: "default"}}))
+	})
+
+	It("prunes the RoleBindings of the namespaces no longer selected", func() {
+		reconcile()
+		grant.Spec.NamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "b"}}
+		Expect(fakeClient.Update(ctx, grant)).To(Succeed())
+		reconcile()
+
+		_, err := binding("team-a")
+		Expect(err).To(Satisfy(apierrors.IsNotFound))
+		_, err = binding("team-b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(grant.Status.Grantees).To(Equal([]string{"system:serviceaccounts:team-b"}))
+	})
+
+	It("grants no namespace without a selector", func() {
+		grant.Spec.NamespaceSelector = nil
+		Expect(fakeClient.Update(ctx, grant)).To(Succeed())
+		reconcile()
+
+		_, err := binding("team-a")
+		Expect(err).To(Satisfy(apierrors.IsNotFound))
+		Expect(grant.Status.Grantees).To(BeEmpty())
+	})
+})
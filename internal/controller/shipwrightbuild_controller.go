@@ -1,25 +1,251 @@
 package controller
 
 import (
-	"os"
+	"context"
+	"fmt"
+	"time"
 
 	manifestivalclient "github.com/manifestival/controller-runtime-client"
 	"github.com/manifestival/manifestival"
 	openshiftserviceca "github.com/openshift/service-ca-operator/pkg/controller/api"
 	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"github.com/redhat-openshift-builds/operator/internal/registries"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/rollout"
 	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
 	shipwrightoperator "github.com/shipwright-io/operator/controllers"
+	shipwrightcommon "github.com/shipwright-io/operator/pkg/common"
 	tektonoperatorv1alpha1 "github.com/tektoncd/operator/pkg/client/clientset/versioned/typed/operator/v1alpha1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
-type ShipwrightBuildReconciler shipwrightoperator.ShipwrightBuildReconciler
+// ShipwrightBuildReconciler runs the upstream Shipwright operator reconciler with the manifests
+// shipped by this operator.
+type ShipwrightBuildReconciler struct {
+	shipwrightoperator.ShipwrightBuildReconciler
+
+	// ManifestPath is the path of the Shipwright Build release manifests.
+	ManifestPath string
+
+	// BuildStrategyManifestPath is the path of the Shipwright Build strategy manifests.
+	BuildStrategyManifestPath string
+
+	// Images overrides the images of the Shipwright Build controllers and strategies.
+	Images *images.Overrides
+
+	// StagedUpgrades verifies the new Shipwright Build releases, and rolls back to the last
+	// verified release when the verification fails.
+	StagedUpgrades bool
+
+	// APIReader reads the objects verifying a release, which are not cached.
+	APIReader client.Reader
+
+	verifier *rollout.Verifier
+}
+
+// Reconcile applies the Shipwright Build release with the upstream reconciler. With staged
+// upgrades, a new release is verified once applied, and rolled back to the last verified
+// release if it fails its verification within the timeout. A rolled back release is not applied
+// again until the desired release changes, and the verified release is applied instead. A failed
+// release without a verified release to roll back to is kept, and verified again with backoff.
+func (r *ShipwrightBuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !r.StagedUpgrades {
+		return r.ShipwrightBuildReconciler.Reconcile(ctx, req)
+	}
+	logger := log.FromContext(ctx).WithValues("name", req.Name)
+
+	object := &shipwrightv1alpha1.ShipwrightBuild{}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, object); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	namespace := object.Spec.TargetNamespace
+	if !object.DeletionTimestamp.IsZero() || namespace == "" {
+		return r.ShipwrightBuildReconciler.Reconcile(ctx, req)
+	}
+
+	desired, err := r.release(namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	state, err := rollout.Load(ctx, r.APIReader, namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	logger = logger.WithValues("revision", desired.Revision)
+
+	switch desired.Revision {
+	case state.Verified.Revision:
+		return r.ShipwrightBuildReconciler.Reconcile(ctx, req)
+	case state.Failed:
+		if len(state.Verified.Resources) > 0 {
+			logger.Info("Release failed its verification, applying the rolled back release", "verified", state.Verified.Revision)
+			return ctrl.Result{}, r.applyVerified(state)
+		}
+		if wait := time.Until(state.RetryAt); wait > 0 {
+			result, err := r.ShipwrightBuildReconciler.Reconcile(ctx, req)
+			if err != nil || (result.RequeueAfter > 0 && result.RequeueAfter < wait) {
+				return result, err
+			}
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+		logger.Info("Verifying the release again", "attempts", state.Attempts)
+		state.Failed = ""
+		state.Progressing = desired.Revision
+		state.Started = time.Now()
+		if err := rollout.Save(ctx, r.Client, r.APIReader, namespace, object, state); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: rollout.PollInterval}, nil
+	case state.Progressing:
+		return r.verify(ctx, object, desired, state)
+	}
+
+	// Apply the new release, and verify it once the upstream reconciler reports it ready
+	result, err := r.ShipwrightBuildReconciler.Reconcile(ctx, req)
+	if err != nil || !result.IsZero() {
+		return result, err
+	}
+	if err := r.APIReader.Get(ctx, req.NamespacedName, object); err != nil {
+		return ctrl.Result{}, err
+	}
+	ready := apimeta.FindStatusCondition(object.Status.Conditions, openshiftv1alpha1.ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionTrue {
+		return result, nil
+	}
+	logger.Info("Release applied, verifying it", "verified", state.Verified.Revision)
+	state.Progressing = desired.Revision
+	state.Started = time.Now()
+	if err := rollout.Save(ctx, r.Client, r.APIReader, namespace, object, state); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: rollout.PollInterval}, nil
+}
+
+// verify checks the release being verified. A verified release replaces the last verified
+// release, and a failed release is rolled back to it.
+func (r *ShipwrightBuildReconciler) verify(ctx context.Context, object *shipwrightv1alpha1.ShipwrightBuild, desired rollout.Set, state *rollout.State) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithValues("name", object.Name, "revision", desired.Revision)
+	namespace := object.Spec.TargetNamespace
+
+	result, message, err := r.verifier.Verify(ctx, desired, namespace, object)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	switch result {
+	case rollout.Pending:
+		if time.Since(state.Started) < rollout.Timeout {
+			logger.Info("Verifying the release", "status", message)
+			return ctrl.Result{RequeueAfter: rollout.PollInterval}, nil
+		}
+		message = fmt.Sprintf("Release was not verified within %s: %s", rollout.Timeout, message)
+	case rollout.Verified:
+		logger.Info("Release verified")
+		state.Verified = desired
+		state.Progressing = ""
+		state.Started = time.Time{}
+		state.Failed = ""
+		state.Attempts = 0
+		state.RetryAt = time.Time{}
+		if err := rollout.Save(ctx, r.Client, r.APIReader, namespace, object, state); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.verifier.Cleanup(ctx, namespace, desired.Revision); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.setDegraded(ctx, object, metav1.ConditionFalse, rollout.ReasonAsExpected,
+			fmt.Sprintf("Release %s is verified", desired.Revision))
+	}
+
+	if err := r.verifier.Cleanup(ctx, namespace, desired.Revision); err != nil {
+		return ctrl.Result{}, err
+	}
+	requeue := ctrl.Result{}
+	reason := rollout.ReasonVerificationFailed
+	state.Progressing = ""
+	state.Started = time.Time{}
+	state.Failed = desired.Revision
+	if len(state.Verified.Resources) > 0 {
+		logger.Info("Release failed its verification, rolling back", "reason", message, "verified", state.Verified.Revision)
+		if err := r.applyVerified(state); err != nil {
+			return ctrl.Result{}, err
+		}
+		reason = rollout.ReasonRolledBack
+		message = fmt.Sprintf("Rolled back to release %s: %s", state.Verified.Revision, message)
+	} else {
+		// The verification may fail for transient reasons, such as slow image pulls
+		state.Attempts++
+		delay := rollout.RetryDelay(state.Attempts)
+		state.RetryAt = time.Now().Add(delay)
+		logger.Info("Release failed its verification, no verified release to roll back to", "reason", message, "retry", delay.String())
+		message = fmt.Sprintf("Verifying it again in %s: %s", delay, message)
+		requeue = ctrl.Result{RequeueAfter: delay}
+	}
+	if err := rollout.Save(ctx, r.Client, r.APIReader, namespace, object, state); err != nil {
+		return ctrl.Result{}, err
+	}
+	return requeue, r.setDegraded(ctx, object, metav1.ConditionTrue, reason,
+		fmt.Sprintf("Release %s failed its verification. %s", desired.Revision, message))
+}
+
+// applyVerified applies the last verified release
+func (r *ShipwrightBuildReconciler) applyVerified(state *rollout.State) error {
+	verified, err := manifestival.ManifestFrom(manifestival.Slice(state.Verified.Resources),
+		manifestival.UseClient(r.Manifest.Client), manifestival.UseLogger(r.Logger))
+	if err != nil {
+		return err
+	}
+	if err := verified.Apply(); err != nil {
+		return fmt.Errorf("failed to apply the verified release %s: %v", state.Verified.Revision, err)
+	}
+	return nil
+}
+
+// release renders the Shipwright Build release and strategies in the target namespace, as
+// applied by the upstream reconciler. The CRDs are left out, and are not rolled back.
+func (r *ShipwrightBuildReconciler) release(namespace string) (rollout.Set, error) {
+	manifest, err := r.Manifest.
+		Filter(manifestival.Not(manifestival.ByKind("Namespace")), manifestival.NoCRDs).
+		Transform(
+			manifestival.InjectNamespace(namespace),
+			shipwrightcommon.DeploymentImages(shipwrightcommon.ToLowerCaseKeys(shipwrightcommon.ImagesFromEnv(shipwrightcommon.ShipwrightImagePrefix))),
+		)
+	if err != nil {
+		return rollout.Set{}, fmt.Errorf("failed to render the Shipwright Build release: %v", err)
+	}
+	return rollout.NewSet(manifest.Resources(), r.BuildStrategyManifest.Resources())
+}
+
+// setDegraded sets the Degraded condition of the ShipwrightBuild. The upstream reconciler updates
+// the status concurrently, so only the condition is patched, on the latest status, and the patch
+// is retried on conflict.
+func (r *ShipwrightBuildReconciler) setDegraded(ctx context.Context, object *shipwrightv1alpha1.ShipwrightBuild, status metav1.ConditionStatus, reason, message string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.APIReader.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+			return err
+		}
+		patch := client.MergeFromWithOptions(object.DeepCopy(), client.MergeFromWithOptimisticLock{})
+		if !apimeta.SetStatusCondition(&object.Status.Conditions, metav1.Condition{
+			Type:               rollout.ConditionDegraded,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: object.Generation,
+		}) {
+			return nil
+		}
+		return r.Client.Status().Patch(ctx, object, patch)
+	})
+}
 
 func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Create Owner Reference for filtering
@@ -35,6 +261,15 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Initialize logger
 	r.Logger = mgr.GetLogger()
 
+	if r.APIReader == nil {
+		r.APIReader = mgr.GetAPIReader()
+	}
+	r.verifier = &rollout.Verifier{
+		Client:    mgr.GetClient(),
+		APIReader: r.APIReader,
+		Images:    r.Images,
+	}
+
 	// Initialize CRD client from REST config
 	if r.CRDClient, err = apiextensionsv1.NewForConfig(mgr.GetConfig()); err != nil {
 		return err
@@ -53,8 +288,8 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	// Shipwright Build release manifests
 	manifestPath := common.ShipwrightBuildManifestPath
-	if path, ok := os.LookupEnv(common.ShipwrightBuildManifestPathEnv); ok {
-		manifestPath = path
+	if r.ManifestPath != "" {
+		manifestPath = r.ManifestPath
 	}
 	if r.Manifest, err = manifestival.NewManifest(manifestPath, manifestivalOptions...); err != nil {
 		return err
@@ -63,6 +298,7 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Remove runAsUser and runAsGroup from a Deployment container's security context
 	// Insert Openshift Service CA annotations in service and CRD
 	if r.Manifest, err = r.Manifest.Transform(
+		r.Images.Transformer(),
 		common.RemoveRunAsUserRunAsGroup,
 		common.InjectAnnotations(
 			[]string{"Service"},
@@ -84,14 +320,29 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 
 	// Shipwright Build strategies manifests
 	manifestPath = common.ShipwrightBuildStrategyManifestPath
-	if path, ok := os.LookupEnv(common.ShipwrightBuildStrategyManifestPathEnv); ok {
-		manifestPath = path
+	if r.BuildStrategyManifestPath != "" {
+		manifestPath = r.BuildStrategyManifestPath
 	}
 	if r.BuildStrategyManifest, err = manifestival.NewManifest(manifestPath, manifestivalOptions...); err != nil {
 		return err
 	}
+	// Mount the registries configuration generated from the cluster image mirror sets
+	if r.BuildStrategyManifest, err = r.BuildStrategyManifest.Transform(
+		r.Images.Transformer(),
+		registries.InjectRegistriesConf(registries.Strategies...),
+	); err != nil {
+		return err
+	}
 
-	reconciler := shipwrightoperator.ShipwrightBuildReconciler(*r)
+	// The upstream reconciler replaces the controller images with the IMAGE_SHIPWRIGHT_* environment
+	// variables, which must be pinned by digest as well.
+	if r.Images != nil && r.Images.RequireDigests {
+		for name, image := range shipwrightcommon.ImagesFromEnv(shipwrightcommon.ShipwrightImagePrefix) {
+			if !images.IsPinned(image) {
+				return fmt.Errorf("%s%s: image %q is not pinned by digest", shipwrightcommon.ShipwrightImagePrefix, name, image)
+			}
+		}
+	}
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&shipwrightv1alpha1.ShipwrightBuild{}).
@@ -109,5 +360,5 @@ func (r *ShipwrightBuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				return false
 			},
 		}).
-		Complete(&reconciler)
+		Complete(r)
 }
@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
+	"github.com/manifestival/manifestival"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/rollout"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	shipwrightoperator "github.com/shipwright-io/operator/controllers"
+	appsv1 "k8s.io/api/apps/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var _ = Describe("ShipwrightBuild staged upgrades", Label("shipwrightbuild", "rollout"), func() {
+	const namespace = "openshift-builds"
+
+	var (
+		ctx             context.Context
+		fakeClient      client.Client
+		reconciler      *ShipwrightBuildReconciler
+		shipwrightBuild *shipwrightv1alpha1.ShipwrightBuild
+		previous        rollout.Set
+		desired         rollout.Set
+	)
+
+	deployment := func(image string) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "shp"}},
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "shp"}},
+					"spec": map[string]interface{}{
+						"containers": []interface{}{map[string]interface{}{"name": "controller", "image": image}},
+					},
+				},
+			},
+		}}
+		u.SetAPIVersion("apps/v1")
+		u.SetKind("Deployment")
+		u.SetNamespace(namespace)
+		u.SetName("shipwright-build-controller")
+		return u
+	}
+
+	reconcile := func() ctrl.Result {
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: shipwrightBuild.Name}})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	degraded := func() *metav1.Condition {
+		object := &shipwrightv1alpha1.ShipwrightBuild{}
+		Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(shipwrightBuild), object)).To(Succeed())
+		return apimeta.FindStatusCondition(object.Status.Conditions, rollout.ConditionDegraded)
+	}
+
+	controllerImage := func() string {
+		object := &appsv1.Deployment{}
+		Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "shipwright-build-controller"}, object)).To(Succeed())
+		return object.Spec.Template.Spec.Containers[0].Image
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(shipwrightv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		shipwrightBuild = &shipwrightv1alpha1.ShipwrightBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: "openshift-builds", UID: "uid"},
+			Spec:       shipwrightv1alpha1.ShipwrightBuildSpec{TargetNamespace: namespace},
+		}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(shipwrightBuild).
+			WithStatusSubresource(&shipwrightv1alpha1.ShipwrightBuild{}).Build()
+
+		var err error
+		previous, err = rollout.NewSet([]unstructured.Unstructured{deployment("registry.example.com/controller:v1")})
+		Expect(err).NotTo(HaveOccurred())
+		manifestClient := manifestival.UseClient(manifestivalclient.NewClient(fakeClient))
+		manifest, err := manifestival.ManifestFrom(manifestival.Slice([]unstructured.Unstructured{deployment("registry.example.com/controller:v2")}), manifestClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest.Apply()).To(Succeed())
+		strategies, err := manifestival.ManifestFrom(manifestival.Slice([]unstructured.Unstructured{}), manifestClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		reconciler = &ShipwrightBuildReconciler{
+			ShipwrightBuildReconciler: shipwrightoperator.ShipwrightBuildReconciler{
+				Client:                fakeClient,
+				Scheme:                scheme,
+				Manifest:              manifest,
+				BuildStrategyManifest: strategies,
+			},
+			StagedUpgrades: true,
+			APIReader:      fakeClient,
+			verifier:       &rollout.Verifier{Client: fakeClient, APIReader: fakeClient},
+		}
+		desired, err = reconciler.release(namespace)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the new release fails its verification", func() {
+		BeforeEach(func() {
+			state := &rollout.State{Verified: previous, Progressing: desired.Revision, Started: time.Now().Add(-time.Hour)}
+			Expect(rollout.Save(ctx, fakeClient, fakeClient, namespace, shipwrightBuild, state)).To(Succeed())
+		})
+
+		It("rolls back to the verified release", func() {
+			reconcile()
+			Expect(controllerImage()).To(Equal("registry.example.com/controller:v1"))
+
+			condition := degraded()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(rollout.ReasonRolledBack))
+
+			state, err := rollout.Load(ctx, fakeClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Failed).To(Equal(desired.Revision))
+			Expect(state.Verified.Revision).To(Equal(previous.Revision))
+			Expect(state.Progressing).To(BeEmpty())
+		})
+
+		It("keeps the status updated concurrently by the upstream reconciler", func() {
+			concurrent := false
+			reconciler.Client = interceptor.NewClient(fakeClient.(client.WithWatch), interceptor.Funcs{
+				SubResourcePatch: func(ctx context.Context, c client.Client, subResource string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+					if !concurrent {
+						concurrent = true
+						object := &shipwrightv1alpha1.ShipwrightBuild{}
+						Expect(c.Get(ctx, client.ObjectKeyFromObject(obj), object)).To(Succeed())
+						apimeta.SetStatusCondition(&object.Status.Conditions, metav1.Condition{
+							Type: "Ready", Status: metav1.ConditionTrue, Reason: "Success",
+						})
+						Expect(c.Status().Update(ctx, object)).To(Succeed())
+					}
+					return c.SubResource(subResource).Patch(ctx, obj, patch, opts...)
+				},
+			})
+			reconcile()
+			Expect(concurrent).To(BeTrue())
+			Expect(degraded()).NotTo(BeNil())
+
+			object := &shipwrightv1alpha1.ShipwrightBuild{}
+			Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(shipwrightBuild), object)).To(Succeed())
+			Expect(apimeta.IsStatusConditionTrue(object.Status.Conditions, "Ready")).To(BeTrue())
+		})
+
+		It("keeps applying the verified release instead of the failed release", func() {
+			reconcile()
+
+			object := &appsv1.Deployment{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "shipwright-build-controller"}, object)).To(Succeed())
+			object.Spec.Template.Spec.Containers[0].Image = "registry.example.com/controller:drifted"
+			Expect(fakeClient.Update(ctx, object)).To(Succeed())
+
+			Expect(reconcile()).To(Equal(ctrl.Result{}))
+			Expect(controllerImage()).To(Equal("registry.example.com/controller:v1"))
+		})
+	})
+
+	When("the first release fails its verification", func() {
+		BeforeEach(func() {
+			state := &rollout.State{Progressing: desired.Revision, Started: time.Now().Add(-time.Hour)}
+			Expect(rollout.Save(ctx, fakeClient, fakeClient, namespace, shipwrightBuild, state)).To(Succeed())
+		})
+
+		It("keeps the release and verifies it again with backoff", func() {
+			Expect(reconcile().RequeueAfter).To(Equal(rollout.RetryInterval))
+			Expect(controllerImage()).To(Equal("registry.example.com/controller:v2"))
+
+			condition := degraded()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(rollout.ReasonVerificationFailed))
+
+			state, err := rollout.Load(ctx, fakeClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Failed).To(Equal(desired.Revision))
+			Expect(state.Attempts).To(Equal(1))
+			Expect(state.RetryAt).To(BeTemporally("~", time.Now().Add(rollout.RetryInterval), 5*time.Second))
+		})
+	})
+
+	When("the backoff of a release without a verified release elapsed", func() {
+		BeforeEach(func() {
+			state := &rollout.State{Failed: desired.Revision, Attempts: 2, RetryAt: time.Now().Add(-time.Second)}
+			Expect(rollout.Save(ctx, fakeClient, fakeClient, namespace, shipwrightBuild, state)).To(Succeed())
+		})
+
+		It("verifies the release again", func() {
+			Expect(reconcile().RequeueAfter).To(Equal(rollout.PollInterval))
+
+			state, err := rollout.Load(ctx, fakeClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Failed).To(BeEmpty())
+			Expect(state.Progressing).To(Equal(desired.Revision))
+			Expect(state.Attempts).To(Equal(2))
+		})
+	})
+
+	When("the new release is being verified", func() {
+		BeforeEach(func() {
+			state := &rollout.State{Verified: previous, Progressing: desired.Revision, Started: time.Now()}
+			Expect(rollout.Save(ctx, fakeClient, fakeClient, namespace, shipwrightBuild, state)).To(Succeed())
+		})
+
+		It("waits for the verification within the timeout", func() {
+			Expect(reconcile().RequeueAfter).To(Equal(rollout.PollInterval))
+			Expect(controllerImage()).To(Equal("registry.example.com/controller:v2"))
+			Expect(degraded()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,14 @@
+package controller
+
+// The Shipwright Triggers controller is deployed from the manifests in config/shipwright/triggers.
+// As for Shipwright Build, the operator is only allowed to modify the named resources it deploys,
+// and holds the permissions it grants to the trigger controller.
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,resourceNames=shipwright-triggers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,resourceNames=shipwright-triggers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles,resourceNames=shipwright-triggers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,resourceNames=shipwright-triggers,verbs=update;patch;delete
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=shipwright.io,resources=builds,verbs=get;list;watch
+// +kubebuilder:rbac:groups=shipwright.io,resources=buildruns,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=tekton.dev,resources=pipelineruns;customruns,verbs=get;list;watch;update;patch
@@ -34,10 +34,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	operatorv1beta1 "github.com/redhat-openshift-builds/operator/api/v1beta1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
-	shipwrightbuild "github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/config"
+	webhookv1alpha1 "github.com/redhat-openshift-builds/operator/internal/webhook/v1alpha1"
 	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
 	//+kubebuilder:scaffold:imports
 )
@@ -64,6 +68,12 @@ var _ = BeforeSuite(func(ctx SpecContext) {
 
 	mgrCtx, cancel = context.WithCancel(context.Background())
 
+	// The scheme must hold all the OpenShiftBuild versions before the test environment starts, so
+	// that the conversion webhook is configured on the CRD.
+	Expect(operatorv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(operatorv1beta1.AddToScheme(scheme.Scheme)).To(Succeed())
+	Expect(shipwrightv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
+
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
 		CRDDirectoryPaths: []string{
@@ -72,6 +82,9 @@ var _ = BeforeSuite(func(ctx SpecContext) {
 			filepath.Join("..", "..", "test", "integration", "crd"),
 		},
 		ErrorIfCRDPathMissing: true,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			Paths: []string{filepath.Join("..", "..", "config", "webhook")},
+		},
 
 		// The BinaryAssetsDirectory is only required if you want to run the tests directly
 		// without call the makefile target test. If not informed it will look for the
@@ -88,32 +101,43 @@ var _ = BeforeSuite(func(ctx SpecContext) {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
-	Expect(operatorv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
-	Expect(shipwrightv1alpha1.AddToScheme(scheme.Scheme)).To(Succeed())
-
 	//+kubebuilder:scaffold:scheme
 
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	Expect(err).NotTo(HaveOccurred())
 	Expect(k8sClient).NotTo(BeNil())
 
+	webhookInstallOptions := &testEnv.WebhookInstallOptions
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme: scheme.Scheme,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    webhookInstallOptions.LocalServingHost,
+			Port:    webhookInstallOptions.LocalServingPort,
+			CertDir: webhookInstallOptions.LocalServingCertDir,
+		}),
 	})
 	Expect(err).NotTo(HaveOccurred())
 
+	Expect(webhookv1alpha1.SetupOpenShiftBuildWebhookWithManager(mgr)).To(Succeed())
+
+	// Working directory is the test suite's parent directory, point the manifest paths to the
+	// correct location.
+	operatorConfig := config.Default()
+	operatorConfig.Manifests.ShipwrightTriggers = filepath.Join("..", "..", "config", "shipwright", "triggers")
+	operatorConfig.Manifests.SharedResource = filepath.Join("..", "..", "config", "sharedresource")
+	operatorConfig.Manifests.NetworkPolicy = filepath.Join("..", "..", "config", "networkpolicies")
+
+	components, err := component.NewRegistry(DefaultComponents(mgr.GetClient(), mgr.GetLogger(), operatorConfig)...)
+	Expect(err).NotTo(HaveOccurred())
+
 	opBuildReconciler := &OpenShiftBuildReconciler{
 		Client:     mgr.GetClient(),
 		Scheme:     mgr.GetScheme(),
-		Shipwright: shipwrightbuild.New(mgr.GetClient(), "openshift-builds"),
+		Components: components,
 	}
 
-	// HACK - working directory is the test suite's parent directory.
-	// Override the global shared resource path to the correct location.
-	// TODO: Make manifest paths a field on the respective reconciler.
-	common.SharedResourceManifestPath = filepath.Join("..", "..", "config", "sharedresource")
-	common.NetworkPolicyManifestPath = filepath.Join("..", "..", "config", "networkpolicies")
 	Expect(opBuildReconciler.SetupWithManager(mgr)).To(Succeed())
+	Expect((&RegistriesReconciler{}).SetupWithManager(mgr)).To(Succeed())
 
 	// Create namespace where operands are deployed. Manifestival does a check for existence.
 	namespace := &corev1.Namespace{
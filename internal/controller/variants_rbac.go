@@ -0,0 +1,7 @@
+package controller
+
+// The hardened variants of the build strategies are generated by the BuildStrategyVariants
+// component. The operator holds the permission to use the SecurityContextConstraints of the user
+// namespace variants, which it grants to the service accounts of the selected namespaces.
+
+// +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,resourceNames=openshift-builds-userns,verbs=use
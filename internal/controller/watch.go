@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// kindWatcher adds watches to a running controller for kinds which may not be served by the
+// cluster when the operator starts, such as the OpenShift configuration or the Shipwright APIs.
+type kindWatcher struct {
+	cache      cache.Cache
+	controller controller.Controller
+	mu         sync.Mutex
+	watched    map[schema.GroupVersionKind]bool
+}
+
+// watch starts watching the kind of the object if it is served by the cluster, and returns
+// whether it is watched. Kinds which are not served yet are checked again on the next call.
+func (w *kindWatcher) watch(ctx context.Context, mapper apimeta.RESTMapper, object client.Object, h handler.EventHandler, predicates ...predicate.Predicate) (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	gvk := object.GetObjectKind().GroupVersionKind()
+	if w.watched[gvk] {
+		return true, nil
+	}
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if apimeta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if w.controller == nil {
+		return false, nil
+	}
+
+	if err := w.controller.Watch(source.Kind(w.cache, object, h, predicates...)); err != nil {
+		return false, err
+	}
+	log.FromContext(ctx).Info("Watching kind", "kind", gvk.String())
+	if w.watched == nil {
+		w.watched = map[schema.GroupVersionKind]bool{}
+	}
+	w.watched[gvk] = true
+	return true, nil
+}
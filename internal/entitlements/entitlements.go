@@ -0,0 +1,394 @@
+package entitlements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ComponentName is the name of the Entitlements component
+const ComponentName = "Entitlements"
+
+// The cluster entitlement secret, maintained by the Insights Operator when Simple Content Access
+// is enabled
+const (
+	SecretName      = "etc-pki-entitlement"
+	SecretNamespace = "openshift-config-managed"
+)
+
+// Names of the objects sharing the cluster entitlement. The SharedSecret name is reserved for the
+// cluster entitlement secret by the Shared Resource CSI Driver webhook.
+const (
+	SharedSecretName = "openshift-etc-pki-entitlement"
+	ClusterRoleName  = "openshift-builds-entitlement"
+	RoleBindingName  = "openshift-builds-entitlement"
+	ReaderRoleName   = "openshift-builds-entitlement-reader"
+)
+
+// Label is set on the objects created to share the cluster entitlement
+const Label = "operator.openshift.io/entitlement"
+
+// MountAnnotation requests the cluster entitlement to be mounted into the BuildRuns of a Build or
+// of a BuildRun when set to "true"
+const MountAnnotation = "operator.openshift.io/mount-entitlement"
+
+// VolumeName is the volume of the build strategies mounted at /etc/pki/entitlement
+const VolumeName = "etc-pki-entitlement"
+
+// CSIDriverName is the name of the Shared Resource CSI Driver
+const CSIDriverName = "csi.sharedresource.openshift.io"
+
+// CSIDriverServiceAccountName is the service account of the Shared Resource CSI Driver, which
+// reads the shared secret
+const CSIDriverServiceAccountName = "csi-driver-shared-resource"
+
+// SharedSecretGVK is the kind of the SharedSecrets of the Shared Resource CSI Driver
+var SharedSecretGVK = schema.GroupVersionKind{Group: "sharedresource.openshift.io", Version: "v1alpha1", Kind: "SharedSecret"}
+
+var _ component.Component = &Entitlements{}
+var _ component.Watcher = &Entitlements{}
+
+// Entitlements shares the cluster entitlement secret through a SharedSecret, and allows the
+// service accounts of the selected namespaces to use it
+type Entitlements struct {
+	Client client.Client
+}
+
+// New creates new instance of Entitlements type
+func New(client client.Client) *Entitlements {
+	return &Entitlements{
+		Client: client,
+	}
+}
+
+// Name returns the component name
+func (e *Entitlements) Name() string {
+	return ComponentName
+}
+
+// Setup initializes the client from the manager if not set
+func (e *Entitlements) Setup(mgr ctrl.Manager) error {
+	if e.Client == nil {
+		e.Client = mgr.GetClient()
+	}
+	return nil
+}
+
+// Reconcile shares the cluster entitlement with the namespaces selected by the owner, or removes
+// the sharing when disabled
+func (e *Entitlements) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if !owner.DeletionTimestamp.IsZero() || !Enabled(owner) {
+		return e.Delete(ctx, owner)
+	}
+	if owner.Spec.SharedResource != nil && owner.Spec.SharedResource.State == openshiftv1alpha1.Disabled {
+		return errors.New("entitlements are shared through the Shared Resource CSI Driver, which is disabled")
+	}
+	selector, err := metav1.LabelSelectorAsSelector(owner.Spec.Entitlements.NamespaceSelector)
+	if err != nil {
+		return fmt.Errorf("invalid namespace selector: %v", err)
+	}
+
+	if err := e.reconcileSharedSecret(ctx, owner); err != nil {
+		return err
+	}
+	if err := e.reconcileReaderRole(ctx, owner); err != nil {
+		return err
+	}
+	if err := e.reconcileClusterRole(ctx, owner); err != nil {
+		return err
+	}
+	return e.reconcileRoleBindings(ctx, owner, selector)
+}
+
+// Delete removes the SharedSecret and the RBAC sharing the cluster entitlement
+func (e *Entitlements) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	bindings, err := e.roleBindings(ctx)
+	if err != nil {
+		return err
+	}
+	objects := []client.Object{}
+	for i := range bindings {
+		objects = append(objects, &bindings[i])
+	}
+	sharedSecret := &unstructured.Unstructured{}
+	sharedSecret.SetGroupVersionKind(SharedSecretGVK)
+	sharedSecret.SetName(SharedSecretName)
+	objects = append(objects,
+		&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleName}},
+		&rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: SecretNamespace, Name: ReaderRoleName}},
+		&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: SecretNamespace, Name: ReaderRoleName}},
+		sharedSecret,
+	)
+	for _, object := range objects {
+		if err := e.Client.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+			return fmt.Errorf("failed to delete %T %s: %v", object, client.ObjectKeyFromObject(object), err)
+		}
+	}
+	return nil
+}
+
+// Status reports whether the cluster entitlement exists, and the number of namespaces it is
+// shared with
+func (e *Entitlements) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	condition := metav1.Condition{
+		Type: component.ConditionType(e),
+	}
+	if !Enabled(owner) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disabled"
+		condition.Message = "Entitlement sharing is disabled"
+		return condition
+	}
+
+	if err := e.Client.Get(ctx, types.NamespacedName{Namespace: SecretNamespace, Name: SecretName}, &corev1.Secret{}); err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretNotFound"
+		condition.Message = fmt.Sprintf("Failed to get the cluster entitlement secret %s/%s, Simple Content Access may not be enabled: %v",
+			SecretNamespace, SecretName, err)
+		return condition
+	}
+	bindings, err := e.roleBindings(ctx)
+	if err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "Unknown"
+		condition.Message = fmt.Sprintf("Failed to list the namespaces sharing the entitlement: %v", err)
+		return condition
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Shared"
+	condition.Message = fmt.Sprintf("Cluster entitlement is shared as SharedSecret %s with %d namespaces", SharedSecretName, len(bindings))
+	return condition
+}
+
+// WatchedTypes returns nil as changes to the shared objects do not trigger a reconciliation
+func (e *Entitlements) WatchedTypes() []client.Object {
+	return nil
+}
+
+// Watches returns the namespaces, so that namespaces are granted the use of the entitlement
+// when their labels start matching the selector, and denied when they stop matching
+func (e *Entitlements) Watches() []component.Watch {
+	return []component.Watch{
+		{
+			Object: &corev1.Namespace{},
+			Predicates: []predicate.Predicate{predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return !labels.Equals(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+				},
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}},
+		},
+	}
+}
+
+// reconcileSharedSecret shares the cluster entitlement secret as a SharedSecret
+func (e *Entitlements) reconcileSharedSecret(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(SharedSecretGVK)
+	object.SetName(SharedSecretName)
+	_, err := ctrl.CreateOrUpdate(ctx, e.Client, object, func() error {
+		setLabel(object)
+		if err := unstructured.SetNestedStringMap(object.Object, map[string]string{
+			"name":      SecretName,
+			"namespace": SecretNamespace,
+		}, "spec", "secretRef"); err != nil {
+			return err
+		}
+		return ctrl.SetControllerReference(owner, object, e.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile SharedSecret %s: %v", SharedSecretName, err)
+	}
+	return nil
+}
+
+// reconcileReaderRole allows the Shared Resource CSI Driver to read the cluster entitlement secret
+func (e *Entitlements) reconcileReaderRole(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	role := &rbacv1.Role{ObjectMeta: metav1.ObjectMeta{Namespace: SecretNamespace, Name: ReaderRoleName}}
+	_, err := ctrl.CreateOrUpdate(ctx, e.Client, role, func() error {
+		setLabel(role)
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: []string{SecretName},
+			Verbs:         []string{"get", "list", "watch"},
+		}}
+		return ctrl.SetControllerReference(owner, role, e.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile Role %s/%s: %v", SecretNamespace, ReaderRoleName, err)
+	}
+
+	binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: SecretNamespace, Name: ReaderRoleName}}
+	_, err = ctrl.CreateOrUpdate(ctx, e.Client, binding, func() error {
+		setLabel(binding)
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: ReaderRoleName}
+		binding.Subjects = []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Namespace: common.OperandNamespace(owner),
+			Name:      CSIDriverServiceAccountName,
+		}}
+		return ctrl.SetControllerReference(owner, binding, e.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile RoleBinding %s/%s: %v", SecretNamespace, ReaderRoleName, err)
+	}
+	return nil
+}
+
+// reconcileClusterRole creates the ClusterRole allowing the use of the SharedSecret
+func (e *Entitlements) reconcileClusterRole(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: ClusterRoleName}}
+	_, err := ctrl.CreateOrUpdate(ctx, e.Client, role, func() error {
+		setLabel(role)
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{SharedSecretGVK.Group},
+			Resources:     []string{"sharedsecrets"},
+			ResourceNames: []string{SharedSecretName},
+			Verbs:         []string{"use"},
+		}}
+		return ctrl.SetControllerReference(owner, role, e.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ClusterRole %s: %v", ClusterRoleName, err)
+	}
+	return nil
+}
+
+// reconcileRoleBindings allows the service accounts of the selected namespaces to use the
+// SharedSecret, and removes the RoleBindings of the namespaces which are no longer selected
+func (e *Entitlements) reconcileRoleBindings(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, selector labels.Selector) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	namespaces := &corev1.NamespaceList{}
+	if err := e.Client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("failed to list the namespaces selected for entitlements: %v", err)
+	}
+	selected := map[string]bool{}
+	for _, namespace := range namespaces.Items {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		selected[namespace.Name] = true
+		binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: RoleBindingName}}
+		result, err := ctrl.CreateOrUpdate(ctx, e.Client, binding, func() error {
+			setLabel(binding)
+			binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: ClusterRoleName}
+			binding.Subjects = []rbacv1.Subject{{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.GroupKind,
+				Name:     "system:serviceaccounts:" + namespace.Name,
+			}}
+			return ctrl.SetControllerReference(owner, binding, e.Client.Scheme())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reconcile RoleBinding %s/%s: %v", namespace.Name, RoleBindingName, err)
+		}
+		if result != "unchanged" {
+			logger.Info("Entitlement shared with namespace", "namespace", namespace.Name, "result", result)
+		}
+	}
+
+	bindings, err := e.roleBindings(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range bindings {
+		if selected[bindings[i].Namespace] {
+			continue
+		}
+		if err := e.Client.Delete(ctx, &bindings[i]); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete RoleBinding %s/%s: %v", bindings[i].Namespace, bindings[i].Name, err)
+		}
+		logger.Info("Entitlement no longer shared with namespace", "namespace", bindings[i].Namespace)
+	}
+	return nil
+}
+
+// roleBindings returns the RoleBindings allowing namespaces to use the SharedSecret
+func (e *Entitlements) roleBindings(ctx context.Context) ([]rbacv1.RoleBinding, error) {
+	list := &rbacv1.RoleBindingList{}
+	if err := e.Client.List(ctx, list, client.HasLabels{Label}); err != nil {
+		return nil, fmt.Errorf("failed to list the entitlement RoleBindings: %v", err)
+	}
+	bindings := []rbacv1.RoleBinding{}
+	for _, binding := range list.Items {
+		if binding.Name == RoleBindingName {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings, nil
+}
+
+// Enabled returns whether the owner shares the cluster entitlement
+func Enabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return owner.Spec.Entitlements != nil && owner.Spec.Entitlements.State == openshiftv1alpha1.Enabled
+}
+
+// Selected returns whether the namespace is allowed to use the cluster entitlement
+func Selected(owner *openshiftv1alpha1.OpenShiftBuild, namespace *corev1.Namespace) bool {
+	if !Enabled(owner) {
+		return false
+	}
+	selector, err := metav1.LabelSelectorAsSelector(owner.Spec.Entitlements.NamespaceSelector)
+	if err != nil {
+		return false
+	}
+	return selector.Matches(labels.Set(namespace.Labels))
+}
+
+// Mount mounts the SharedSecret into the entitlement volume of the strategy, unless the BuildRun
+// or its Build already override the volume. It returns whether the BuildRun was changed.
+func Mount(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec) bool {
+	volumes := buildRun.Spec.Volumes
+	if build != nil {
+		volumes = append(append([]buildv1beta1.BuildVolume{}, volumes...), build.Volumes...)
+	}
+	for _, volume := range volumes {
+		if volume.Name == VolumeName {
+			return false
+		}
+	}
+	buildRun.Spec.Volumes = append(buildRun.Spec.Volumes, buildv1beta1.BuildVolume{
+		Name: VolumeName,
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:           CSIDriverName,
+				ReadOnly:         ptr.To(true),
+				VolumeAttributes: map[string]string{"sharedSecret": SharedSecretName},
+			},
+		},
+	})
+	return true
+}
+
+// setLabel marks the object as sharing the cluster entitlement
+func setLabel(object client.Object) {
+	objectLabels := object.GetLabels()
+	if objectLabels == nil {
+		objectLabels = map[string]string{}
+	}
+	objectLabels[Label] = "true"
+	object.SetLabels(objectLabels)
+}
@@ -0,0 +1,13 @@
+package entitlements_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestEntitlements(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Entitlements Suite")
+}
@@ -0,0 +1,170 @@
+package entitlements_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/entitlements"
+	"github.com/redhat-openshift-builds/operator/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Entitlements", Label("entitlements"), func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		owner     *openshiftv1alpha1.OpenShiftBuild
+		k8sClient client.Client
+		component *entitlements.Entitlements
+		enabled   *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	newSharedSecret := func() *unstructured.Unstructured {
+		sharedSecret := &unstructured.Unstructured{}
+		sharedSecret.SetGroupVersionKind(entitlements.SharedSecretGVK)
+		return sharedSecret
+	}
+	bindingKey := func(namespace string) types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: entitlements.RoleBindingName}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = testutil.NewScheme()
+		owner = testutil.NewOwner()
+		owner.Default()
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "entitled", Labels: map[string]string{"entitled": "true"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		).Build()
+		component = entitlements.New(k8sClient)
+
+		enabled = owner.DeepCopy()
+		enabled.Spec.Entitlements.State = openshiftv1alpha1.Enabled
+		enabled.Spec.Entitlements.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"entitled": "true"},
+		}
+	})
+
+	Describe("Reconcile", func() {
+		It("shares nothing when the entitlements are disabled", func() {
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: entitlements.SharedSecretName}, newSharedSecret())).
+				To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("shares the entitlement secret with the selected namespaces", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			sharedSecret := newSharedSecret()
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: entitlements.SharedSecretName}, sharedSecret)).To(Succeed())
+			Expect(metav1.IsControlledBy(sharedSecret, enabled)).To(BeTrue())
+			secretRef, _, _ := unstructured.NestedStringMap(sharedSecret.Object, "spec", "secretRef")
+			Expect(secretRef).To(Equal(map[string]string{
+				"name":      entitlements.SecretName,
+				"namespace": entitlements.SecretNamespace,
+			}))
+
+			reader := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: entitlements.SecretNamespace, Name: entitlements.ReaderRoleName}, reader)).To(Succeed())
+			Expect(reader.Subjects[0].Namespace).To(Equal(common.OpenShiftBuildNamespaceName))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: entitlements.ClusterRoleName}, &rbacv1.ClusterRole{})).To(Succeed())
+
+			binding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, bindingKey("entitled"), binding)).To(Succeed())
+			Expect(binding.RoleRef.Name).To(Equal(entitlements.ClusterRoleName))
+			Expect(binding.Subjects[0].Name).To(Equal("system:serviceaccounts:entitled"))
+			Expect(k8sClient.Get(ctx, bindingKey("other"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("shares with no namespace without a selector", func() {
+			enabled.Spec.Entitlements.NamespaceSelector = nil
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, bindingKey("entitled"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("shares with all namespaces with an empty selector", func() {
+			enabled.Spec.Entitlements.NamespaceSelector = &metav1.LabelSelector{}
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, bindingKey("entitled"), &rbacv1.RoleBinding{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, bindingKey("other"), &rbacv1.RoleBinding{})).To(Succeed())
+		})
+
+		It("stops sharing with namespaces which are no longer selected", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "entitled"}, namespace)).To(Succeed())
+			namespace.Labels = nil
+			Expect(k8sClient.Update(ctx, namespace)).To(Succeed())
+
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, bindingKey("entitled"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("removes the sharing when disabled", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: entitlements.SharedSecretName}, newSharedSecret())).
+				To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: entitlements.ClusterRoleName}, &rbacv1.ClusterRole{})).
+				To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, bindingKey("entitled"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("fails when the Shared Resource CSI Driver is disabled", func() {
+			enabled.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+			Expect(component.Reconcile(ctx, enabled)).To(MatchError(ContainSubstring("Shared Resource CSI Driver")))
+		})
+	})
+
+	Describe("Status", func() {
+		It("reports the entitlements are disabled", func() {
+			condition := component.Status(ctx, owner)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Disabled"))
+		})
+
+		It("reports a missing entitlement secret", func() {
+			condition := component.Status(ctx, enabled)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("SecretNotFound"))
+		})
+
+		It("reports the number of namespaces sharing the entitlement", func() {
+			Expect(k8sClient.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: entitlements.SecretNamespace, Name: entitlements.SecretName},
+			})).To(Succeed())
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			condition := component.Status(ctx, enabled)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("Shared"))
+			Expect(condition.Message).To(ContainSubstring("with 1 namespaces"))
+		})
+	})
+
+	Describe("Selected", func() {
+		It("selects the namespaces matching the selector", func() {
+			Expect(entitlements.Selected(enabled, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"entitled": "true"}},
+			})).To(BeTrue())
+			Expect(entitlements.Selected(enabled, &corev1.Namespace{})).To(BeFalse())
+		})
+
+		It("selects no namespace when disabled", func() {
+			Expect(entitlements.Selected(owner, &corev1.Namespace{})).To(BeFalse())
+		})
+	})
+})
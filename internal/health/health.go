@@ -0,0 +1,223 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// DefaultReconcileTimeout is the duration after which an in-flight reconciliation is considered wedged
+const DefaultReconcileTimeout = 10 * time.Minute
+
+// CacheSyncTimeout is the maximum duration a probe waits for the informer caches to sync
+const CacheSyncTimeout = time.Second
+
+// Result values reported for component reconciliations
+const (
+	ResultSuccess = "Success"
+	ResultFailure = "Failure"
+)
+
+// ComponentStatus is the outcome of the last reconciliation of a component
+type ComponentStatus struct {
+	Name              string    `json:"name"`
+	LastReconcileTime time.Time `json:"lastReconcileTime"`
+	Duration          string    `json:"duration"`
+	Result            string    `json:"result"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// Tracker records the progress of the OpenShiftBuild reconcile loop, and exposes it through
+// health probes and the components debug endpoint. A nil Tracker records nothing.
+type Tracker struct {
+	// Reader is used to check whether an OpenShiftBuild exists when no reconciliation succeeded yet.
+	Reader client.Reader
+
+	// Elected is closed when the manager is elected leader. Until then, no reconciliation is
+	// expected and the probes report success.
+	Elected <-chan struct{}
+
+	// Timeout is the duration after which an in-flight reconciliation is considered wedged.
+	Timeout time.Duration
+
+	mu          sync.RWMutex
+	started     time.Time
+	lastSuccess time.Time
+	lastError   error
+	names       []string
+	components  map[string]ComponentStatus
+}
+
+// NewTracker creates new instance of Tracker type
+func NewTracker(reader client.Reader, elected <-chan struct{}) *Tracker {
+	return &Tracker{
+		Reader:  reader,
+		Elected: elected,
+		Timeout: DefaultReconcileTimeout,
+	}
+}
+
+// ReconcileStarted records the start of a reconciliation
+func (t *Tracker) ReconcileStarted() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started = time.Now()
+}
+
+// ReconcileFinished records the end of a reconciliation and its result
+func (t *Tracker) ReconcileFinished(err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.started = time.Time{}
+	t.lastError = err
+	if err == nil {
+		t.lastSuccess = time.Now()
+	}
+}
+
+// ComponentReconciled records the result of a component reconciliation started at the given time
+func (t *Tracker) ComponentReconciled(name string, started time.Time, err error) {
+	if t == nil {
+		return
+	}
+	status := ComponentStatus{
+		Name:              name,
+		LastReconcileTime: started,
+		Duration:          time.Since(started).String(),
+		Result:            ResultSuccess,
+	}
+	if err != nil {
+		status.Result = ResultFailure
+		status.Error = err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.components == nil {
+		t.components = map[string]ComponentStatus{}
+	}
+	if _, ok := t.components[name]; !ok {
+		t.names = append(t.names, name)
+	}
+	t.components[name] = status
+}
+
+// Components returns the last reconciliation status of each component, in the order they were
+// first reconciled
+func (t *Tracker) Components() []ComponentStatus {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	statuses := make([]ComponentStatus, 0, len(t.names))
+	for _, name := range t.names {
+		statuses = append(statuses, t.components[name])
+	}
+	return statuses
+}
+
+// ReadyzCheck is a healthz.Checker reporting an error until a reconciliation succeeded, unless
+// there is no OpenShiftBuild to reconcile or the manager is not the elected leader.
+func (t *Tracker) ReadyzCheck(req *http.Request) error {
+	if !t.elected() {
+		return nil
+	}
+
+	t.mu.RLock()
+	succeeded, lastError := !t.lastSuccess.IsZero(), t.lastError
+	t.mu.RUnlock()
+	if succeeded {
+		return nil
+	}
+
+	if t.Reader != nil {
+		list := &openshiftv1alpha1.OpenShiftBuildList{}
+		if err := t.Reader.List(requestContext(req), list); err != nil {
+			return fmt.Errorf("failed to list OpenShiftBuild: %v", err)
+		}
+		if len(list.Items) == 0 {
+			return nil
+		}
+	}
+	if lastError != nil {
+		return fmt.Errorf("OpenShiftBuild has not been reconciled successfully: %v", lastError)
+	}
+	return errors.New("OpenShiftBuild has not been reconciled yet")
+}
+
+// LivezCheck is a healthz.Checker reporting an error when a reconciliation is in flight for
+// longer than the timeout, which denotes a wedged reconcile loop.
+func (t *Tracker) LivezCheck(_ *http.Request) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.started.IsZero() || t.Timeout <= 0 {
+		return nil
+	}
+	if elapsed := time.Since(t.started); elapsed > t.Timeout {
+		return fmt.Errorf("reconciliation in flight for %s, exceeding the %s timeout", elapsed.Round(time.Second), t.Timeout)
+	}
+	return nil
+}
+
+// ServeHTTP serves the last reconciliation status of each component as JSON
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.Components()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// elected returns true if the manager is the elected leader
+func (t *Tracker) elected() bool {
+	if t == nil {
+		return false
+	}
+	if t.Elected == nil {
+		return true
+	}
+	select {
+	case <-t.Elected:
+		return true
+	default:
+		return false
+	}
+}
+
+// CacheSyncCheck returns a healthz.Checker reporting an error until the informer caches are synced
+func CacheSyncCheck(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(requestContext(req), CacheSyncTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches are not synced")
+		}
+		return nil
+	}
+}
+
+// requestContext returns the context of the probe request
+func requestContext(req *http.Request) context.Context {
+	if req == nil {
+		return context.Background()
+	}
+	return req.Context()
+}
@@ -0,0 +1,94 @@
+package health_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/health"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Tracker", Label("health"), func() {
+	var (
+		tracker *health.Tracker
+		elected chan struct{}
+	)
+
+	BeforeEach(func() {
+		elected = make(chan struct{})
+		openShiftBuild := &operatorv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		}
+		tracker = health.NewTracker(fake.NewClientBuilder().WithScheme(scheme).WithObjects(openShiftBuild).Build(), elected)
+	})
+
+	Describe("ReadyzCheck", func() {
+		It("reports ready when the manager is not the elected leader", func() {
+			Expect(tracker.ReadyzCheck(nil)).To(Succeed())
+		})
+
+		When("the manager is the elected leader", func() {
+			BeforeEach(func() {
+				close(elected)
+			})
+
+			It("reports an error until a reconciliation succeeds", func() {
+				Expect(tracker.ReadyzCheck(nil)).To(MatchError(ContainSubstring("not been reconciled yet")))
+				tracker.ReconcileStarted()
+				tracker.ReconcileFinished(errors.New("conflict"))
+				Expect(tracker.ReadyzCheck(nil)).To(MatchError(ContainSubstring("conflict")))
+				tracker.ReconcileStarted()
+				tracker.ReconcileFinished(nil)
+				Expect(tracker.ReadyzCheck(nil)).To(Succeed())
+			})
+
+			It("reports ready when there is no OpenShiftBuild to reconcile", func() {
+				tracker.Reader = fake.NewClientBuilder().WithScheme(scheme).Build()
+				Expect(tracker.ReadyzCheck(nil)).To(Succeed())
+			})
+		})
+	})
+
+	Describe("LivezCheck", func() {
+		It("reports healthy when no reconciliation is in flight", func() {
+			Expect(tracker.LivezCheck(nil)).To(Succeed())
+		})
+
+		It("reports an error when a reconciliation exceeds the timeout", func() {
+			tracker.Timeout = time.Millisecond
+			tracker.ReconcileStarted()
+			Eventually(func() error { return tracker.LivezCheck(nil) }).Should(MatchError(ContainSubstring("in flight")))
+			tracker.ReconcileFinished(nil)
+			Expect(tracker.LivezCheck(nil)).To(Succeed())
+		})
+	})
+
+	Describe("ServeHTTP", func() {
+		It("serves the last reconciliation of each component", func() {
+			started := time.Now()
+			tracker.ComponentReconciled("Namespace", started, nil)
+			tracker.ComponentReconciled("SharedResource", started, errors.New("forbidden"))
+			tracker.ComponentReconciled("Namespace", started, nil)
+
+			recorder := httptest.NewRecorder()
+			tracker.ServeHTTP(recorder, httptest.NewRequest("GET", "/debug/components", nil))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			statuses := []health.ComponentStatus{}
+			Expect(json.Unmarshal(recorder.Body.Bytes(), &statuses)).To(Succeed())
+			Expect(statuses).To(HaveLen(2))
+			Expect(statuses[0].Name).To(Equal("Namespace"))
+			Expect(statuses[0].Result).To(Equal(health.ResultSuccess))
+			Expect(statuses[1].Name).To(Equal("SharedResource"))
+			Expect(statuses[1].Result).To(Equal(health.ResultFailure))
+			Expect(statuses[1].Error).To(Equal("forbidden"))
+		})
+	})
+})
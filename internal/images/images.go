@@ -0,0 +1,210 @@
+package images
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// RelatedImagePrefix is the prefix of the environment variables overriding operand images
+const RelatedImagePrefix = "RELATED_IMAGE_"
+
+// digestPattern matches image references pinned by digest
+var digestPattern = regexp.MustCompile(`@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+
+// podSpecPaths holds the path of the pod spec in the workload kinds
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// strategyKinds holds the Shipwright build strategy kinds
+var strategyKinds = map[string]bool{
+	"BuildStrategy":        true,
+	"ClusterBuildStrategy": true,
+}
+
+// Key normalizes the name of a container, step, parameter or environment variable to the key
+// of an override, e.g. "git-container-image" and "GIT_CONTAINER_IMAGE" to "git_container_image".
+func Key(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "-", "_")
+}
+
+// IsPinned returns true if the image reference is pinned by digest
+func IsPinned(image string) bool {
+	return digestPattern.MatchString(image)
+}
+
+// Overrides replaces the operand images declared in the manifests
+type Overrides struct {
+	// Images maps the keys of containers, init containers, strategy steps, strategy parameters
+	// and environment variables to the image references replacing their value. A key qualified
+	// with the name of the object, e.g. "buildah_build_and_push", takes precedence over the
+	// unqualified key, e.g. "build_and_push".
+	Images map[string]string
+
+	// RequireDigests rejects the manifests holding images not pinned by digest.
+	RequireDigests bool
+}
+
+// New creates new instance of Overrides type
+func New(images map[string]string, requireDigests bool) *Overrides {
+	return &Overrides{
+		Images:         images,
+		RequireDigests: requireDigests,
+	}
+}
+
+// Transformer returns a manifestival.Transformer replacing the images of pod templates and build
+// strategies, and rejecting the images not pinned by digest if required. A nil Overrides returns
+// a no-op transformer.
+func (o *Overrides) Transformer() manifestival.Transformer {
+	return func(u *unstructured.Unstructured) error {
+		if o == nil {
+			return nil
+		}
+		if path, ok := podSpecPaths[u.GetKind()]; ok {
+			return o.transformPodSpec(u, path)
+		}
+		if strategyKinds[u.GetKind()] {
+			return o.transformStrategy(u)
+		}
+		return nil
+	}
+}
+
+// transformPodSpec replaces the images of the containers and init containers of a pod spec
+func (o *Overrides) transformPodSpec(u *unstructured.Unstructured, path []string) error {
+	errs := []error{}
+	for _, field := range []string{"initContainers", "containers"} {
+		fieldPath := append(append([]string{}, path...), field)
+		containers, found, err := unstructured.NestedSlice(u.Object, fieldPath...)
+		if err != nil {
+			return fmt.Errorf("%s %s: %v", u.GetKind(), u.GetName(), err)
+		}
+		if !found {
+			continue
+		}
+		for i := range containers {
+			container, ok := containers[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			errs = append(errs, o.transformContainer(u, container)...)
+		}
+		if err := unstructured.SetNestedSlice(u.Object, containers, fieldPath...); err != nil {
+			return fmt.Errorf("%s %s: %v", u.GetKind(), u.GetName(), err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// transformStrategy replaces the images of the steps and parameters of a build strategy
+func (o *Overrides) transformStrategy(u *unstructured.Unstructured) error {
+	errs := []error{}
+	steps, found, err := unstructured.NestedSlice(u.Object, "spec", "steps")
+	if err != nil {
+		return fmt.Errorf("%s %s: %v", u.GetKind(), u.GetName(), err)
+	}
+	if found {
+		for i := range steps {
+			step, ok := steps[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			errs = append(errs, o.transformContainer(u, step)...)
+		}
+		if err := unstructured.SetNestedSlice(u.Object, steps, "spec", "steps"); err != nil {
+			return fmt.Errorf("%s %s: %v", u.GetKind(), u.GetName(), err)
+		}
+	}
+
+	parameters, found, err := unstructured.NestedSlice(u.Object, "spec", "parameters")
+	if err != nil {
+		return fmt.Errorf("%s %s: %v", u.GetKind(), u.GetName(), err)
+	}
+	if found {
+		for i := range parameters {
+			parameter, ok := parameters[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := parameter["name"].(string)
+			image, overridden := o.lookup(u, name)
+			if overridden {
+				parameter["default"] = image
+			}
+			value, _ := parameter["default"].(string)
+			if overridden || (strings.HasSuffix(name, "-image") && value != "") {
+				errs = append(errs, o.check(u, "parameter", name, value)...)
+			}
+		}
+		if err := unstructured.SetNestedSlice(u.Object, parameters, "spec", "parameters"); err != nil {
+			return fmt.Errorf("%s %s: %v", u.GetKind(), u.GetName(), err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// transformContainer replaces the image and the image-valued environment variables of a
+// container or strategy step
+func (o *Overrides) transformContainer(u *unstructured.Unstructured, container map[string]interface{}) []error {
+	errs := []error{}
+	name, _ := container["name"].(string)
+	if image, ok := o.lookup(u, name); ok {
+		container["image"] = image
+	}
+	if image, ok := container["image"].(string); ok {
+		errs = append(errs, o.check(u, "container", name, image)...)
+	}
+
+	env, _ := container["env"].([]interface{})
+	for i := range env {
+		variable, ok := env[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		envName, _ := variable["name"].(string)
+		image, overridden := o.lookup(u, envName)
+		if overridden {
+			variable["value"] = image
+		}
+		value, hasValue := variable["value"].(string)
+		if overridden || (hasValue && value != "" && strings.HasSuffix(envName, "_IMAGE")) {
+			errs = append(errs, o.check(u, "environment variable", envName, value)...)
+		}
+	}
+	return errs
+}
+
+// lookup returns the override of the named container, step, parameter or environment variable
+func (o *Overrides) lookup(u *unstructured.Unstructured, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	if image, ok := o.Images[Key(u.GetName()+"_"+name)]; ok {
+		return image, true
+	}
+	image, ok := o.Images[Key(name)]
+	return image, ok
+}
+
+// check rejects the image if it is not pinned by digest and digests are required. References to
+// strategy parameters are resolved at build time, and are not checked.
+func (o *Overrides) check(u *unstructured.Unstructured, field, name, image string) []error {
+	if !o.RequireDigests || strings.Contains(image, "$(") || IsPinned(image) {
+		return nil
+	}
+	return []error{
+		fmt.Errorf("%s %s: %s %s: image %q is not pinned by digest", u.GetKind(), u.GetName(), field, name, image),
+	}
+}
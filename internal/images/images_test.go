@@ -0,0 +1,177 @@
+package images_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	controllerImage = "registry.example.com/builds/controller@sha256:484bc5e57f754d08dd06481cd573adf475c21211e1402d7c7913091a3659f0a4"
+	gitImage        = "registry.example.com/builds/git@sha256:0b8beff3346f4a16785f76ee3f87fd3e390e869faf7135730f2fb4e8d4a3cc30"
+	buildahImage    = "registry.example.com/ubi10/buildah@sha256:48c76ef9635739ad0c33645c2885ab6aa3036dca5883f475b820ac456d6817b6"
+	s2iImage        = "registry.example.com/s2i/s2i@sha256:798e0e860df8e3ca1ff5f8aa796940d6dbd4b739ccfc35c6f4c9bc046d5f0c12"
+	lifecycleImage  = "registry.example.com/buildpacksio/lifecycle@sha256:ccb838d655199bff6a77fc4296a2b2a44e00163dabd0ba8b0d9030f281ec935f"
+)
+
+const deployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: shipwright-build-controller
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: quay.io/example/init:v1
+      containers:
+      - name: shipwright-build
+        image: ghcr.io/shipwright-io/build/shipwright-build-controller:v0.18.0
+        env:
+        - name: GIT_CONTAINER_IMAGE
+          value: ghcr.io/shipwright-io/build/git:v0.18.0
+        - name: CONTROLLER_NAME
+          value: shipwright-build
+`
+
+const daemonSet = `
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: shared-resource-csi-driver-node
+spec:
+  template:
+    spec:
+      containers:
+      - name: hostpath
+        image: registry.redhat.io/openshift-builds/shared-resource:latest
+`
+
+const strategy = `
+apiVersion: shipwright.io/v1beta1
+kind: ClusterBuildStrategy
+metadata:
+  name: buildpacks
+spec:
+  parameters:
+  - name: cnb-lifecycle-image
+    default: buildpacksio/lifecycle:0.21.5
+  - name: cnb-log-level
+    default: debug
+  steps:
+  - name: build-and-push
+    image: registry.redhat.io/ubi10/buildah:latest
+  - name: analyze
+    image: $(params.cnb-lifecycle-image)
+`
+
+func parse(manifest string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	Expect(yaml.Unmarshal([]byte(manifest), &u.Object)).To(Succeed())
+	return u
+}
+
+var _ = Describe("Images", Label("images"), func() {
+
+	Describe("Key", func() {
+		It("normalizes names", func() {
+			Expect(images.Key("GIT_CONTAINER_IMAGE")).To(Equal("git_container_image"))
+			Expect(images.Key("build-and-push")).To(Equal("build_and_push"))
+		})
+	})
+
+	Describe("IsPinned", func() {
+		It("accepts references pinned by digest", func() {
+			Expect(images.IsPinned(controllerImage)).To(BeTrue())
+			Expect(images.IsPinned("ghcr.io/shipwright-io/build/git:v0.18.0@sha256:0b8beff3346f4a16785f76ee3f87fd3e390e869faf7135730f2fb4e8d4a3cc30")).To(BeTrue())
+		})
+
+		It("rejects references by tag", func() {
+			Expect(images.IsPinned("ghcr.io/shipwright-io/build/git:v0.18.0")).To(BeFalse())
+			Expect(images.IsPinned("ghcr.io/shipwright-io/build/git")).To(BeFalse())
+		})
+	})
+
+	Describe("Transformer", func() {
+		It("is a no-op for nil overrides", func() {
+			var overrides *images.Overrides
+			object := parse(deployment)
+			Expect(overrides.Transformer()(object)).To(Succeed())
+			Expect(object.Object).To(Equal(parse(deployment).Object))
+		})
+
+		It("replaces container, init container and environment variable images", func() {
+			overrides := images.New(map[string]string{
+				"shipwright_build":    controllerImage,
+				"init":                gitImage,
+				"git_container_image": gitImage,
+			}, false)
+			object := parse(deployment)
+			Expect(overrides.Transformer()(object)).To(Succeed())
+
+			initContainers, _, _ := unstructured.NestedSlice(object.Object, "spec", "template", "spec", "initContainers")
+			Expect(initContainers[0]).To(HaveKeyWithValue("image", gitImage))
+			containers, _, _ := unstructured.NestedSlice(object.Object, "spec", "template", "spec", "containers")
+			container := containers[0].(map[string]interface{})
+			Expect(container).To(HaveKeyWithValue("image", controllerImage))
+			env := container["env"].([]interface{})
+			Expect(env[0]).To(HaveKeyWithValue("value", gitImage))
+			Expect(env[1]).To(HaveKeyWithValue("value", "shipwright-build"))
+		})
+
+		It("replaces DaemonSet images", func() {
+			overrides := images.New(map[string]string{"hostpath": controllerImage}, false)
+			object := parse(daemonSet)
+			Expect(overrides.Transformer()(object)).To(Succeed())
+			containers, _, _ := unstructured.NestedSlice(object.Object, "spec", "template", "spec", "containers")
+			Expect(containers[0]).To(HaveKeyWithValue("image", controllerImage))
+		})
+
+		It("replaces strategy steps and parameters, preferring keys qualified by the object name", func() {
+			overrides := images.New(map[string]string{
+				"build_and_push":            s2iImage,
+				"buildpacks_build_and_push": buildahImage,
+				"cnb_lifecycle_image":       lifecycleImage,
+			}, false)
+			object := parse(strategy)
+			Expect(overrides.Transformer()(object)).To(Succeed())
+
+			steps, _, _ := unstructured.NestedSlice(object.Object, "spec", "steps")
+			Expect(steps[0]).To(HaveKeyWithValue("image", buildahImage))
+			Expect(steps[1]).To(HaveKeyWithValue("image", "$(params.cnb-lifecycle-image)"))
+			parameters, _, _ := unstructured.NestedSlice(object.Object, "spec", "parameters")
+			Expect(parameters[0]).To(HaveKeyWithValue("default", lifecycleImage))
+			Expect(parameters[1]).To(HaveKeyWithValue("default", "debug"))
+		})
+
+		When("digests are required", func() {
+			It("rejects images not pinned by digest", func() {
+				overrides := images.New(map[string]string{"shipwright_build": controllerImage}, true)
+				err := overrides.Transformer()(parse(deployment))
+				Expect(err).To(MatchError(ContainSubstring(`container init: image "quay.io/example/init:v1"`)))
+				Expect(err).To(MatchError(ContainSubstring(`environment variable GIT_CONTAINER_IMAGE: image "ghcr.io/shipwright-io/build/git:v0.18.0"`)))
+				Expect(err).NotTo(MatchError(ContainSubstring("container shipwright-build")))
+			})
+
+			It("rejects strategy parameters not pinned by digest", func() {
+				overrides := images.New(map[string]string{"build_and_push": buildahImage}, true)
+				err := overrides.Transformer()(parse(strategy))
+				Expect(err).To(MatchError(ContainSubstring("parameter cnb-lifecycle-image")))
+				Expect(err).NotTo(MatchError(ContainSubstring("analyze")))
+			})
+
+			It("accepts manifests with all the images pinned by digest", func() {
+				overrides := images.New(map[string]string{
+					"init":                gitImage,
+					"shipwright_build":    controllerImage,
+					"git_container_image": gitImage,
+				}, true)
+				Expect(overrides.Transformer()(parse(deployment))).To(Succeed())
+			})
+		})
+	})
+})
@@ -0,0 +1,77 @@
+package imagestreams
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BuilderImageParam is the strategy parameter holding the builder image, such as the builder
+// image of the source-to-image strategy
+const BuilderImageParam = "builder-image"
+
+// BuilderImageAnnotation is set on the BuildRuns to the ImageStreamTag their builder image was
+// resolved from, as "<namespace>/<imagestream>:<tag>"
+const BuilderImageAnnotation = "operator.openshift.io/builder-imagestreamtag"
+
+// BuilderNamespace is the namespace of the builder ImageStreams referenced without a namespace,
+// holding the builder images shipped with OpenShift
+const BuilderNamespace = "openshift"
+
+// ParseBuilderImage parses a builder image referencing an ImageStreamTag, in the form
+// "[<namespace>/]<imagestream>:<tag>". It returns nil for the other image references: references
+// by digest or without a tag, and references whose first segment is a registry host.
+func ParseBuilderImage(value string) *Tag {
+	if strings.Contains(value, "@") {
+		return nil
+	}
+	namespace := BuilderNamespace
+	ref := value
+	if ns, name, found := strings.Cut(value, "/"); found {
+		if ns == "localhost" || strings.ContainsAny(ns, ".:") || len(validation.IsDNS1123Label(ns)) > 0 {
+			return nil
+		}
+		namespace, ref = ns, name
+	}
+	if !strings.Contains(ref, ":") {
+		return nil
+	}
+	tag, err := ParseTag(namespace, ref)
+	if err != nil {
+		return nil
+	}
+	return tag
+}
+
+// ResolveBuilderImage returns the image of the tag pinned by digest. It returns an empty string
+// when the ImageStream does not exist or its kind is not served, and an error when the
+// ImageStream has no image for the tag.
+func ResolveBuilderImage(ctx context.Context, reader client.Reader, tag *Tag) (string, error) {
+	imageStream := &unstructured.Unstructured{}
+	imageStream.SetGroupVersionKind(ImageStreamGVK)
+	err := reader.Get(ctx, types.NamespacedName{Namespace: tag.Namespace, Name: tag.ImageStream}, imageStream)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	digest, reference, ok := ResolveTag(imageStream, tag.Tag)
+	if !ok {
+		return "", fmt.Errorf("ImageStream %s/%s has no image for tag %s", tag.Namespace, tag.ImageStream, tag.Tag)
+	}
+	// The pull specification of the tag may reference the image by tag
+	if name, _, found := strings.Cut(reference, "@"); found {
+		reference = name
+	} else if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference + "@" + digest, nil
+}
@@ -0,0 +1,81 @@
+package imagestreams_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Builder images", Label("imagestreams"), func() {
+	Describe("ParseBuilderImage", func() {
+		It("defaults the namespace to openshift", func() {
+			Expect(imagestreams.ParseBuilderImage("nodejs:20-ubi9")).To(Equal(
+				&imagestreams.Tag{Namespace: "openshift", ImageStream: "nodejs", Tag: "20-ubi9"}))
+		})
+
+		It("parses the namespace", func() {
+			Expect(imagestreams.ParseBuilderImage("team/builder:v1")).To(Equal(
+				&imagestreams.Tag{Namespace: "team", ImageStream: "builder", Tag: "v1"}))
+		})
+
+		It("ignores the other image references", func() {
+			for _, value := range []string{
+				"nodejs",
+				"registry.access.redhat.com/ubi9/openjdk-11",
+				"quay.io/centos7/nodejs-12-centos7:master",
+				"localhost/builder:v1",
+				"registry:5000/builder:v1",
+				"nodejs@sha256:2222",
+				"Invalid:v1",
+			} {
+				Expect(imagestreams.ParseBuilderImage(value)).To(BeNil(), value)
+			}
+		})
+	})
+
+	Describe("ResolveBuilderImage", func() {
+		var ctx context.Context
+		var imageStream *unstructured.Unstructured
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			imageStream = &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "image.openshift.io/v1",
+				"kind":       "ImageStream",
+				"metadata":   map[string]interface{}{"namespace": "openshift", "name": "nodejs"},
+				"status": map[string]interface{}{
+					"tags": []interface{}{
+						map[string]interface{}{"tag": "20-ubi9", "items": []interface{}{
+							map[string]interface{}{"image": "sha256:2222", "dockerImageReference": "registry.example.com/nodejs@sha256:2222"},
+						}},
+						map[string]interface{}{"tag": "18-ubi9", "items": []interface{}{}},
+					},
+				},
+			}}
+		})
+
+		resolve := func(tag string) (string, error) {
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(imageStream).Build()
+			return imagestreams.ResolveBuilderImage(ctx, c, imagestreams.ParseBuilderImage(tag))
+		}
+
+		It("pins the image of the tag by digest", func() {
+			Expect(resolve("nodejs:20-ubi9")).To(Equal("registry.example.com/nodejs@sha256:2222"))
+		})
+
+		It("fails when the tag has no image", func() {
+			_, err := resolve("nodejs:18-ubi9")
+			Expect(err).To(MatchError(ContainSubstring("has no image for tag 18-ubi9")))
+		})
+
+		It("returns nothing when the ImageStream does not exist", func() {
+			Expect(resolve("python:3.12-ubi9")).To(BeEmpty())
+		})
+	})
+})
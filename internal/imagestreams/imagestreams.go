@@ -0,0 +1,196 @@
+package imagestreams
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OutputAnnotation requests a Build or BuildRun to push its image to the given ImageStreamTag
+// of its namespace, in the form "<imagestream>[:<tag>]"
+const OutputAnnotation = "operator.openshift.io/output-imagestreamtag"
+
+// Annotations of the ImageStream tags updated by the operator
+const (
+	BuildRunAnnotation       = "shipwright.io/buildrun"
+	CompletionTimeAnnotation = "shipwright.io/completion-time"
+)
+
+// DefaultTag is the tag of the ImageStreamTag outputs which do not set one
+const DefaultTag = "latest"
+
+// DefaultServiceAccounts are the service accounts running the BuildRuns which do not set one, in
+// the order Shipwright looks them up
+var DefaultServiceAccounts = []string{"pipeline", "default"}
+
+// ImageStreamGVK is the kind of the OpenShift ImageStreams
+var ImageStreamGVK = schema.GroupVersionKind{Group: "image.openshift.io", Version: "v1", Kind: "ImageStream"}
+
+// Tag is an ImageStreamTag of a namespace
+type Tag struct {
+	Namespace   string
+	ImageStream string
+	Tag         string
+}
+
+// ParseTag parses the value of the OutputAnnotation
+func ParseTag(namespace, value string) (*Tag, error) {
+	name, tag, found := strings.Cut(value, ":")
+	if !found {
+		tag = DefaultTag
+	}
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return nil, fmt.Errorf("invalid ImageStream name %q: %s", name, strings.Join(msgs, ", "))
+	}
+	if tag == "" || strings.ContainsAny(tag, ":/@") {
+		return nil, fmt.Errorf("invalid ImageStream tag %q", tag)
+	}
+	return &Tag{Namespace: namespace, ImageStream: name, Tag: tag}, nil
+}
+
+func (t *Tag) String() string {
+	return t.ImageStream + ":" + t.Tag
+}
+
+// PullSpec returns the reference of the tag in the internal registry
+func (t *Tag) PullSpec() string {
+	return path.Join(common.InternalRegistryHostname, t.Namespace, t.String())
+}
+
+// PushSecret returns the secret holding the internal registry credentials of the service account
+// running the BuildRun, or an empty string if it has none.
+func PushSecret(ctx context.Context, reader client.Reader, namespace string, serviceAccount *string) (string, error) {
+	names := DefaultServiceAccounts
+	if serviceAccount != nil {
+		names = []string{*serviceAccount}
+	}
+	for _, name := range names {
+		object := &corev1.ServiceAccount{}
+		if err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, object); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return "", err
+		}
+		// The image registry credentials of service accounts are generated by OpenShift
+		for _, secret := range object.ImagePullSecrets {
+			if strings.HasPrefix(secret.Name, name+"-dockercfg-") {
+				return secret.Name, nil
+			}
+		}
+		return "", nil
+	}
+	return "", nil
+}
+
+// SetOutput pushes the image of the BuildRun to the tag, with the internal registry credentials
+// of its service account unless the output sets a push secret. The output of the Build is copied
+// to the BuildRun when the BuildRun does not override it.
+func SetOutput(ctx context.Context, reader client.Reader, buildRun *buildv1beta1.BuildRun, build *buildv1beta1.BuildSpec, tag *Tag) error {
+	output := buildRun.Spec.Output
+	if output == nil {
+		output = &buildv1beta1.Image{}
+		if build != nil {
+			output = build.Output.DeepCopy()
+		}
+		buildRun.Spec.Output = output
+	}
+	output.Image = tag.PullSpec()
+	if output.PushSecret == nil {
+		secret, err := PushSecret(ctx, reader, buildRun.Namespace, buildRun.Spec.ServiceAccount)
+		if err != nil {
+			return err
+		}
+		if secret != "" {
+			output.PushSecret = ptr.To(secret)
+		}
+	}
+
+	annotations := buildRun.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OutputAnnotation] = tag.String()
+	buildRun.SetAnnotations(annotations)
+	return nil
+}
+
+// TagImage creates the ImageStream if needed and points the tag to the image pushed by the
+// BuildRun. Tags updated by BuildRuns which completed later are left untouched, so that BuildRuns
+// can be processed in any order. It returns whether the ImageStream was changed.
+func TagImage(ctx context.Context, c client.Client, tag *Tag, buildRun *buildv1beta1.BuildRun) (bool, error) {
+	if buildRun.Status.Output == nil || buildRun.Status.Output.Digest == "" {
+		return false, fmt.Errorf("BuildRun %s/%s has no output digest", buildRun.Namespace, buildRun.Name)
+	}
+	completed := buildRun.Status.CompletionTime
+	if completed == nil {
+		return false, fmt.Errorf("BuildRun %s/%s has no completion time", buildRun.Namespace, buildRun.Name)
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(ImageStreamGVK)
+	err := c.Get(ctx, types.NamespacedName{Namespace: tag.Namespace, Name: tag.ImageStream}, object)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+	found := err == nil
+	if !found {
+		object.SetNamespace(tag.Namespace)
+		object.SetName(tag.ImageStream)
+	}
+
+	tags, _, err := unstructured.NestedSlice(object.Object, "spec", "tags")
+	if err != nil {
+		return false, err
+	}
+	desired := map[string]interface{}{
+		"name": tag.Tag,
+		"annotations": map[string]interface{}{
+			BuildRunAnnotation:       buildRun.Name,
+			CompletionTimeAnnotation: completed.UTC().Format(time.RFC3339),
+		},
+		"from": map[string]interface{}{
+			"kind": "ImageStreamImage",
+			"name": tag.ImageStream + "@" + buildRun.Status.Output.Digest,
+		},
+		"referencePolicy": map[string]interface{}{"type": "Source"},
+	}
+	index := -1
+	for i, item := range tags {
+		existing, ok := item.(map[string]interface{})
+		if !ok || existing["name"] != tag.Tag {
+			continue
+		}
+		index = i
+		last, _, _ := unstructured.NestedString(existing, "annotations", CompletionTimeAnnotation)
+		if lastTime, err := time.Parse(time.RFC3339, last); err == nil && !completed.UTC().Truncate(time.Second).After(lastTime) {
+			return false, nil
+		}
+	}
+	if index < 0 {
+		tags = append(tags, desired)
+	} else {
+		tags[index] = desired
+	}
+	if err := unstructured.SetNestedSlice(object.Object, tags, "spec", "tags"); err != nil {
+		return false, err
+	}
+
+	if !found {
+		return true, c.Create(ctx, object)
+	}
+	return true, c.Update(ctx, object)
+}
@@ -0,0 +1,13 @@
+package imagestreams_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestImageStreams(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ImageStreams Suite")
+}
@@ -0,0 +1,177 @@
+package imagestreams_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("ImageStreams", Label("imagestreams"), func() {
+	var ctx context.Context
+
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
+	Describe("ParseTag", func() {
+		It("parses the ImageStream and tag", func() {
+			tag, err := imagestreams.ParseTag("test", "app:v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*tag).To(Equal(imagestreams.Tag{Namespace: "test", ImageStream: "app", Tag: "v1"}))
+			Expect(tag.PullSpec()).To(Equal("image-registry.openshift-image-registry.svc:5000/test/app:v1"))
+		})
+
+		It("defaults the tag", func() {
+			tag, err := imagestreams.ParseTag("test", "app")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tag.Tag).To(Equal(imagestreams.DefaultTag))
+		})
+
+		It("rejects invalid values", func() {
+			for _, value := range []string{"", "App:v1", "app:", "app:v1:v2", "other/app:v1"} {
+				_, err := imagestreams.ParseTag("test", value)
+				Expect(err).To(HaveOccurred(), value)
+			}
+		})
+	})
+
+	Describe("SetOutput", func() {
+		var (
+			buildRun *buildv1beta1.BuildRun
+			objects  []client.Object
+			tag      *imagestreams.Tag
+		)
+
+		serviceAccount := func(name string, pullSecrets ...string) *corev1.ServiceAccount {
+			object := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name}}
+			for _, secret := range pullSecrets {
+				object.ImagePullSecrets = append(object.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+			}
+			return object
+		}
+
+		BeforeEach(func() {
+			buildRun = &buildv1beta1.BuildRun{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"}}
+			objects = nil
+			tag = &imagestreams.Tag{Namespace: "test", ImageStream: "app", Tag: "latest"}
+		})
+
+		setOutput := func(build *buildv1beta1.BuildSpec) {
+			reader := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+			Expect(imagestreams.SetOutput(ctx, reader, buildRun, build, tag)).To(Succeed())
+		}
+
+		It("uses the credentials of the pipeline service account", func() {
+			objects = append(objects,
+				serviceAccount("pipeline", "registry-credentials", "pipeline-dockercfg-abcde"),
+				serviceAccount("default", "default-dockercfg-fghij"),
+			)
+			build := &buildv1beta1.BuildSpec{Output: buildv1beta1.Image{Image: "placeholder", Labels: map[string]string{"team": "web"}}}
+			setOutput(build)
+			Expect(buildRun.Spec.Output.Image).To(Equal(tag.PullSpec()))
+			Expect(buildRun.Spec.Output.PushSecret).To(Equal(ptr.To("pipeline-dockercfg-abcde")))
+			Expect(buildRun.Spec.Output.Labels).To(HaveKeyWithValue("team", "web"))
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(imagestreams.OutputAnnotation, "app:latest"))
+			Expect(build.Output.Image).To(Equal("placeholder"))
+		})
+
+		It("falls back to the default service account", func() {
+			objects = append(objects, serviceAccount("default", "default-dockercfg-fghij"))
+			setOutput(nil)
+			Expect(buildRun.Spec.Output.PushSecret).To(Equal(ptr.To("default-dockercfg-fghij")))
+		})
+
+		It("uses the service account of the BuildRun", func() {
+			objects = append(objects, serviceAccount("pipeline", "pipeline-dockercfg-abcde"), serviceAccount("builder", "builder-dockercfg-klmno"))
+			buildRun.Spec.ServiceAccount = ptr.To("builder")
+			setOutput(nil)
+			Expect(buildRun.Spec.Output.PushSecret).To(Equal(ptr.To("builder-dockercfg-klmno")))
+		})
+
+		It("keeps the push secret of the BuildRun", func() {
+			objects = append(objects, serviceAccount("pipeline", "pipeline-dockercfg-abcde"))
+			buildRun.Spec.Output = &buildv1beta1.Image{PushSecret: ptr.To("mine")}
+			setOutput(nil)
+			Expect(buildRun.Spec.Output.Image).To(Equal(tag.PullSpec()))
+			Expect(buildRun.Spec.Output.PushSecret).To(Equal(ptr.To("mine")))
+		})
+	})
+
+	Describe("TagImage", func() {
+		var (
+			c   client.Client
+			tag *imagestreams.Tag
+		)
+
+		buildRun := func(name, digest string, completed time.Time) *buildv1beta1.BuildRun {
+			return &buildv1beta1.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: name},
+				Status: buildv1beta1.BuildRunStatus{
+					Output:         &buildv1beta1.Output{Digest: digest},
+					CompletionTime: &metav1.Time{Time: completed},
+				},
+			}
+		}
+
+		specTags := func() []interface{} {
+			object := &unstructured.Unstructured{}
+			object.SetGroupVersionKind(imagestreams.ImageStreamGVK)
+			Expect(c.Get(ctx, types.NamespacedName{Namespace: "test", Name: "app"}, object)).To(Succeed())
+			tags, _, err := unstructured.NestedSlice(object.Object, "spec", "tags")
+			Expect(err).NotTo(HaveOccurred())
+			return tags
+		}
+
+		BeforeEach(func() {
+			c = fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+			tag = &imagestreams.Tag{Namespace: "test", ImageStream: "app", Tag: "latest"}
+		})
+
+		It("creates the ImageStream and updates the tag with newer BuildRuns only", func() {
+			now := time.Now()
+			changed, err := imagestreams.TagImage(ctx, c, tag, buildRun("app-2", "sha256:2222", now))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			tags := specTags()
+			Expect(tags).To(HaveLen(1))
+			Expect(tags[0]).To(HaveKeyWithValue("from", map[string]interface{}{"kind": "ImageStreamImage", "name": "app@sha256:2222"}))
+
+			By("ignoring older BuildRuns")
+			changed, err = imagestreams.TagImage(ctx, c, tag, buildRun("app-1", "sha256:1111", now.Add(-time.Minute)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeFalse())
+
+			By("updating the tag with newer BuildRuns")
+			changed, err = imagestreams.TagImage(ctx, c, tag, buildRun("app-3", "sha256:3333", now.Add(time.Minute)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			tags = specTags()
+			Expect(tags).To(HaveLen(1))
+			Expect(tags[0]).To(HaveKeyWithValue("from", map[string]interface{}{"kind": "ImageStreamImage", "name": "app@sha256:3333"}))
+
+			By("adding other tags")
+			tag.Tag = "stable"
+			_, err = imagestreams.TagImage(ctx, c, tag, buildRun("app-3", "sha256:3333", now.Add(time.Minute)))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(specTags()).To(HaveLen(2))
+		})
+
+		It("requires the output digest", func() {
+			_, err := imagestreams.TagImage(ctx, c, tag, buildRun("app-1", "", time.Now()))
+			Expect(err).To(MatchError(ContainSubstring("has no output digest")))
+		})
+	})
+})
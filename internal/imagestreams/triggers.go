@@ -0,0 +1,142 @@
+package imagestreams
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Annotations of the Builds rebuilt when the ImageStreamTags they use change
+const (
+	// TriggersAnnotation lists the ImageStreamTags triggering the Build, separated by commas, in
+	// the form "[<namespace>/]<imagestream>[:<tag>][=<parameter>]". The image of the tag is passed
+	// to the named strategy parameter of the triggered BuildRuns, if any.
+	TriggersAnnotation = "operator.openshift.io/imagestream-triggers"
+
+	// TriggersStatusAnnotation records the images which last triggered the Build, as a JSON list
+	// of TriggerStatus.
+	TriggersStatusAnnotation = "operator.openshift.io/imagestream-triggers-status"
+
+	// TriggeredByAnnotation is set on the triggered BuildRuns to the images which changed.
+	TriggeredByAnnotation = "operator.openshift.io/triggered-by"
+)
+
+// Trigger is an ImageStreamTag triggering a Build
+type Trigger struct {
+	Tag
+
+	// Param is the strategy parameter receiving the image of the tag, if any.
+	Param string
+}
+
+// From returns the reference of the tag recorded in the trigger status
+func (t *Trigger) From() string {
+	return t.Namespace + "/" + t.Tag.String()
+}
+
+// TriggerStatus is the image which last triggered a Build
+type TriggerStatus struct {
+	// From is the triggering ImageStreamTag, as "<namespace>/<imagestream>:<tag>".
+	From string `json:"from"`
+
+	// LastTriggeredImageID is the digest of the image which last triggered the Build.
+	LastTriggeredImageID string `json:"lastTriggeredImageID"`
+
+	// BuildRun is the name of the BuildRun created by the trigger, empty when the image was
+	// recorded without triggering a build.
+	BuildRun string `json:"buildRun,omitempty"`
+
+	// Time is the time the image was recorded.
+	Time metav1.Time `json:"time"`
+}
+
+// ParseTriggers parses the value of the TriggersAnnotation of a Build of the namespace
+func ParseTriggers(namespace, value string) ([]Trigger, error) {
+	triggers := []Trigger{}
+	errs := []error{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		ref, param, _ := strings.Cut(item, "=")
+		tagNamespace := namespace
+		if ns, name, found := strings.Cut(ref, "/"); found {
+			if msgs := validation.IsDNS1123Label(ns); len(msgs) > 0 {
+				errs = append(errs, fmt.Errorf("%s: invalid namespace %q: %s", item, ns, strings.Join(msgs, ", ")))
+				continue
+			}
+			tagNamespace, ref = ns, name
+		}
+		tag, err := ParseTag(tagNamespace, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", item, err))
+			continue
+		}
+		triggers = append(triggers, Trigger{Tag: *tag, Param: param})
+	}
+	if len(triggers) == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("no ImageStreamTag"))
+	}
+	return triggers, errors.Join(errs...)
+}
+
+// References returns whether one of the triggers is a tag of the ImageStream
+func References(triggers []Trigger, namespace, name string) bool {
+	return slices.ContainsFunc(triggers, func(t Trigger) bool {
+		return t.Namespace == namespace && t.ImageStream == name
+	})
+}
+
+// ParseTriggersStatus parses the value of the TriggersStatusAnnotation, ignoring invalid values
+func ParseTriggersStatus(value string) []TriggerStatus {
+	status := []TriggerStatus{}
+	if value != "" {
+		if err := json.Unmarshal([]byte(value), &status); err != nil {
+			return []TriggerStatus{}
+		}
+	}
+	return status
+}
+
+// ResolveTag returns the digest and the pull specification of the current image of the tag in
+// the ImageStream status, or false if the tag has no image yet
+func ResolveTag(imageStream *unstructured.Unstructured, tag string) (string, string, bool) {
+	tags, _, _ := unstructured.NestedSlice(imageStream.Object, "status", "tags")
+	for _, item := range tags {
+		event, ok := item.(map[string]interface{})
+		if !ok || event["tag"] != tag {
+			continue
+		}
+		items, _, _ := unstructured.NestedSlice(event, "items")
+		if len(items) == 0 {
+			return "", "", false
+		}
+		latest, ok := items[0].(map[string]interface{})
+		if !ok {
+			return "", "", false
+		}
+		image, _, _ := unstructured.NestedString(latest, "image")
+		reference, _, _ := unstructured.NestedString(latest, "dockerImageReference")
+		return image, reference, image != ""
+	}
+	return "", "", false
+}
+
+// BuildRunName returns the name of the BuildRun triggered by the images, which is the same for
+// the same images so that a change triggers a single BuildRun
+func BuildRunName(build string, images []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(images, ",")))
+	if len(build) > 52 {
+		build = strings.TrimRight(build[:52], "-.")
+	}
+	return build + "-" + hex.EncodeToString(sum[:])[:10]
+}
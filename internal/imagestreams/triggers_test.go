@@ -0,0 +1,80 @@
+package imagestreams_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Triggers", Label("imagestreams"), func() {
+	Describe("ParseTriggers", func() {
+		It("parses the tags, namespaces and parameters", func() {
+			triggers, err := imagestreams.ParseTriggers("test", "base, openshift/nodejs:20=builder-image")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(triggers).To(Equal([]imagestreams.Trigger{
+				{Tag: imagestreams.Tag{Namespace: "test", ImageStream: "base", Tag: "latest"}},
+				{Tag: imagestreams.Tag{Namespace: "openshift", ImageStream: "nodejs", Tag: "20"}, Param: "builder-image"},
+			}))
+			Expect(triggers[1].From()).To(Equal("openshift/nodejs:20"))
+			Expect(imagestreams.References(triggers, "openshift", "nodejs")).To(BeTrue())
+			Expect(imagestreams.References(triggers, "test", "nodejs")).To(BeFalse())
+		})
+
+		It("returns the valid triggers and the errors", func() {
+			triggers, err := imagestreams.ParseTriggers("test", "base,Invalid/app,app:")
+			Expect(err).To(HaveOccurred())
+			Expect(triggers).To(HaveLen(1))
+		})
+
+		It("requires a trigger", func() {
+			_, err := imagestreams.ParseTriggers("test", " ")
+			Expect(err).To(MatchError(ContainSubstring("no ImageStreamTag")))
+		})
+	})
+
+	Describe("ResolveTag", func() {
+		It("returns the current image of the tag", func() {
+			imageStream := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"tags": []interface{}{
+						map[string]interface{}{"tag": "19", "items": []interface{}{}},
+						map[string]interface{}{"tag": "20", "items": []interface{}{
+							map[string]interface{}{"image": "sha256:2222", "dockerImageReference": "registry.example.com/nodejs@sha256:2222"},
+							map[string]interface{}{"image": "sha256:1111", "dockerImageReference": "registry.example.com/nodejs@sha256:1111"},
+						}},
+					},
+				},
+			}}
+			image, reference, ok := imagestreams.ResolveTag(imageStream, "20")
+			Expect(ok).To(BeTrue())
+			Expect(image).To(Equal("sha256:2222"))
+			Expect(reference).To(Equal("registry.example.com/nodejs@sha256:2222"))
+
+			_, _, ok = imagestreams.ResolveTag(imageStream, "19")
+			Expect(ok).To(BeFalse())
+			_, _, ok = imagestreams.ResolveTag(imageStream, "18")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("BuildRunName", func() {
+		It("derives a valid name from the images", func() {
+			name := imagestreams.BuildRunName("app", []string{"test/base:latest@sha256:1111"})
+			Expect(name).To(MatchRegexp(`^app-[0-9a-f]{10}$`))
+			Expect(imagestreams.BuildRunName("app", []string{"test/base:latest@sha256:1111"})).To(Equal(name))
+			Expect(imagestreams.BuildRunName("app", []string{"test/base:latest@sha256:2222"})).NotTo(Equal(name))
+			Expect(len(imagestreams.BuildRunName(strings.Repeat("a", 100), nil))).To(BeNumerically("<=", 63))
+		})
+	})
+
+	Describe("ParseTriggersStatus", func() {
+		It("ignores invalid values", func() {
+			Expect(imagestreams.ParseTriggersStatus("not json")).To(BeEmpty())
+			Expect(imagestreams.ParseTriggersStatus(`[{"from":"test/base:latest","lastTriggeredImageID":"sha256:1111","time":null}]`)).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Apply creates the converted objects, or updates them if they were converted from the same
+// BuildConfig earlier. Objects of the same name which were not converted from the BuildConfig
+// are left untouched and reported as an error.
+func Apply(ctx context.Context, c client.Client, result *Result) error {
+	if result.BuildStrategy != nil {
+		existing := &buildv1beta1.BuildStrategy{}
+		err := apply(ctx, c, result.BuildStrategy, existing, func() {
+			existing.Spec = result.BuildStrategy.Spec
+		})
+		if err != nil {
+			return err
+		}
+	}
+	existing := &buildv1beta1.Build{}
+	return apply(ctx, c, result.Build, existing, func() {
+		existing.Spec = result.Build.Spec
+	})
+}
+
+// apply creates the desired object, or updates the existing one with the mutate function
+func apply(ctx context.Context, c client.Client, desired, existing client.Object, mutate func()) error {
+	kind := desired.GetObjectKind().GroupVersionKind().Kind
+	err := c.Get(ctx, client.ObjectKeyFromObject(desired), existing)
+	if apierrors.IsNotFound(err) {
+		if err := c.Create(ctx, desired.DeepCopyObject().(client.Object)); err != nil {
+			return fmt.Errorf("failed to create %s %s/%s: %v", kind, desired.GetNamespace(), desired.GetName(), err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	from := desired.GetAnnotations()[ConvertedFromAnnotation]
+	if existing.GetAnnotations()[ConvertedFromAnnotation] != from {
+		return fmt.Errorf("%s %s/%s already exists and was not converted from the BuildConfig %s", kind, desired.GetNamespace(), desired.GetName(), from)
+	}
+	mutate()
+	if err := c.Update(ctx, existing); err != nil {
+		return fmt.Errorf("failed to update %s %s/%s: %v", kind, desired.GetNamespace(), desired.GetName(), err)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+// Package migration converts OpenShift BuildConfigs to Shipwright Builds.
+//
+// +kubebuilder:skip
+package migration
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// BuildConfigGVK is the kind of the OpenShift BuildConfigs
+var BuildConfigGVK = schema.GroupVersionKind{Group: "build.openshift.io", Version: "v1", Kind: "BuildConfig"}
+
+// The types below hold the subset of the build.openshift.io/v1 API read by the converter.
+
+// BuildConfig is an OpenShift build configuration
+type BuildConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BuildConfigSpec `json:"spec,omitempty"`
+}
+
+// BuildConfigSpec is the specification of a BuildConfig
+type BuildConfigSpec struct {
+	Triggers                     []BuildTriggerPolicy        `json:"triggers,omitempty"`
+	RunPolicy                    string                      `json:"runPolicy,omitempty"`
+	ServiceAccount               string                      `json:"serviceAccount,omitempty"`
+	Source                       BuildSource                 `json:"source,omitempty"`
+	Strategy                     BuildStrategy               `json:"strategy,omitempty"`
+	Output                       BuildOutput                 `json:"output,omitempty"`
+	Resources                    corev1.ResourceRequirements `json:"resources,omitempty"`
+	PostCommit                   BuildPostCommitSpec         `json:"postCommit,omitempty"`
+	CompletionDeadlineSeconds    *int64                      `json:"completionDeadlineSeconds,omitempty"`
+	NodeSelector                 map[string]string           `json:"nodeSelector,omitempty"`
+	MountTrustedCA               *bool                       `json:"mountTrustedCA,omitempty"`
+	SuccessfulBuildsHistoryLimit *int32                      `json:"successfulBuildsHistoryLimit,omitempty"`
+	FailedBuildsHistoryLimit     *int32                      `json:"failedBuildsHistoryLimit,omitempty"`
+}
+
+// BuildTriggerPolicy is a trigger of a BuildConfig
+type BuildTriggerPolicy struct {
+	Type string `json:"type"`
+}
+
+// BuildSource is the input of a build
+type BuildSource struct {
+	Type         string                       `json:"type,omitempty"`
+	Binary       *BinaryBuildSource           `json:"binary,omitempty"`
+	Dockerfile   *string                      `json:"dockerfile,omitempty"`
+	Git          *GitBuildSource              `json:"git,omitempty"`
+	Images       []runtime.RawExtension       `json:"images,omitempty"`
+	ContextDir   string                       `json:"contextDir,omitempty"`
+	SourceSecret *corev1.LocalObjectReference `json:"sourceSecret,omitempty"`
+	Secrets      []runtime.RawExtension       `json:"secrets,omitempty"`
+	ConfigMaps   []runtime.RawExtension       `json:"configMaps,omitempty"`
+}
+
+// BinaryBuildSource is a source uploaded when the build is started
+type BinaryBuildSource struct {
+	AsFile string `json:"asFile,omitempty"`
+}
+
+// GitBuildSource is a source cloned from a git repository
+type GitBuildSource struct {
+	URI        string  `json:"uri"`
+	Ref        string  `json:"ref,omitempty"`
+	HTTPProxy  *string `json:"httpProxy,omitempty"`
+	HTTPSProxy *string `json:"httpsProxy,omitempty"`
+	NoProxy    *string `json:"noProxy,omitempty"`
+}
+
+// BuildStrategy is the strategy of a build
+type BuildStrategy struct {
+	Type                    string                `json:"type,omitempty"`
+	DockerStrategy          *DockerBuildStrategy  `json:"dockerStrategy,omitempty"`
+	SourceStrategy          *SourceBuildStrategy  `json:"sourceStrategy,omitempty"`
+	CustomStrategy          *CustomBuildStrategy  `json:"customStrategy,omitempty"`
+	JenkinsPipelineStrategy *runtime.RawExtension `json:"jenkinsPipelineStrategy,omitempty"`
+}
+
+// DockerBuildStrategy builds an image from a Dockerfile
+type DockerBuildStrategy struct {
+	From                    *corev1.ObjectReference      `json:"from,omitempty"`
+	PullSecret              *corev1.LocalObjectReference `json:"pullSecret,omitempty"`
+	NoCache                 bool                         `json:"noCache,omitempty"`
+	Env                     []corev1.EnvVar              `json:"env,omitempty"`
+	ForcePull               bool                         `json:"forcePull,omitempty"`
+	DockerfilePath          string                       `json:"dockerfilePath,omitempty"`
+	BuildArgs               []corev1.EnvVar              `json:"buildArgs,omitempty"`
+	ImageOptimizationPolicy *string                      `json:"imageOptimizationPolicy,omitempty"`
+	Volumes                 []runtime.RawExtension       `json:"volumes,omitempty"`
+}
+
+// SourceBuildStrategy builds an image with a Source-to-Image builder image
+type SourceBuildStrategy struct {
+	From        corev1.ObjectReference       `json:"from"`
+	PullSecret  *corev1.LocalObjectReference `json:"pullSecret,omitempty"`
+	Env         []corev1.EnvVar              `json:"env,omitempty"`
+	Scripts     string                       `json:"scripts,omitempty"`
+	Incremental *bool                        `json:"incremental,omitempty"`
+	ForcePull   bool                         `json:"forcePull,omitempty"`
+	Volumes     []runtime.RawExtension       `json:"volumes,omitempty"`
+}
+
+// CustomBuildStrategy builds an image with a custom builder image
+type CustomBuildStrategy struct {
+	From               corev1.ObjectReference       `json:"from"`
+	PullSecret         *corev1.LocalObjectReference `json:"pullSecret,omitempty"`
+	Env                []corev1.EnvVar              `json:"env,omitempty"`
+	ExposeDockerSocket bool                         `json:"exposeDockerSocket,omitempty"`
+	ForcePull          bool                         `json:"forcePull,omitempty"`
+	Secrets            []runtime.RawExtension       `json:"secrets,omitempty"`
+}
+
+// BuildOutput is the destination of the built image
+type BuildOutput struct {
+	To          *corev1.ObjectReference      `json:"to,omitempty"`
+	PushSecret  *corev1.LocalObjectReference `json:"pushSecret,omitempty"`
+	ImageLabels []ImageLabel                 `json:"imageLabels,omitempty"`
+}
+
+// ImageLabel is a label applied to the built image
+type ImageLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// BuildPostCommitSpec is a hook run after the image is built
+type BuildPostCommitSpec struct {
+	Command []string `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Script  string   `json:"script,omitempty"`
+}
+
+// FromUnstructured converts an unstructured BuildConfig
+func FromUnstructured(object *unstructured.Unstructured) (*BuildConfig, error) {
+	buildConfig := &BuildConfig{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, buildConfig); err != nil {
+		return nil, err
+	}
+	return buildConfig, nil
+}
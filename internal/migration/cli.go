@@ -0,0 +1,225 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// CommandName is the name of the operator subcommand converting BuildConfigs
+const CommandName = "convert-buildconfig"
+
+// Command converts BuildConfigs read from files or from the cluster, and prints the converted
+// objects as YAML documents. Conversion issues are printed to Stderr.
+type Command struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// NewClient returns the client reading the BuildConfigs from the cluster and applying the
+	// converted objects. It is only called when the cluster is accessed.
+	NewClient func() (client.Client, error)
+}
+
+// Run runs the command with the given arguments and returns its exit code: 0 when all the
+// BuildConfigs were converted, 1 when some could not be converted or applied, and 2 when the
+// arguments are invalid.
+func (c *Command) Run(ctx context.Context, args []string) int {
+	fs := flag.NewFlagSet(CommandName, flag.ContinueOnError)
+	fs.SetOutput(c.Stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(c.Stderr, "Usage: %s [flags] [BUILDCONFIG...]\n\n", CommandName)
+		fmt.Fprintln(c.Stderr, "Converts OpenShift BuildConfigs to Shipwright Builds, read from files or from the cluster.")
+		fs.PrintDefaults()
+	}
+	var filename, namespace string
+	var allNamespaces, apply bool
+	fs.StringVar(&filename, "f", "", "File holding the BuildConfigs as YAML or JSON, or - for the standard input.")
+	fs.StringVar(&namespace, "n", "", "Namespace of the BuildConfigs read from the cluster.")
+	fs.BoolVar(&allNamespaces, "A", false, "Read the BuildConfigs of all the namespaces from the cluster.")
+	fs.BoolVar(&apply, "apply", false, "Create or update the converted objects in the cluster instead of printing them.")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	names := fs.Args()
+	if filename != "" && (namespace != "" || allNamespaces || len(names) > 0) {
+		fmt.Fprintln(c.Stderr, "error: -f cannot be combined with -n, -A or BuildConfig names")
+		return 2
+	}
+	if filename == "" && namespace == "" && !allNamespaces {
+		fmt.Fprintln(c.Stderr, "error: one of -f, -n or -A is required")
+		return 2
+	}
+	if allNamespaces && len(names) > 0 {
+		fmt.Fprintln(c.Stderr, "error: BuildConfig names require -n")
+		return 2
+	}
+
+	var cl client.Client
+	if filename == "" || apply {
+		var err error
+		if cl, err = c.NewClient(); err != nil {
+			fmt.Fprintf(c.Stderr, "error: %v\n", err)
+			return 1
+		}
+	}
+
+	var objects []unstructured.Unstructured
+	var err error
+	if filename != "" {
+		objects, err = c.read(filename)
+	} else {
+		objects, err = list(ctx, cl, namespace, names)
+	}
+	if err != nil {
+		fmt.Fprintf(c.Stderr, "error: %v\n", err)
+		return 1
+	}
+
+	code := 0
+	first := true
+	for i := range objects {
+		object := &objects[i]
+		if object.GroupVersionKind().GroupKind() != BuildConfigGVK.GroupKind() {
+			fmt.Fprintf(c.Stderr, "skipping %s %s: not a BuildConfig\n", object.GetKind(), object.GetName())
+			continue
+		}
+		buildConfig, err := FromUnstructured(object)
+		if err == nil {
+			err = c.convert(ctx, cl, buildConfig, apply, &first)
+		}
+		if err != nil {
+			fmt.Fprintf(c.Stderr, "error: %v\n", err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// convert converts the BuildConfig, reports the issues, and prints or applies the result
+func (c *Command) convert(ctx context.Context, cl client.Client, buildConfig *BuildConfig, apply bool, first *bool) error {
+	result, err := Convert(buildConfig)
+	if err != nil {
+		return err
+	}
+	for _, issue := range result.Issues {
+		fmt.Fprintf(c.Stderr, "BuildConfig %s/%s: %s\n", buildConfig.Namespace, buildConfig.Name, issue)
+	}
+	if apply {
+		if err := Apply(ctx, cl, result); err != nil {
+			return err
+		}
+		fmt.Fprintf(c.Stdout, "build.shipwright.io/%s converted from BuildConfig %s/%s\n", result.Build.Name, buildConfig.Namespace, buildConfig.Name)
+		return nil
+	}
+
+	objects := []runtime.Object{}
+	if result.BuildStrategy != nil {
+		objects = append(objects, result.BuildStrategy)
+	}
+	objects = append(objects, result.Build)
+	for _, object := range objects {
+		data, err := toYAML(object)
+		if err != nil {
+			return err
+		}
+		if !*first {
+			fmt.Fprintln(c.Stdout, "---")
+		}
+		*first = false
+		if _, err := c.Stdout.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// read decodes the objects of a YAML or JSON file, which may hold several documents and lists
+func (c *Command) read(filename string) ([]unstructured.Unstructured, error) {
+	var data []byte
+	var err error
+	if filename == "-" {
+		data, err = io.ReadAll(c.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []unstructured.Unstructured{}
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, fmt.Errorf("failed to decode %s: %v", filename, err)
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		if !object.IsList() {
+			objects = append(objects, *object)
+			continue
+		}
+		items, err := object.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", filename, err)
+		}
+		objects = append(objects, items.Items...)
+	}
+}
+
+// list reads the BuildConfigs from the cluster, all of the namespace unless names are given
+func list(ctx context.Context, cl client.Client, namespace string, names []string) ([]unstructured.Unstructured, error) {
+	if len(names) == 0 {
+		objects := &unstructured.UnstructuredList{}
+		objects.SetGroupVersionKind(BuildConfigGVK.GroupVersion().WithKind(BuildConfigGVK.Kind + "List"))
+		if err := cl.List(ctx, objects, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list BuildConfigs: %v", err)
+		}
+		return objects.Items, nil
+	}
+	objects := make([]unstructured.Unstructured, 0, len(names))
+	for _, name := range names {
+		object := unstructured.Unstructured{}
+		object.SetGroupVersionKind(BuildConfigGVK)
+		if err := cl.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &object); err != nil {
+			return nil, fmt.Errorf("failed to get BuildConfig %s: %v", name, err)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// toYAML marshals the object without its status and server populated metadata
+func toYAML(object runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+	data, err := yaml.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimSpace(string(data)) + "\n"), nil
+}
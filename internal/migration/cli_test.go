@@ -0,0 +1,130 @@
+package migration_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/migration"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Command", Label("migration"), func() {
+	var (
+		ctx            context.Context
+		stdout, stderr *bytes.Buffer
+		command        *migration.Command
+		fakeClient     client.Client
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+		scheme := runtime.NewScheme()
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		command = &migration.Command{
+			Stdin:  strings.NewReader(""),
+			Stdout: stdout,
+			Stderr: stderr,
+			NewClient: func() (client.Client, error) {
+				return fakeClient, nil
+			},
+		}
+	})
+
+	createBuildConfig := func(content string) {
+		object := &unstructured.Unstructured{}
+		Expect(yaml.Unmarshal([]byte(content), &object.Object)).To(Succeed())
+		Expect(fakeClient.Create(ctx, object)).To(Succeed())
+	}
+
+	When("the BuildConfigs are read from a file", func() {
+		It("should print the converted objects and report the issues", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "buildconfigs.yaml")
+			content := dockerBuildConfig + "---\n" + customBuildConfig + "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n"
+			Expect(os.WriteFile(path, []byte(content), 0o600)).To(Succeed())
+
+			Expect(command.Run(ctx, []string{"-f", path})).To(Equal(0))
+			documents := strings.Split(stdout.String(), "---\n")
+			Expect(documents).To(HaveLen(3))
+			Expect(documents[0]).To(ContainSubstring("name: frontend"))
+			Expect(documents[0]).NotTo(ContainSubstring("status:"))
+			Expect(documents[1]).To(ContainSubstring("kind: BuildStrategy"))
+			Expect(documents[2]).To(ContainSubstring("name: legacy"))
+			Expect(stderr.String()).To(ContainSubstring("BuildConfig shop/frontend: spec.triggers[1]: GitHub triggers are not converted"))
+			Expect(stderr.String()).To(ContainSubstring("skipping ConfigMap other"))
+		})
+
+		It("should read lists from the standard input", func() {
+			list := &unstructured.Unstructured{}
+			list.SetAPIVersion("v1")
+			list.SetKind("List")
+			item := map[string]interface{}{}
+			Expect(yaml.Unmarshal([]byte(sourceBuildConfig), &item)).To(Succeed())
+			list.Object["items"] = []interface{}{item}
+			data, err := list.MarshalJSON()
+			Expect(err).NotTo(HaveOccurred())
+			command.Stdin = bytes.NewReader(data)
+
+			Expect(command.Run(ctx, []string{"-f", "-"})).To(Equal(0))
+			Expect(stdout.String()).To(ContainSubstring("name: backend"))
+		})
+
+		It("should fail when a BuildConfig cannot be converted", func() {
+			command.Stdin = strings.NewReader(strings.Replace(dockerBuildConfig, "  output:\n", "  unused:\n", 1))
+			Expect(command.Run(ctx, []string{"-f", "-"})).To(Equal(1))
+			Expect(stderr.String()).To(ContainSubstring("builds without output image are not supported"))
+		})
+	})
+
+	When("the arguments are invalid", func() {
+		It("should require a source of BuildConfigs", func() {
+			Expect(command.Run(ctx, nil)).To(Equal(2))
+			Expect(command.Run(ctx, []string{"-f", "-", "-n", "shop"})).To(Equal(2))
+		})
+	})
+
+	When("the BuildConfigs are read from the cluster", func() {
+		BeforeEach(func() {
+			createBuildConfig(dockerBuildConfig)
+			createBuildConfig(sourceBuildConfig)
+		})
+
+		It("should convert the named BuildConfigs", func() {
+			Expect(command.Run(ctx, []string{"-n", "shop", "backend"})).To(Equal(0))
+			Expect(stdout.String()).To(ContainSubstring("name: backend"))
+			Expect(stdout.String()).NotTo(ContainSubstring("name: frontend"))
+		})
+
+		It("should apply the converted Builds", func() {
+			Expect(command.Run(ctx, []string{"-n", "shop", "--apply"})).To(Equal(0))
+			build := &buildv1beta1.Build{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: "shop", Name: "frontend"}, build)).To(Succeed())
+			Expect(build.Spec.Strategy.Name).To(Equal(migration.BuildahStrategyName))
+
+			By("updating the Builds converted earlier")
+			Expect(command.Run(ctx, []string{"-n", "shop", "--apply", "frontend"})).To(Equal(0))
+		})
+
+		It("should not overwrite Builds which were not converted", func() {
+			Expect(fakeClient.Create(ctx, &buildv1beta1.Build{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "backend"},
+			})).To(Succeed())
+			Expect(command.Run(ctx, []string{"-n", "shop", "--apply"})).To(Equal(1))
+			Expect(stderr.String()).To(ContainSubstring("Build shop/backend already exists"))
+		})
+	})
+})
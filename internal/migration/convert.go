@@ -0,0 +1,387 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// ConvertedFromAnnotation is set on the converted Builds to the name of their BuildConfig
+const ConvertedFromAnnotation = "operator.openshift.io/converted-from-buildconfig"
+
+// Names of the build strategies installed by the operator and used by the converted Builds
+const (
+	BuildahStrategyName       = "buildah"
+	SourceToImageStrategyName = "source-to-image"
+)
+
+// Issue is a BuildConfig setting which could not be converted as is
+type Issue struct {
+	// Field is the path of the setting in the BuildConfig.
+	Field string
+
+	// Message describes how the setting was handled.
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Field, i.Message)
+}
+
+// Result holds the objects converted from a BuildConfig
+type Result struct {
+	// Build is the Shipwright Build equivalent to the BuildConfig.
+	Build *buildv1beta1.Build
+
+	// BuildStrategy is the namespaced strategy running the builder image of a custom BuildConfig
+	// strategy, if any.
+	BuildStrategy *buildv1beta1.BuildStrategy
+
+	// Issues lists the settings which could not be converted as is.
+	Issues []Issue
+}
+
+type converter struct {
+	buildConfig *BuildConfig
+	result      *Result
+}
+
+// Convert translates the BuildConfig into a Shipwright Build using the build strategies installed
+// by the operator. An error is returned when the BuildConfig cannot be converted at all, other
+// settings which could not be converted are reported as issues of the result.
+func Convert(buildConfig *BuildConfig) (*Result, error) {
+	c := &converter{
+		buildConfig: buildConfig,
+		result: &Result{
+			Build: &buildv1beta1.Build{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: buildv1beta1.SchemeGroupVersion.String(),
+					Kind:       "Build",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:   buildConfig.Namespace,
+					Name:        buildConfig.Name,
+					Labels:      buildConfig.Labels,
+					Annotations: map[string]string{ConvertedFromAnnotation: buildConfig.Name},
+				},
+			},
+		},
+	}
+	spec := &buildConfig.Spec
+	errs := []error{
+		c.convertSource(&spec.Source),
+		c.convertStrategy(&spec.Strategy),
+		c.convertOutput(&spec.Output),
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("BuildConfig %s/%s cannot be converted: %w", buildConfig.Namespace, buildConfig.Name, err)
+	}
+	c.convertSettings(spec)
+	return c.result, nil
+}
+
+func (c *converter) report(field, format string, args ...interface{}) {
+	c.result.Issues = append(c.result.Issues, Issue{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *converter) convertSource(source *BuildSource) error {
+	build := &c.result.Build.Spec
+	switch {
+	case source.Git != nil:
+		build.Source = &buildv1beta1.Source{
+			Type: buildv1beta1.GitType,
+			Git:  &buildv1beta1.Git{URL: source.Git.URI},
+		}
+		if source.Git.Ref != "" {
+			build.Source.Git.Revision = ptr.To(source.Git.Ref)
+		}
+		if source.SourceSecret != nil {
+			build.Source.Git.CloneSecret = ptr.To(source.SourceSecret.Name)
+		}
+		if source.Git.HTTPProxy != nil || source.Git.HTTPSProxy != nil || source.Git.NoProxy != nil {
+			c.report("spec.source.git", "git proxies are not supported, the cluster proxy is used to clone the repository")
+		}
+	case source.Binary != nil:
+		build.Source = &buildv1beta1.Source{
+			Type:  buildv1beta1.LocalType,
+			Local: &buildv1beta1.Local{Name: "binary"},
+		}
+		c.report("spec.source.binary", "converted to a local source, upload it with \"shp build upload\" instead of \"oc start-build --from-dir\"")
+		if source.Binary.AsFile != "" {
+			c.report("spec.source.binary.asFile", "uploading a single file is not supported")
+		}
+	case source.Dockerfile != nil:
+		return errors.New("builds from an inline Dockerfile without source repository are not supported")
+	default:
+		return errors.New("the BuildConfig has no source")
+	}
+	if source.Dockerfile != nil && source.Git != nil {
+		c.report("spec.source.dockerfile", "the inline Dockerfile is ignored, commit it to the source repository")
+	}
+	if source.ContextDir != "" {
+		build.Source.ContextDir = ptr.To(source.ContextDir)
+	}
+	if len(source.Images) > 0 {
+		c.report("spec.source.images", "image sources are not supported")
+	}
+	if len(source.Secrets) > 0 || len(source.ConfigMaps) > 0 {
+		c.report("spec.source", "build input secrets and config maps are not supported, mount them with build volumes")
+	}
+	return nil
+}
+
+func (c *converter) convertStrategy(strategy *BuildStrategy) error {
+	build := &c.result.Build.Spec
+	kind := buildv1beta1.ClusterBuildStrategyKind
+	switch {
+	case strategy.DockerStrategy != nil:
+		docker := strategy.DockerStrategy
+		build.Strategy = buildv1beta1.Strategy{Name: BuildahStrategyName, Kind: &kind}
+		if docker.DockerfilePath != "" {
+			c.param("dockerfile", docker.DockerfilePath)
+		}
+		if docker.From != nil {
+			if image, ok := c.image(docker.From, "spec.strategy.dockerStrategy.from"); ok {
+				c.param("runtime-stage-from", image)
+			}
+		}
+		if len(docker.BuildArgs) > 0 {
+			c.arrayParam("build-args", c.keyValues(docker.BuildArgs, "spec.strategy.dockerStrategy.buildArgs"))
+		}
+		if docker.NoCache {
+			c.param("no-cache", "true")
+		}
+		if docker.ForcePull {
+			c.param("pull", "always")
+		}
+		if len(docker.Env) > 0 {
+			build.Env = docker.Env
+			c.report("spec.strategy.dockerStrategy.env", "environment variables are set on the build step and are not added to the Dockerfile, use build arguments instead")
+		}
+		if docker.ImageOptimizationPolicy != nil {
+			c.report("spec.strategy.dockerStrategy.imageOptimizationPolicy", "image optimization policies are not supported, use the squash parameter instead")
+		}
+		c.reportPullSecret(docker.PullSecret, "spec.strategy.dockerStrategy.pullSecret")
+		c.reportVolumes(len(docker.Volumes), "spec.strategy.dockerStrategy.volumes")
+	case strategy.SourceStrategy != nil:
+		source := strategy.SourceStrategy
+		build.Strategy = buildv1beta1.Strategy{Name: SourceToImageStrategyName, Kind: &kind}
+		if image, ok := c.image(&source.From, "spec.strategy.sourceStrategy.from"); ok {
+			c.param("builder-image", image)
+		}
+		if len(source.Env) > 0 {
+			c.arrayParam("build-env", c.keyValues(source.Env, "spec.strategy.sourceStrategy.env"))
+		}
+		if source.Scripts != "" {
+			c.param("scripts-url", source.Scripts)
+		}
+		if ptr.Deref(source.Incremental, false) {
+			c.param("incremental", "true")
+		}
+		if source.ForcePull {
+			c.param("pull-policy", "always")
+		}
+		c.reportPullSecret(source.PullSecret, "spec.strategy.sourceStrategy.pullSecret")
+		c.reportVolumes(len(source.Volumes), "spec.strategy.sourceStrategy.volumes")
+	case strategy.CustomStrategy != nil:
+		return c.convertCustomStrategy(strategy.CustomStrategy)
+	case strategy.JenkinsPipelineStrategy != nil:
+		return errors.New("the JenkinsPipeline strategy is deprecated and not supported")
+	default:
+		return errors.New("the BuildConfig has no strategy")
+	}
+	return nil
+}
+
+// convertCustomStrategy generates a namespaced build strategy running the custom builder image,
+// with the environment variables OpenShift sets for custom builders which have an equivalent.
+func (c *converter) convertCustomStrategy(custom *CustomBuildStrategy) error {
+	image, ok := c.image(&custom.From, "spec.strategy.customStrategy.from")
+	if !ok {
+		return errors.New("the custom builder image cannot be resolved")
+	}
+
+	env := []corev1.EnvVar{{Name: "OUTPUT_IMAGE", Value: "$(params.shp-output-image)"}}
+	if git := c.buildConfig.Spec.Source.Git; git != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "SOURCE_REPOSITORY", Value: git.URI},
+			corev1.EnvVar{Name: "SOURCE_URI", Value: git.URI},
+			corev1.EnvVar{Name: "SOURCE_REF", Value: git.Ref},
+		)
+	}
+	if contextDir := c.buildConfig.Spec.Source.ContextDir; contextDir != "" {
+		env = append(env, corev1.EnvVar{Name: "SOURCE_CONTEXT_DIR", Value: contextDir})
+	}
+	env = append(env, custom.Env...)
+
+	name := c.buildConfig.Name + "-custom"
+	c.result.BuildStrategy = &buildv1beta1.BuildStrategy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: buildv1beta1.SchemeGroupVersion.String(),
+			Kind:       string(buildv1beta1.NamespacedBuildStrategyKind),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   c.buildConfig.Namespace,
+			Name:        name,
+			Annotations: map[string]string{ConvertedFromAnnotation: c.buildConfig.Name},
+		},
+		Spec: buildv1beta1.BuildStrategySpec{
+			Steps: []buildv1beta1.Step{{
+				Name:       "build",
+				Image:      image,
+				WorkingDir: "$(params.shp-source-root)",
+				Env:        env,
+			}},
+		},
+	}
+	kind := buildv1beta1.NamespacedBuildStrategyKind
+	c.result.Build.Spec.Strategy = buildv1beta1.Strategy{Name: name, Kind: &kind}
+
+	c.report("spec.strategy.customStrategy", "converted to the %s BuildStrategy, the BUILD environment variable is not set for the custom builder", name)
+	if custom.ExposeDockerSocket {
+		c.report("spec.strategy.customStrategy.exposeDockerSocket", "the Docker socket is not available to build strategies")
+	}
+	if len(custom.Secrets) > 0 {
+		c.report("spec.strategy.customStrategy.secrets", "secrets are not mounted, add them as volumes of the %s BuildStrategy", name)
+	}
+	c.reportPullSecret(custom.PullSecret, "spec.strategy.customStrategy.pullSecret")
+	return nil
+}
+
+func (c *converter) convertOutput(output *BuildOutput) error {
+	build := &c.result.Build.Spec
+	if output.To == nil {
+		return errors.New("builds without output image are not supported")
+	}
+	image, ok := c.image(output.To, "spec.output.to")
+	if !ok {
+		return errors.New("the output image cannot be resolved")
+	}
+	build.Output.Image = image
+	if output.PushSecret != nil {
+		build.Output.PushSecret = ptr.To(output.PushSecret.Name)
+	}
+	for _, label := range output.ImageLabels {
+		if build.Output.Labels == nil {
+			build.Output.Labels = map[string]string{}
+		}
+		build.Output.Labels[label.Name] = label.Value
+	}
+	return nil
+}
+
+func (c *converter) convertSettings(spec *BuildConfigSpec) {
+	build := &c.result.Build.Spec
+	if spec.CompletionDeadlineSeconds != nil {
+		build.Timeout = &metav1.Duration{Duration: time.Duration(*spec.CompletionDeadlineSeconds) * time.Second}
+	}
+	if len(spec.NodeSelector) > 0 {
+		build.NodeSelector = spec.NodeSelector
+	}
+	if limit := ptr.Deref(spec.SuccessfulBuildsHistoryLimit, 0); limit > 0 {
+		build.Retention = ptr.To(ptr.Deref(build.Retention, buildv1beta1.BuildRetention{}))
+		build.Retention.SucceededLimit = ptr.To(uint(limit))
+	}
+	if limit := ptr.Deref(spec.FailedBuildsHistoryLimit, 0); limit > 0 {
+		build.Retention = ptr.To(ptr.Deref(build.Retention, buildv1beta1.BuildRetention{}))
+		build.Retention.FailedLimit = ptr.To(uint(limit))
+	}
+	if len(spec.Resources.Limits) > 0 || len(spec.Resources.Requests) > 0 {
+		c.report("spec.resources", "resources are not converted, set them per step with spec.strategy.stepResources")
+	}
+	if spec.ServiceAccount != "" {
+		c.report("spec.serviceAccount", "set the service account %q on the BuildRuns instead", spec.ServiceAccount)
+	}
+	if spec.RunPolicy != "" && spec.RunPolicy != "Serial" {
+		c.report("spec.runPolicy", "the %s run policy is not supported, BuildRuns run in parallel", spec.RunPolicy)
+	}
+	if spec.PostCommit.Script != "" || len(spec.PostCommit.Command) > 0 || len(spec.PostCommit.Args) > 0 {
+		c.report("spec.postCommit", "post commit hooks are not supported")
+	}
+	if ptr.Deref(spec.MountTrustedCA, false) {
+		c.report("spec.mountTrustedCA", "the cluster trusted CA bundle is not mounted")
+	}
+	for i, trigger := range spec.Triggers {
+		if trigger.Type != "ConfigChange" {
+			c.report(fmt.Sprintf("spec.triggers[%d]", i), "%s triggers are not converted", trigger.Type)
+		}
+	}
+}
+
+// image returns the pull specification of the referenced image. ImageStream references are
+// resolved through the internal registry.
+func (c *converter) image(ref *corev1.ObjectReference, field string) (string, bool) {
+	switch ref.Kind {
+	case "DockerImage", "":
+		if ref.Name == "" {
+			c.report(field, "the image name is empty")
+			return "", false
+		}
+		return ref.Name, true
+	case "ImageStreamTag", "ImageStreamImage":
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = c.buildConfig.Namespace
+		}
+		image := path.Join(common.InternalRegistryHostname, namespace, ref.Name)
+		c.report(field, "the %s %s/%s is referenced through the internal registry as %s", ref.Kind, namespace, ref.Name, image)
+		return image, true
+	default:
+		c.report(field, "references of kind %q are not supported", ref.Kind)
+		return "", false
+	}
+}
+
+func (c *converter) param(name, value string) {
+	build := &c.result.Build.Spec
+	build.ParamValues = append(build.ParamValues, buildv1beta1.ParamValue{
+		Name:        name,
+		SingleValue: &buildv1beta1.SingleValue{Value: ptr.To(value)},
+	})
+}
+
+func (c *converter) arrayParam(name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	param := buildv1beta1.ParamValue{Name: name}
+	for _, value := range values {
+		param.Values = append(param.Values, buildv1beta1.SingleValue{Value: ptr.To(value)})
+	}
+	build := &c.result.Build.Spec
+	build.ParamValues = append(build.ParamValues, param)
+}
+
+// keyValues formats the environment variables as KEY=VALUE. Variables referencing secrets or
+// config maps are reported.
+func (c *converter) keyValues(env []corev1.EnvVar, field string) []string {
+	values := make([]string, 0, len(env))
+	for i, e := range env {
+		if e.ValueFrom != nil {
+			c.report(field+"["+strconv.Itoa(i)+"]", "the value of %s is taken from a reference, which is not supported", e.Name)
+			continue
+		}
+		values = append(values, e.Name+"="+e.Value)
+	}
+	return values
+}
+
+func (c *converter) reportPullSecret(secret *corev1.LocalObjectReference, field string) {
+	if secret != nil {
+		c.report(field, "link the secret %q to the service account running the BuildRuns instead", secret.Name)
+	}
+}
+
+func (c *converter) reportVolumes(count int, field string) {
+	if count > 0 {
+		c.report(field, "build volumes are not converted, the strategy volumes can only be overridden when they are declared overridable")
+	}
+}
@@ -0,0 +1,262 @@
+package migration_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/migration"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+const dockerBuildConfig = `
+apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: frontend
+  namespace: shop
+  labels:
+    app: frontend
+spec:
+  runPolicy: Serial
+  completionDeadlineSeconds: 1800
+  successfulBuildsHistoryLimit: 3
+  source:
+    git:
+      uri: https://github.com/example/frontend
+      ref: main
+    contextDir: web
+    sourceSecret:
+      name: git-credentials
+  strategy:
+    dockerStrategy:
+      dockerfilePath: build/Dockerfile
+      from:
+        kind: DockerImage
+        name: registry.example.com/base:1
+      buildArgs:
+      - name: VERSION
+        value: "1.2"
+      noCache: true
+      forcePull: true
+  output:
+    to:
+      kind: ImageStreamTag
+      name: frontend:latest
+    pushSecret:
+      name: registry-credentials
+    imageLabels:
+    - name: team
+      value: web
+  triggers:
+  - type: ConfigChange
+  - type: GitHub
+`
+
+const sourceBuildConfig = `
+apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: backend
+  namespace: shop
+spec:
+  serviceAccount: builder
+  source:
+    git:
+      uri: https://github.com/example/backend
+  strategy:
+    sourceStrategy:
+      from:
+        kind: DockerImage
+        name: registry.access.redhat.com/ubi9/nodejs-20
+      env:
+      - name: NPM_MIRROR
+        value: https://npm.example.com
+      - name: TOKEN
+        valueFrom:
+          secretKeyRef:
+            name: token
+            key: token
+      incremental: true
+  output:
+    to:
+      kind: DockerImage
+      name: quay.io/example/backend:latest
+`
+
+const customBuildConfig = `
+apiVersion: build.openshift.io/v1
+kind: BuildConfig
+metadata:
+  name: legacy
+  namespace: shop
+spec:
+  source:
+    git:
+      uri: https://github.com/example/legacy
+  strategy:
+    customStrategy:
+      from:
+        kind: DockerImage
+        name: quay.io/example/custom-builder:latest
+      exposeDockerSocket: true
+      env:
+      - name: MODE
+        value: release
+  output:
+    to:
+      kind: DockerImage
+      name: quay.io/example/legacy:latest
+`
+
+func parseBuildConfig(content string) *migration.BuildConfig {
+	object := &unstructured.Unstructured{}
+	Expect(yaml.Unmarshal([]byte(content), &object.Object)).To(Succeed())
+	buildConfig, err := migration.FromUnstructured(object)
+	Expect(err).NotTo(HaveOccurred())
+	return buildConfig
+}
+
+func paramValue(build *buildv1beta1.Build, name string) *buildv1beta1.ParamValue {
+	for i := range build.Spec.ParamValues {
+		if build.Spec.ParamValues[i].Name == name {
+			return &build.Spec.ParamValues[i]
+		}
+	}
+	return nil
+}
+
+func issueFields(result *migration.Result) []string {
+	fields := []string{}
+	for _, issue := range result.Issues {
+		fields = append(fields, issue.Field)
+	}
+	return fields
+}
+
+var _ = Describe("Convert", Label("migration"), func() {
+	When("the BuildConfig uses the Docker strategy", func() {
+		It("should convert it to a buildah Build", func() {
+			result, err := migration.Convert(parseBuildConfig(dockerBuildConfig))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.BuildStrategy).To(BeNil())
+
+			build := result.Build
+			Expect(build.Namespace).To(Equal("shop"))
+			Expect(build.Name).To(Equal("frontend"))
+			Expect(build.Labels).To(HaveKeyWithValue("app", "frontend"))
+			Expect(build.Annotations).To(HaveKeyWithValue(migration.ConvertedFromAnnotation, "frontend"))
+
+			Expect(build.Spec.Strategy.Name).To(Equal(migration.BuildahStrategyName))
+			Expect(*build.Spec.Strategy.Kind).To(Equal(buildv1beta1.ClusterBuildStrategyKind))
+			Expect(build.Spec.Source.Type).To(Equal(buildv1beta1.GitType))
+			Expect(build.Spec.Source.Git.URL).To(Equal("https://github.com/example/frontend"))
+			Expect(build.Spec.Source.Git.Revision).To(Equal(ptr.To("main")))
+			Expect(build.Spec.Source.Git.CloneSecret).To(Equal(ptr.To("git-credentials")))
+			Expect(build.Spec.Source.ContextDir).To(Equal(ptr.To("web")))
+
+			Expect(*paramValue(build, "dockerfile").Value).To(Equal("build/Dockerfile"))
+			Expect(*paramValue(build, "runtime-stage-from").Value).To(Equal("registry.example.com/base:1"))
+			Expect(*paramValue(build, "no-cache").Value).To(Equal("true"))
+			Expect(*paramValue(build, "pull").Value).To(Equal("always"))
+			Expect(paramValue(build, "build-args").Values).To(ConsistOf(buildv1beta1.SingleValue{Value: ptr.To("VERSION=1.2")}))
+
+			Expect(build.Spec.Output.Image).To(Equal("image-registry.openshift-image-registry.svc:5000/shop/frontend:latest"))
+			Expect(build.Spec.Output.PushSecret).To(Equal(ptr.To("registry-credentials")))
+			Expect(build.Spec.Output.Labels).To(HaveKeyWithValue("team", "web"))
+			Expect(build.Spec.Timeout.Duration).To(Equal(30 * time.Minute))
+			Expect(*build.Spec.Retention.SucceededLimit).To(BeEquivalentTo(3))
+			Expect(build.Spec.Retention.FailedLimit).To(BeNil())
+
+			Expect(issueFields(result)).To(ConsistOf("spec.output.to", "spec.triggers[1]"))
+		})
+	})
+
+	When("the BuildConfig uses the Source strategy", func() {
+		It("should convert it to a source-to-image Build", func() {
+			result, err := migration.Convert(parseBuildConfig(sourceBuildConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			build := result.Build
+			Expect(build.Spec.Strategy.Name).To(Equal(migration.SourceToImageStrategyName))
+			Expect(*paramValue(build, "builder-image").Value).To(Equal("registry.access.redhat.com/ubi9/nodejs-20"))
+			Expect(*paramValue(build, "incremental").Value).To(Equal("true"))
+			Expect(paramValue(build, "build-env").Values).To(ConsistOf(buildv1beta1.SingleValue{Value: ptr.To("NPM_MIRROR=https://npm.example.com")}))
+			Expect(build.Spec.Output.Image).To(Equal("quay.io/example/backend:latest"))
+			Expect(build.Spec.Timeout).To(BeNil())
+
+			Expect(issueFields(result)).To(ConsistOf("spec.strategy.sourceStrategy.env[1]", "spec.serviceAccount"))
+		})
+	})
+
+	When("the BuildConfig uses a custom strategy", func() {
+		It("should generate a BuildStrategy running the custom builder", func() {
+			result, err := migration.Convert(parseBuildConfig(customBuildConfig))
+			Expect(err).NotTo(HaveOccurred())
+
+			strategy := result.BuildStrategy
+			Expect(strategy).NotTo(BeNil())
+			Expect(strategy.Namespace).To(Equal("shop"))
+			Expect(strategy.Name).To(Equal("legacy-custom"))
+			Expect(strategy.Annotations).To(HaveKeyWithValue(migration.ConvertedFromAnnotation, "legacy"))
+			Expect(strategy.Spec.Steps).To(HaveLen(1))
+			Expect(strategy.Spec.Steps[0].Image).To(Equal("quay.io/example/custom-builder:latest"))
+			Expect(strategy.Spec.Steps[0].Env).To(ContainElements(
+				corev1.EnvVar{Name: "OUTPUT_IMAGE", Value: "$(params.shp-output-image)"},
+				corev1.EnvVar{Name: "SOURCE_URI", Value: "https://github.com/example/legacy"},
+				corev1.EnvVar{Name: "MODE", Value: "release"},
+			))
+
+			Expect(result.Build.Spec.Strategy.Name).To(Equal("legacy-custom"))
+			Expect(*result.Build.Spec.Strategy.Kind).To(Equal(buildv1beta1.NamespacedBuildStrategyKind))
+			Expect(issueFields(result)).To(ContainElements("spec.strategy.customStrategy", "spec.strategy.customStrategy.exposeDockerSocket"))
+		})
+	})
+
+	When("the BuildConfig uses a binary source", func() {
+		It("should convert it to a local source and report it", func() {
+			buildConfig := parseBuildConfig(dockerBuildConfig)
+			buildConfig.Spec.Source.Git = nil
+			buildConfig.Spec.Source.Binary = &migration.BinaryBuildSource{}
+
+			result, err := migration.Convert(buildConfig)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Build.Spec.Source.Type).To(Equal(buildv1beta1.LocalType))
+			Expect(issueFields(result)).To(ContainElement("spec.source.binary"))
+		})
+	})
+
+	When("the BuildConfig cannot be converted", func() {
+		It("should fail for the JenkinsPipeline strategy", func() {
+			buildConfig := parseBuildConfig(dockerBuildConfig)
+			buildConfig.Spec.Strategy.DockerStrategy = nil
+			buildConfig.Spec.Strategy.JenkinsPipelineStrategy = &runtime.RawExtension{}
+
+			_, err := migration.Convert(buildConfig)
+			Expect(err).To(MatchError(ContainSubstring("JenkinsPipeline")))
+		})
+
+		It("should fail without output image", func() {
+			buildConfig := parseBuildConfig(dockerBuildConfig)
+			buildConfig.Spec.Output.To = nil
+
+			_, err := migration.Convert(buildConfig)
+			Expect(err).To(MatchError(ContainSubstring("shop/frontend cannot be converted")))
+		})
+
+		It("should fail for an inline Dockerfile without repository", func() {
+			buildConfig := parseBuildConfig(dockerBuildConfig)
+			buildConfig.Spec.Source.Git = nil
+			buildConfig.Spec.Source.Dockerfile = ptr.To("FROM scratch")
+
+			_, err := migration.Convert(buildConfig)
+			Expect(err).To(MatchError(ContainSubstring("inline Dockerfile")))
+		})
+	})
+})
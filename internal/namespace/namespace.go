@@ -0,0 +1,107 @@
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ComponentName is the name of the operand Namespace component
+const ComponentName = "Namespace"
+
+var _ component.Component = &Namespace{}
+
+// Namespace type defines methods to create and label the namespace where operands are deployed
+type Namespace struct {
+	Client client.Client
+	Labels map[string]string
+}
+
+// New creates new instance of Namespace type
+func New(client client.Client, labels map[string]string) *Namespace {
+	return &Namespace{
+		Client: client,
+		Labels: labels,
+	}
+}
+
+// Name returns the component name
+func (n *Namespace) Name() string {
+	return ComponentName
+}
+
+// Setup initializes the client from the manager if not set
+func (n *Namespace) Setup(mgr ctrl.Manager) error {
+	if n.Client == nil {
+		n.Client = mgr.GetClient()
+	}
+	return nil
+}
+
+// Reconcile creates the operand namespace with the operator labels. The labels are only
+// maintained on the namespaces created by the operator, and existing namespaces are left as is.
+func (n *Namespace) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	object := &corev1.Namespace{}
+	err := n.Client.Get(ctx, types.NamespacedName{Name: common.OperandNamespace(owner)}, object)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get namespace %s: %v", common.OperandNamespace(owner), err)
+	}
+	if err == nil && object.Labels[common.OperandNamespaceManagedByLabel] != common.OperandNamespaceManagedBy {
+		logger.Info("Operand namespace", "namespace", object.Name, "result", "existing")
+		return nil
+	}
+
+	object = &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.OperandNamespace(owner),
+		},
+	}
+	result, err := ctrl.CreateOrUpdate(ctx, n.Client, object, func() error {
+		labels := object.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, n.Labels)
+		labels[common.OperandNamespaceManagedByLabel] = common.OperandNamespaceManagedBy
+		object.SetLabels(labels)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile namespace %s: %v", object.Name, err)
+	}
+	logger.Info("Operand namespace", "namespace", object.Name, "result", result)
+	return nil
+}
+
+// Delete is a no-op. The operand namespace is left behind as it may hold the operator itself
+// and resources not managed by the operator.
+func (n *Namespace) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	return nil
+}
+
+// Status reports the namespace where operands are deployed
+func (n *Namespace) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	return metav1.Condition{
+		Type:    component.ConditionType(n),
+		Status:  metav1.ConditionTrue,
+		Reason:  "Reconciled",
+		Message: fmt.Sprintf("Operands are deployed in namespace %s", common.OperandNamespace(owner)),
+	}
+}
+
+// WatchedTypes returns nil as the namespace is not owned by OpenShiftBuild
+func (n *Namespace) WatchedTypes() []client.Object {
+	return nil
+}
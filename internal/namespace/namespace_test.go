@@ -0,0 +1,101 @@
+package namespace_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/namespace"
+	"github.com/redhat-openshift-builds/operator/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Namespace", Label("namespace"), func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		owner     *openshiftv1alpha1.OpenShiftBuild
+		k8sClient client.Client
+		ns        *namespace.Namespace
+		labels    map[string]string
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = testutil.NewScheme()
+		owner = testutil.NewOwner()
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		labels = map[string]string{"test-key": "test-value"}
+		ns = namespace.New(k8sClient, labels)
+	})
+
+	When("the namespace is not set on the owner", func() {
+		It("should create the default operand namespace with labels", func() {
+			Expect(ns.Reconcile(ctx, owner.DeepCopy())).To(Succeed())
+
+			object := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: common.OperandNamespaceName}, object)).To(Succeed())
+			Expect(object.GetLabels()).To(HaveKeyWithValue("test-key", "test-value"))
+		})
+	})
+
+	When("the namespace is set on the owner", func() {
+		It("should create the configured namespace", func() {
+			reconcileOwner := owner.DeepCopy()
+			reconcileOwner.Spec.Namespace = "test-namespace"
+			Expect(ns.Reconcile(ctx, reconcileOwner)).To(Succeed())
+
+			object := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: "test-namespace"}, object)).To(Succeed())
+			Expect(ns.Status(ctx, reconcileOwner).Message).To(ContainSubstring("test-namespace"))
+		})
+	})
+
+	When("the namespace already exists", func() {
+		It("should not label it", func() {
+			Expect(k8sClient.Create(ctx, &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   common.OperandNamespaceName,
+					Labels: map[string]string{"existing": "label"},
+				},
+			})).To(Succeed())
+			Expect(ns.Reconcile(ctx, owner.DeepCopy())).To(Succeed())
+
+			object := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: common.OperandNamespaceName}, object)).To(Succeed())
+			Expect(object.GetLabels()).To(Equal(map[string]string{"existing": "label"}))
+		})
+	})
+
+	When("the namespace was created by the operator", func() {
+		It("should restore the operator labels", func() {
+			Expect(ns.Reconcile(ctx, owner.DeepCopy())).To(Succeed())
+			object := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: common.OperandNamespaceName}, object)).To(Succeed())
+			delete(object.Labels, "test-key")
+			Expect(k8sClient.Update(ctx, object)).To(Succeed())
+
+			Expect(ns.Reconcile(ctx, owner.DeepCopy())).To(Succeed())
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: common.OperandNamespaceName}, object)).To(Succeed())
+			Expect(object.GetLabels()).To(HaveKeyWithValue("test-key", "test-value"))
+			Expect(object.GetLabels()).To(HaveKeyWithValue(common.OperandNamespaceManagedByLabel, common.OperandNamespaceManagedBy))
+		})
+	})
+
+	When("the owner is deleted", func() {
+		It("should not delete the namespace", func() {
+			Expect(ns.Reconcile(ctx, owner.DeepCopy())).To(Succeed())
+			Expect(ns.Delete(ctx, owner.DeepCopy())).To(Succeed())
+
+			object := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, client.ObjectKey{Name: common.OperandNamespaceName}, object)).To(Succeed())
+		})
+	})
+})
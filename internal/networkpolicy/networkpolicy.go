@@ -4,17 +4,30 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
 	"github.com/manifestival/manifestival"
 	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ComponentName is the name of the NetworkPolicy component
+const ComponentName = "NetworkPolicy"
+
+var (
+	_ component.Component   = &NetworkPolicy{}
+	_ component.Relocatable = &NetworkPolicy{}
+)
+
 type NetworkPolicy struct {
-	Client   client.Client
-	Logger   logr.Logger
-	Manifest manifestival.Manifest
+	Client       client.Client
+	Logger       logr.Logger
+	Manifest     manifestival.Manifest
+	ManifestPath string
 }
 
 func New(client client.Client, manifest manifestival.Manifest, logger logr.Logger) *NetworkPolicy {
@@ -25,19 +38,40 @@ func New(client client.Client, manifest manifestival.Manifest, logger logr.Logge
 	}
 }
 
-func (np *NetworkPolicy) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
-	logger := np.Logger.WithValues("name", owner.Name)
+// Name returns the component name
+func (np *NetworkPolicy) Name() string {
+	return ComponentName
+}
 
-	transformerfuncs := []manifestival.Transformer{
-		manifestival.InjectOwner(owner),
-		manifestival.InjectNamespace(common.OpenShiftBuildNamespaceName),
+// Setup initializes the manifestival to apply NetworkPolicy resources
+func (np *NetworkPolicy) Setup(mgr ctrl.Manager) error {
+	if np.Client == nil {
+		np.Client = mgr.GetClient()
 	}
 
-	if owner.DeletionTimestamp.IsZero() {
-		transformerfuncs = append(transformerfuncs, common.InjectFinalizer(common.OpenShiftBuildFinalizerName))
+	// Initialize Manifestival
+	manifestivalOptions := []manifestival.Option{
+		manifestival.UseLogger(np.Logger),
+		manifestival.UseClient(manifestivalclient.NewClient(np.Client)),
 	}
 
-	manifest, err := np.Manifest.Transform(transformerfuncs...)
+	// NetworkPolicy manifests
+	networkPolicyManifestPath := common.NetworkPolicyManifestPath
+	if np.ManifestPath != "" {
+		networkPolicyManifestPath = np.ManifestPath
+	}
+	manifest, err := manifestival.NewManifest(networkPolicyManifestPath, manifestivalOptions...)
+	if err != nil {
+		return err
+	}
+	np.Manifest = manifest
+	return nil
+}
+
+func (np *NetworkPolicy) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := np.Logger.WithValues("name", owner.Name)
+
+	manifest, err := np.transform(owner)
 	if err != nil {
 		logger.Error(err, "Failed to transform NetworkPolicy manifests")
 		return err
@@ -52,13 +86,63 @@ func (np *NetworkPolicy) Reconcile(ctx context.Context, owner *openshiftv1alpha1
 	return manifest.Apply()
 }
 
+// Delete removes the finalizers from the NetworkPolicy resources and deletes them
+func (np *NetworkPolicy) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	manifest, err := np.transform(owner)
+	if err != nil {
+		np.Logger.Error(err, "Failed to transform NetworkPolicy manifests", "name", owner.Name)
+		return err
+	}
+	return np.deleteManifests(&manifest)
+}
+
+// Relocate deletes the NetworkPolicy resources deployed in the previous operand namespace
+func (np *NetworkPolicy) Relocate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, previous string) error {
+	previousOwner := owner.DeepCopy()
+	previousOwner.Spec.Namespace = previous
+	manifest, err := np.transform(previousOwner)
+	if err != nil {
+		return err
+	}
+	np.Logger.Info("Deleting NetworkPolicy resources of the previous operand namespace", "namespace", previous)
+	return common.DeleteOperands(manifest, previous, owner)
+}
+
+// Status reports whether the NetworkPolicy resources are deployed
+func (np *NetworkPolicy) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	return metav1.Condition{
+		Type:    component.ConditionType(np),
+		Status:  metav1.ConditionTrue,
+		Reason:  "Reconciled",
+		Message: "NetworkPolicy resources are deployed",
+	}
+}
+
+// WatchedTypes returns nil as changes to NetworkPolicy resources do not trigger a reconciliation
+func (np *NetworkPolicy) WatchedTypes() []client.Object {
+	return nil
+}
+
+func (np *NetworkPolicy) transform(owner *openshiftv1alpha1.OpenShiftBuild) (manifestival.Manifest, error) {
+	transformerfuncs := []manifestival.Transformer{
+		manifestival.InjectOwner(owner),
+		manifestival.InjectNamespace(common.OperandNamespace(owner)),
+	}
+
+	if owner.DeletionTimestamp.IsZero() {
+		transformerfuncs = append(transformerfuncs, common.InjectFinalizer(common.OpenShiftBuildFinalizerName))
+	}
+
+	return np.Manifest.Transform(transformerfuncs...)
+}
+
 func (np *NetworkPolicy) deleteManifests(manifest *manifestival.Manifest) error {
 	mfc := np.Manifest.Client
 	for _, res := range manifest.Resources() {
 		obj, err := mfc.Get(&res)
 		if err != nil {
 			if errors.IsNotFound(err) {
-				continue 
+				continue
 			}
 			return err
 		}
@@ -114,6 +114,35 @@ var _ = Describe("NetworkPolicy", Label("networkpolicy"), func() {
 			})
 		})
 
+		When("the operand namespace changes", Ordered, func() {
+			var reconcileOwner *operatorv1alpha1.OpenShiftBuild
+
+			BeforeAll(func() {
+				reconcileOwner = owner.DeepCopy()
+				Expect(np.Reconcile(ctx, reconcileOwner)).To(Succeed())
+
+				foreign := newUnstructuredNetworkPolicy("user-policy")
+				Expect(k8sClient.Create(ctx, foreign)).To(Succeed())
+
+				reconcileOwner.Spec.Namespace = "builds"
+				Expect(np.Reconcile(ctx, reconcileOwner)).To(Succeed())
+				Expect(np.Relocate(ctx, reconcileOwner, common.OpenShiftBuildNamespaceName)).To(Succeed())
+			})
+
+			It("should create the NetworkPolicy resources in the new namespace", func() {
+				netpolList := &networkingv1.NetworkPolicyList{}
+				Expect(k8sClient.List(ctx, netpolList, client.InNamespace("builds"))).To(Succeed())
+				Expect(netpolList.Items).To(HaveLen(2))
+			})
+
+			It("should delete the NetworkPolicy resources of the previous namespace", func() {
+				netpolList := &networkingv1.NetworkPolicyList{}
+				Expect(k8sClient.List(ctx, netpolList, client.InNamespace(common.OpenShiftBuildNamespaceName))).To(Succeed())
+				Expect(netpolList.Items).To(HaveLen(1))
+				Expect(netpolList.Items[0].Name).To(Equal("user-policy"))
+			})
+		})
+
 		When("no resources exist during deletion", func() {
 			It("should not error and should leave no resources behind", func() {
 				reconcileOwner := owner.DeepCopy()
@@ -0,0 +1,193 @@
+package pruning
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Annotations of the namespaces overriding the cluster retention policy of their BuildRuns
+const (
+	SucceededLimitAnnotation    = "operator.openshift.io/buildrun-succeeded-limit"
+	FailedLimitAnnotation       = "operator.openshift.io/buildrun-failed-limit"
+	TTLAfterSucceededAnnotation = "operator.openshift.io/buildrun-ttl-after-succeeded"
+	TTLAfterFailedAnnotation    = "operator.openshift.io/buildrun-ttl-after-failed"
+)
+
+// Annotations are the annotations of the namespaces overriding the cluster retention policy
+var Annotations = []string{
+	SucceededLimitAnnotation,
+	FailedLimitAnnotation,
+	TTLAfterSucceededAnnotation,
+	TTLAfterFailedAnnotation,
+}
+
+// Reasons for pruning a BuildRun
+const (
+	ReasonLimit = "limit"
+	ReasonTTL   = "ttl"
+)
+
+// PrunedBuildRuns counts the BuildRuns deleted by the operator
+var PrunedBuildRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "openshift_builds_pruned_buildruns_total",
+	Help: "Number of completed BuildRuns pruned by the OpenShift Builds operator",
+}, []string{"namespace", "status", "reason"})
+
+func init() {
+	metrics.Registry.MustRegister(PrunedBuildRuns)
+}
+
+// Policy is the retention policy of the BuildRuns of a namespace. Nil fields do not limit the
+// BuildRuns.
+type Policy struct {
+	SucceededLimit    *int32
+	FailedLimit       *int32
+	TTLAfterSucceeded *time.Duration
+	TTLAfterFailed    *time.Duration
+}
+
+// Enabled returns true when the OpenShiftBuild enables the pruning of the BuildRuns
+func Enabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return owner.Spec.Shipwright != nil && owner.Spec.Shipwright.Pruning != nil &&
+		owner.Spec.Shipwright.Pruning.State == openshiftv1alpha1.Enabled
+}
+
+// PolicyFor returns the retention policy of the namespace: the cluster defaults of the
+// OpenShiftBuild overridden by the annotations of the namespace. Invalid annotations are
+// ignored and returned as an error along with the policy.
+func PolicyFor(owner *openshiftv1alpha1.OpenShiftBuild, namespace *corev1.Namespace) (Policy, error) {
+	policy := Policy{}
+	if owner.Spec.Shipwright != nil && owner.Spec.Shipwright.Pruning != nil {
+		defaults := owner.Spec.Shipwright.Pruning
+		policy.SucceededLimit = defaults.SucceededLimit
+		policy.FailedLimit = defaults.FailedLimit
+		if defaults.TTLAfterSucceeded != nil {
+			policy.TTLAfterSucceeded = &defaults.TTLAfterSucceeded.Duration
+		}
+		if defaults.TTLAfterFailed != nil {
+			policy.TTLAfterFailed = &defaults.TTLAfterFailed.Duration
+		}
+	}
+
+	var errs []error
+	overrideLimit := func(annotation string, limit **int32) {
+		value, ok := namespace.Annotations[annotation]
+		if !ok {
+			return
+		}
+		parsed, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || parsed < 1 || parsed > 10000 {
+			errs = append(errs, fmt.Errorf("%s must be a number between 1 and 10000: %q", annotation, value))
+			return
+		}
+		*limit = ptr.To(int32(parsed))
+	}
+	overrideTTL := func(annotation string, ttl **time.Duration) {
+		value, ok := namespace.Annotations[annotation]
+		if !ok {
+			return
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be a positive duration: %q", annotation, value))
+			return
+		}
+		*ttl = &parsed
+	}
+	overrideLimit(SucceededLimitAnnotation, &policy.SucceededLimit)
+	overrideLimit(FailedLimitAnnotation, &policy.FailedLimit)
+	overrideTTL(TTLAfterSucceededAnnotation, &policy.TTLAfterSucceeded)
+	overrideTTL(TTLAfterFailedAnnotation, &policy.TTLAfterFailed)
+
+	if len(errs) > 0 {
+		return policy, fmt.Errorf("invalid retention annotations of namespace %s: %v", namespace.Name, errs)
+	}
+	return policy, nil
+}
+
+// Empty returns true when the policy does not limit the BuildRuns
+func (p Policy) Empty() bool {
+	return p.SucceededLimit == nil && p.FailedLimit == nil && p.TTLAfterSucceeded == nil && p.TTLAfterFailed == nil
+}
+
+// Pruned is a BuildRun to delete
+type Pruned struct {
+	BuildRun *buildv1beta1.BuildRun
+	Reason   string
+}
+
+// Status returns the status of the pruned BuildRun, succeeded or failed
+func (p Pruned) Status() string {
+	if p.BuildRun.IsSuccessful() {
+		return "succeeded"
+	}
+	return "failed"
+}
+
+// Prune returns the completed BuildRuns of a namespace exceeding the policy, and the duration
+// until the next completed BuildRun expires, zero when none does. BuildRuns are counted against
+// the limits per Build, and the BuildRuns with an embedded Build spec are counted together.
+// The BuildRuns retained by Shipwright, for which retains returns true, are left alone.
+func (p Policy) Prune(buildRuns []buildv1beta1.BuildRun, retains func(*buildv1beta1.BuildRun) bool, now time.Time) ([]Pruned, time.Duration) {
+	succeeded := map[string][]*buildv1beta1.BuildRun{}
+	failed := map[string][]*buildv1beta1.BuildRun{}
+	for i := range buildRuns {
+		buildRun := &buildRuns[i]
+		if !buildRun.IsDone() || !buildRun.DeletionTimestamp.IsZero() || retains(buildRun) {
+			continue
+		}
+		name := buildRun.Spec.BuildName()
+		if buildRun.IsSuccessful() {
+			succeeded[name] = append(succeeded[name], buildRun)
+		} else {
+			failed[name] = append(failed[name], buildRun)
+		}
+	}
+
+	pruned := []Pruned{}
+	var next time.Duration
+	prune := func(groups map[string][]*buildv1beta1.BuildRun, limit *int32, ttl *time.Duration) {
+		for _, group := range groups {
+			// Newest first, so that the oldest BuildRuns exceed the limit
+			slices.SortFunc(group, func(a, b *buildv1beta1.BuildRun) int {
+				return completionTime(b).Compare(completionTime(a))
+			})
+			for i, buildRun := range group {
+				if limit != nil && i >= int(*limit) {
+					pruned = append(pruned, Pruned{BuildRun: buildRun, Reason: ReasonLimit})
+					continue
+				}
+				if ttl == nil {
+					continue
+				}
+				remaining := completionTime(buildRun).Add(*ttl).Sub(now)
+				if remaining <= 0 {
+					pruned = append(pruned, Pruned{BuildRun: buildRun, Reason: ReasonTTL})
+				} else if next == 0 || remaining < next {
+					next = remaining
+				}
+			}
+		}
+	}
+	prune(succeeded, p.SucceededLimit, p.TTLAfterSucceeded)
+	prune(failed, p.FailedLimit, p.TTLAfterFailed)
+	return pruned, next
+}
+
+// completionTime returns the completion time of the BuildRun, or its creation time for the
+// BuildRuns completed without one, such as the BuildRuns failing validation
+func completionTime(buildRun *buildv1beta1.BuildRun) time.Time {
+	if buildRun.Status.CompletionTime != nil {
+		return buildRun.Status.CompletionTime.Time
+	}
+	return buildRun.CreationTimestamp.Time
+}
@@ -0,0 +1,13 @@
+package pruning_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestPruning(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Pruning Suite")
+}
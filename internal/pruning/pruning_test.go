@@ -0,0 +1,142 @@
+package pruning_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/pruning"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Pruning", Label("pruning"), func() {
+	var (
+		owner     *openshiftv1alpha1.OpenShiftBuild
+		namespace *corev1.Namespace
+		now       time.Time
+	)
+
+	buildRun := func(name, build string, succeeded bool, age time.Duration) buildv1beta1.BuildRun {
+		buildRun := buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "team", Name: name},
+			Spec:       buildv1beta1.BuildRunSpec{Build: buildv1beta1.ReferencedBuild{Name: ptr.To(build)}},
+			Status:     buildv1beta1.BuildRunStatus{CompletionTime: &metav1.Time{Time: now.Add(-age)}},
+		}
+		status := corev1.ConditionFalse
+		if succeeded {
+			status = corev1.ConditionTrue
+		}
+		buildRun.Status.SetCondition(&buildv1beta1.Condition{Type: buildv1beta1.Succeeded, Status: status})
+		return buildRun
+	}
+	names := func(pruned []pruning.Pruned) []string {
+		names := []string{}
+		for _, p := range pruned {
+			names = append(names, p.BuildRun.Name+"/"+p.Reason)
+		}
+		return names
+	}
+	retainsNone := func(*buildv1beta1.BuildRun) bool { return false }
+
+	BeforeEach(func() {
+		now = time.Now()
+		owner = &openshiftv1alpha1.OpenShiftBuild{}
+		owner.Default()
+		owner.Spec.Shipwright.Pruning = &openshiftv1alpha1.ShipwrightPruning{
+			State:             openshiftv1alpha1.Enabled,
+			SucceededLimit:    ptr.To[int32](2),
+			FailedLimit:       ptr.To[int32](1),
+			TTLAfterSucceeded: &metav1.Duration{Duration: 24 * time.Hour},
+		}
+		namespace = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team"}}
+	})
+
+	Describe("PolicyFor", func() {
+		It("returns the cluster defaults", func() {
+			policy, err := pruning.PolicyFor(owner, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.SucceededLimit).To(HaveValue(BeEquivalentTo(2)))
+			Expect(policy.FailedLimit).To(HaveValue(BeEquivalentTo(1)))
+			Expect(policy.TTLAfterSucceeded).To(HaveValue(Equal(24 * time.Hour)))
+			Expect(policy.TTLAfterFailed).To(BeNil())
+		})
+
+		It("overrides the cluster defaults with the namespace annotations", func() {
+			namespace.Annotations = map[string]string{
+				pruning.SucceededLimitAnnotation: "10",
+				pruning.TTLAfterFailedAnnotation: "2h",
+			}
+			policy, err := pruning.PolicyFor(owner, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.SucceededLimit).To(HaveValue(BeEquivalentTo(10)))
+			Expect(policy.FailedLimit).To(HaveValue(BeEquivalentTo(1)))
+			Expect(policy.TTLAfterFailed).To(HaveValue(Equal(2 * time.Hour)))
+		})
+
+		It("ignores the invalid annotations", func() {
+			namespace.Annotations = map[string]string{
+				pruning.FailedLimitAnnotation:       "0",
+				pruning.TTLAfterSucceededAnnotation: "forever",
+			}
+			policy, err := pruning.PolicyFor(owner, namespace)
+			Expect(err).To(MatchError(And(ContainSubstring(pruning.FailedLimitAnnotation), ContainSubstring(pruning.TTLAfterSucceededAnnotation))))
+			Expect(policy.FailedLimit).To(HaveValue(BeEquivalentTo(1)))
+			Expect(policy.TTLAfterSucceeded).To(HaveValue(Equal(24 * time.Hour)))
+		})
+
+		It("is empty without cluster defaults nor annotations", func() {
+			owner.Spec.Shipwright.Pruning = nil
+			policy, err := pruning.PolicyFor(owner, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Empty()).To(BeTrue())
+		})
+	})
+
+	Describe("Prune", func() {
+		var policy pruning.Policy
+
+		BeforeEach(func() {
+			var err error
+			policy, err = pruning.PolicyFor(owner, namespace)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("prunes the oldest BuildRuns exceeding the limits of each Build", func() {
+			pruned, _ := policy.Prune([]buildv1beta1.BuildRun{
+				buildRun("app-1", "app", true, 3*time.Hour),
+				buildRun("app-2", "app", true, 2*time.Hour),
+				buildRun("app-3", "app", true, time.Hour),
+				buildRun("app-4", "app", false, 2*time.Hour),
+				buildRun("app-5", "app", false, time.Hour),
+				buildRun("api-1", "api", true, 3*time.Hour),
+			}, retainsNone, now)
+			Expect(names(pruned)).To(ConsistOf("app-1/limit", "app-4/limit"))
+		})
+
+		It("prunes the expired BuildRuns and returns the time until the next expiry", func() {
+			pruned, next := policy.Prune([]buildv1beta1.BuildRun{
+				buildRun("app-1", "app", true, 25*time.Hour),
+				buildRun("app-2", "app", true, 20*time.Hour),
+			}, retainsNone, now)
+			Expect(names(pruned)).To(ConsistOf("app-1/ttl"))
+			Expect(next).To(Equal(4 * time.Hour))
+		})
+
+		It("leaves the running and retained BuildRuns alone", func() {
+			running := buildRun("app-1", "app", true, 48*time.Hour)
+			running.Status.Conditions = nil
+			retained := buildRun("app-2", "app", true, 48*time.Hour)
+			retained.Spec.Retention = &buildv1beta1.BuildRunRetention{}
+			pruned, next := policy.Prune([]buildv1beta1.BuildRun{running, retained}, func(buildRun *buildv1beta1.BuildRun) bool {
+				return buildRun.Spec.Retention != nil
+			}, now)
+			Expect(pruned).To(BeEmpty())
+			Expect(next).To(BeZero())
+		})
+	})
+})
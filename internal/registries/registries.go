@@ -0,0 +1,221 @@
+package registries
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/manifestival/manifestival"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap holding the generated registries configuration,
+	// in the operand namespace and in every build namespace.
+	ConfigMapName = "openshift-builds-registries"
+
+	// ConfigMapKey is the key of the registries configuration in the ConfigMap.
+	ConfigMapKey = "registries.conf"
+
+	// VolumeName is the name of the build strategy volume holding the ConfigMap.
+	VolumeName = "openshift-builds-registries"
+
+	// MountPath is the drop-in directory where the ConfigMap is mounted in the build strategy
+	// steps. Its files are loaded on top of the registries.conf shipped in the step image. The
+	// ConfigMap is not mounted with a subPath, so that its updates reach the running steps and a
+	// missing ConfigMap leaves the directory empty.
+	MountPath = "/etc/containers/registries.conf.d"
+
+	// FileName is the name of the drop-in file holding the registries configuration in the
+	// mounted directory.
+	FileName = "99-openshift-builds-mirrors.conf"
+
+	// mirrorSourcePolicyNeverContactSource blocks pulls from the source when set on any mirror set.
+	mirrorSourcePolicyNeverContactSource = "NeverContactSource"
+)
+
+var (
+	// ImageDigestMirrorSetGVK is the kind of the cluster mirrors used for pulls by digest
+	ImageDigestMirrorSetGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ImageDigestMirrorSet"}
+
+	// ImageTagMirrorSetGVK is the kind of the cluster mirrors used for pulls by tag
+	ImageTagMirrorSetGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "ImageTagMirrorSet"}
+
+	// Strategies are the names of the build strategies pulling images with the containers
+	// libraries, and in which the registries configuration is mounted.
+	Strategies = []string{"buildah", "source-to-image", "buildpacks", "buildpacks-extender"}
+
+	// Labels identify the ConfigMaps managed by the operator.
+	Labels = map[string]string{
+		"app.kubernetes.io/managed-by": "openshift-builds-operator",
+		"app.kubernetes.io/component":  "registries",
+	}
+)
+
+type mirror struct {
+	location string
+	digest   bool
+	tag      bool
+}
+
+type registry struct {
+	source  string
+	blocked bool
+	mirrors []*mirror
+}
+
+// Render returns the registries.conf (v2) drop-in configuration translating the given
+// ImageDigestMirrorSet and ImageTagMirrorSet objects. Mirrors of the same source are merged,
+// in the order of the mirror set names.
+func Render(digestMirrorSets, tagMirrorSets []unstructured.Unstructured) (string, error) {
+	registries := map[string]*registry{}
+	add := func(sets []unstructured.Unstructured, field string, digest bool) error {
+		sets = slices.Clone(sets)
+		sort.Slice(sets, func(i, j int) bool { return sets[i].GetName() < sets[j].GetName() })
+		for _, set := range sets {
+			entries, _, err := unstructured.NestedSlice(set.Object, "spec", field)
+			if err != nil {
+				return fmt.Errorf("%s %s: %v", set.GetKind(), set.GetName(), err)
+			}
+			for _, e := range entries {
+				entry, ok := e.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				source, _, _ := unstructured.NestedString(entry, "source")
+				if source == "" {
+					continue
+				}
+				r, found := registries[source]
+				if !found {
+					r = &registry{source: source}
+					registries[source] = r
+				}
+				if policy, _, _ := unstructured.NestedString(entry, "mirrorSourcePolicy"); policy == mirrorSourcePolicyNeverContactSource {
+					r.blocked = true
+				}
+				locations, _, _ := unstructured.NestedStringSlice(entry, "mirrors")
+				for _, location := range locations {
+					i := slices.IndexFunc(r.mirrors, func(m *mirror) bool { return m.location == location })
+					if i < 0 {
+						r.mirrors = append(r.mirrors, &mirror{location: location})
+						i = len(r.mirrors) - 1
+					}
+					r.mirrors[i].digest = r.mirrors[i].digest || digest
+					r.mirrors[i].tag = r.mirrors[i].tag || !digest
+				}
+			}
+		}
+		return nil
+	}
+	if err := add(digestMirrorSets, "imageDigestMirrors", true); err != nil {
+		return "", err
+	}
+	if err := add(tagMirrorSets, "imageTagMirrors", false); err != nil {
+		return "", err
+	}
+
+	sources := make([]string, 0, len(registries))
+	for source := range registries {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	var b strings.Builder
+	b.WriteString("# Generated by the OpenShift Builds operator from the cluster ImageDigestMirrorSets and ImageTagMirrorSets.\n")
+	for _, source := range sources {
+		r := registries[source]
+		b.WriteString("\n[[registry]]\n")
+		if strings.HasPrefix(source, "*.") {
+			fmt.Fprintf(&b, "  prefix = %s\n", strconv.Quote(source))
+		} else {
+			fmt.Fprintf(&b, "  prefix = \"\"\n  location = %s\n", strconv.Quote(source))
+		}
+		if r.blocked {
+			b.WriteString("  blocked = true\n")
+		}
+		for _, m := range r.mirrors {
+			fmt.Fprintf(&b, "\n  [[registry.mirror]]\n    location = %s\n", strconv.Quote(m.location))
+			switch {
+			case m.digest && !m.tag:
+				b.WriteString("    pull-from-mirror = \"digest-only\"\n")
+			case m.tag && !m.digest:
+				b.WriteString("    pull-from-mirror = \"tag-only\"\n")
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// InjectRegistriesConf is a Manifestival transformer mounting the registries configuration in
+// every step of the given build strategies. The volume is optional, so that builds keep running
+// in namespaces the ConfigMap has not been mirrored to yet.
+func InjectRegistriesConf(strategies ...string) manifestival.Transformer {
+	return func(object *unstructured.Unstructured) error {
+		if object.GetKind() != "ClusterBuildStrategy" && object.GetKind() != "BuildStrategy" {
+			return nil
+		}
+		if !slices.Contains(strategies, object.GetName()) {
+			return nil
+		}
+
+		volumes, _, err := unstructured.NestedSlice(object.Object, "spec", "volumes")
+		if err != nil {
+			return err
+		}
+		if !slices.ContainsFunc(volumes, hasName(VolumeName)) {
+			volumes = append(volumes, map[string]interface{}{
+				"name":        VolumeName,
+				"overridable": false,
+				"description": "Registries configuration generated from the cluster image mirror sets",
+				"configMap": map[string]interface{}{
+					"name":     ConfigMapName,
+					"optional": true,
+					"items": []interface{}{
+						map[string]interface{}{"key": ConfigMapKey, "path": FileName},
+					},
+				},
+			})
+			if err := unstructured.SetNestedSlice(object.Object, volumes, "spec", "volumes"); err != nil {
+				return err
+			}
+		}
+
+		steps, _, err := unstructured.NestedSlice(object.Object, "spec", "steps")
+		if err != nil {
+			return err
+		}
+		for i := range steps {
+			step, ok := steps[i].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			mounts, _, err := unstructured.NestedSlice(step, "volumeMounts")
+			if err != nil {
+				return err
+			}
+			if slices.ContainsFunc(mounts, hasName(VolumeName)) {
+				continue
+			}
+			mounts = append(mounts, map[string]interface{}{
+				"name":      VolumeName,
+				"mountPath": MountPath,
+				"readOnly":  true,
+			})
+			if err := unstructured.SetNestedSlice(step, mounts, "volumeMounts"); err != nil {
+				return err
+			}
+		}
+		return unstructured.SetNestedSlice(object.Object, steps, "spec", "steps")
+	}
+}
+
+func hasName(name string) func(interface{}) bool {
+	return func(item interface{}) bool {
+		m, ok := item.(map[string]interface{})
+		return ok && m["name"] == name
+	}
+}
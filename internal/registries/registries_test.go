@@ -0,0 +1,151 @@
+package registries_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/registries"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const digestMirrorSet = `
+apiVersion: config.openshift.io/v1
+kind: ImageDigestMirrorSet
+metadata:
+  name: redhat
+spec:
+  imageDigestMirrors:
+  - source: registry.redhat.io/ubi10
+    mirrors:
+    - mirror.example.com/ubi10
+    - backup.example.com/ubi10
+    mirrorSourcePolicy: NeverContactSource
+  - source: "*.quay.io"
+    mirrors:
+    - mirror.example.com/quay
+`
+
+const tagMirrorSet = `
+apiVersion: config.openshift.io/v1
+kind: ImageTagMirrorSet
+metadata:
+  name: redhat
+spec:
+  imageTagMirrors:
+  - source: registry.redhat.io/ubi10
+    mirrors:
+    - mirror.example.com/ubi10
+  - source: docker.io/library
+    mirrors:
+    - mirror.example.com/library
+`
+
+const strategy = `
+apiVersion: shipwright.io/v1beta1
+kind: ClusterBuildStrategy
+metadata:
+  name: buildah
+spec:
+  steps:
+  - name: build-and-push
+    image: registry.redhat.io/ubi10/buildah:latest
+    volumeMounts:
+    - name: buildah-images
+      mountPath: /var/lib/containers/storage
+  volumes:
+  - name: buildah-images
+    emptyDir: {}
+`
+
+func parse(manifest string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	Expect(yaml.Unmarshal([]byte(manifest), &u.Object)).To(Succeed())
+	return u
+}
+
+var _ = Describe("Registries", Label("registries"), func() {
+
+	Describe("Render", func() {
+		It("renders an empty configuration without mirror sets", func() {
+			content, err := registries.Render(nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(HavePrefix("# Generated by the OpenShift Builds operator"))
+			Expect(content).NotTo(ContainSubstring("[[registry]]"))
+		})
+
+		It("merges the digest and tag mirrors by source", func() {
+			content, err := registries.Render(
+				[]unstructured.Unstructured{*parse(digestMirrorSet)},
+				[]unstructured.Unstructured{*parse(tagMirrorSet)},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(ContainSubstring(`
+[[registry]]
+  prefix = "*.quay.io"
+
+  [[registry.mirror]]
+    location = "mirror.example.com/quay"
+    pull-from-mirror = "digest-only"
+`))
+			Expect(content).To(ContainSubstring(`
+[[registry]]
+  prefix = ""
+  location = "docker.io/library"
+
+  [[registry.mirror]]
+    location = "mirror.example.com/library"
+    pull-from-mirror = "tag-only"
+`))
+			Expect(content).To(ContainSubstring(`
+[[registry]]
+  prefix = ""
+  location = "registry.redhat.io/ubi10"
+  blocked = true
+
+  [[registry.mirror]]
+    location = "mirror.example.com/ubi10"
+
+  [[registry.mirror]]
+    location = "backup.example.com/ubi10"
+    pull-from-mirror = "digest-only"
+`))
+		})
+	})
+
+	Describe("InjectRegistriesConf", func() {
+		It("mounts the registries configuration in the steps of the given strategies", func() {
+			object := parse(strategy)
+			transformer := registries.InjectRegistriesConf(registries.Strategies...)
+			Expect(transformer(object)).To(Succeed())
+			// The transformer is idempotent
+			Expect(transformer(object)).To(Succeed())
+
+			volumes, _, _ := unstructured.NestedSlice(object.Object, "spec", "volumes")
+			Expect(volumes).To(HaveLen(2))
+			Expect(volumes[1]).To(HaveKeyWithValue("name", registries.VolumeName))
+			Expect(volumes[1]).To(HaveKeyWithValue("configMap", map[string]interface{}{
+				"name":     registries.ConfigMapName,
+				"optional": true,
+				"items": []interface{}{
+					map[string]interface{}{"key": registries.ConfigMapKey, "path": registries.FileName},
+				},
+			}))
+
+			steps, _, _ := unstructured.NestedSlice(object.Object, "spec", "steps")
+			mounts, _, _ := unstructured.NestedSlice(steps[0].(map[string]interface{}), "volumeMounts")
+			Expect(mounts).To(HaveLen(2))
+			Expect(mounts[1]).To(Equal(map[string]interface{}{
+				"name":      registries.VolumeName,
+				"mountPath": registries.MountPath,
+				"readOnly":  true,
+			}))
+		})
+
+		It("ignores other strategies", func() {
+			object := parse(strategy)
+			Expect(registries.InjectRegistriesConf("buildpacks")(object)).To(Succeed())
+			Expect(object.Object).To(Equal(parse(strategy).Object))
+		})
+	})
+})
@@ -2,22 +2,38 @@ package sharedresource
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/go-logr/logr"
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
 	"github.com/manifestival/manifestival"
 	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/images"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ComponentName is the name of the SharedResource component
+const ComponentName = "SharedResource"
+
+var (
+	_ component.Component   = &SharedResource{}
+	_ component.Relocatable = &SharedResource{}
+)
+
 // SharedResource type defines methods to Get, Create v1alpha1.SharedResource resource
 type SharedResource struct {
-	Client   client.Client
-	Logger   logr.Logger
-	Manifest manifestival.Manifest
-	State    openshiftv1alpha1.State
+	Client       client.Client
+	Logger       logr.Logger
+	Manifest     manifestival.Manifest
+	ManifestPath string
+	State        openshiftv1alpha1.State
+
+	// Images overrides the images of the Shared Resource CSI Driver.
+	Images *images.Overrides
 }
 
 // New creates new instance of SharedResource type
@@ -28,29 +44,46 @@ func New(client client.Client, manifest manifestival.Manifest) *SharedResource {
 	}
 }
 
-// UpdateSharedResource transforms the manifests, and applies or deletes them based on SharedResource.State.
-func (sr *SharedResource) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
-	logger := sr.Logger.WithValues("name", owner.Name)
+// Name returns the component name
+func (sr *SharedResource) Name() string {
+	return ComponentName
+}
 
-	if owner.Spec.SharedResource == nil {
-		owner.Spec.SharedResource = &openshiftv1alpha1.SharedResource{
-			State: openshiftv1alpha1.Enabled,
-		}
-		if err := sr.Client.Update(ctx, owner); err != nil {
-			return fmt.Errorf("failed to update OpenShiftBuild with default values: %v", err)
-		}
+// Setup initializes the manifestival to apply Shared Resources
+func (sr *SharedResource) Setup(mgr ctrl.Manager) error {
+	if sr.Client == nil {
+		sr.Client = mgr.GetClient()
 	}
-	sr.State = owner.Spec.SharedResource.State
 
-	// Applying transformers
-	transformerfuncs := []manifestival.Transformer{}
-	transformerfuncs = append(transformerfuncs, manifestival.InjectOwner(owner))
-	transformerfuncs = append(transformerfuncs, manifestival.InjectNamespace(common.OpenShiftBuildNamespaceName))
-	if sr.State == openshiftv1alpha1.Enabled && owner.DeletionTimestamp.IsZero() {
-		transformerfuncs = append(transformerfuncs, common.InjectFinalizer(common.OpenShiftBuildFinalizerName))
+	// Initialize Manifestival
+	manifestivalOptions := []manifestival.Option{
+		manifestival.UseLogger(sr.Logger),
+		manifestival.UseClient(manifestivalclient.NewClient(sr.Client)),
 	}
 
-	manifest, err := sr.Manifest.Transform(transformerfuncs...)
+	// Shared Resource manifests
+	sharedManifestPath := common.SharedResourceManifestPath
+	if sr.ManifestPath != "" {
+		sharedManifestPath = sr.ManifestPath
+	}
+	manifest, err := manifestival.NewManifest(sharedManifestPath, manifestivalOptions...)
+	if err != nil {
+		return err
+	}
+	if manifest, err = manifest.Transform(sr.Images.Transformer()); err != nil {
+		return err
+	}
+	sr.Manifest = manifest
+	return nil
+}
+
+// Reconcile transforms the manifests, and applies or deletes them based on SharedResource.State.
+func (sr *SharedResource) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := sr.Logger.WithValues("name", owner.Name)
+
+	sr.State = owner.Spec.SharedResource.State
+
+	manifest, err := sr.transform(owner)
 	if err != nil {
 		logger.Error(err, "transforming manifest")
 		return err
@@ -66,6 +99,66 @@ func (sr *SharedResource) Reconcile(ctx context.Context, owner *openshiftv1alpha
 	return manifest.Apply()
 }
 
+// Delete removes the finalizers from the Shared Resources, leaving their deletion to the
+// garbage collector, or deletes them explicitly if SharedResource is disabled.
+func (sr *SharedResource) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if owner.Spec.SharedResource != nil {
+		sr.State = owner.Spec.SharedResource.State
+	}
+
+	manifest, err := sr.transform(owner)
+	if err != nil {
+		sr.Logger.Error(err, "transforming manifest", "name", owner.Name)
+		return err
+	}
+	return sr.deleteManifests(&manifest)
+}
+
+// Relocate deletes the Shared Resources deployed in the previous operand namespace
+func (sr *SharedResource) Relocate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, previous string) error {
+	previousOwner := owner.DeepCopy()
+	previousOwner.Spec.Namespace = previous
+	manifest, err := sr.transform(previousOwner)
+	if err != nil {
+		return err
+	}
+	sr.Logger.Info("Deleting Shared Resources of the previous operand namespace", "namespace", previous)
+	return common.DeleteOperands(manifest, previous, owner)
+}
+
+// Status reports whether the Shared Resources are deployed
+func (sr *SharedResource) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    component.ConditionType(sr),
+		Status:  metav1.ConditionTrue,
+		Reason:  "Reconciled",
+		Message: "Shared Resource CSI Driver is deployed",
+	}
+	if owner.Spec.SharedResource != nil && owner.Spec.SharedResource.State == openshiftv1alpha1.Disabled {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disabled"
+		condition.Message = "Shared Resource CSI Driver is disabled"
+	}
+	return condition
+}
+
+// WatchedTypes returns nil as changes to Shared Resources do not trigger a reconciliation
+func (sr *SharedResource) WatchedTypes() []client.Object {
+	return nil
+}
+
+// transform applies the owner, namespace and finalizer transformers to the manifests
+func (sr *SharedResource) transform(owner *openshiftv1alpha1.OpenShiftBuild) (manifestival.Manifest, error) {
+	transformerfuncs := []manifestival.Transformer{}
+	transformerfuncs = append(transformerfuncs, manifestival.InjectOwner(owner))
+	transformerfuncs = append(transformerfuncs, manifestival.InjectNamespace(common.OperandNamespace(owner)))
+	transformerfuncs = append(transformerfuncs, common.InjectServiceMonitorNamespace(common.OperandNamespace(owner)))
+	if sr.State == openshiftv1alpha1.Enabled && owner.DeletionTimestamp.IsZero() {
+		transformerfuncs = append(transformerfuncs, common.InjectFinalizer(common.OpenShiftBuildFinalizerName))
+	}
+	return sr.Manifest.Transform(transformerfuncs...)
+}
+
 // deleteManifests removes the applied finalizer from all manifest.Resources &
 // performs deletion of the resources if SharedResource.State is disabled.
 func (sr *SharedResource) deleteManifests(manifest *manifestival.Manifest) error {
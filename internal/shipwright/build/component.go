@@ -0,0 +1,170 @@
+package build
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/rollout"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ComponentName is the name of the Shipwright Build component
+const ComponentName = "ShipwrightBuild"
+
+var (
+	_ component.Component  = &Component{}
+	_ component.Degradable = &Component{}
+	_ component.Watcher    = &Component{}
+)
+
+// Component manages the v1alpha1.ShipwrightBuild resource as a component of OpenShiftBuild
+type Component struct {
+	*ShipwrightBuild
+}
+
+// NewComponent creates new instance of Component type. The target namespace of the
+// ShipwrightBuild is resolved from the owner on every reconciliation, and is not kept by the
+// component.
+func NewComponent(client client.Client) *Component {
+	return &Component{
+		ShipwrightBuild: New(client, ""),
+	}
+}
+
+// Name returns the component name
+func (c *Component) Name() string {
+	return ComponentName
+}
+
+// Setup is a no-op as the ShipwrightBuild manifests are applied by the ShipwrightBuild controller
+func (c *Component) Setup(mgr ctrl.Manager) error {
+	if c.Client == nil {
+		c.Client = mgr.GetClient()
+	}
+	return nil
+}
+
+// Reconcile creates or deletes ShipwrightBuild object
+func (c *Component) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := log.FromContext(ctx).WithValues("name", owner.Name)
+
+	switch owner.Spec.Shipwright.Build.State {
+	case openshiftv1alpha1.Enabled:
+		result, err := New(c.Client, common.OperandNamespace(owner)).CreateOrUpdate(ctx, owner)
+		if err != nil {
+			return err
+		}
+		logger.Info("ShipwrightBuild resource", "result", result)
+	case openshiftv1alpha1.Disabled:
+		if err := c.ShipwrightBuild.Delete(ctx, owner); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("ShipwrightBuild resource", "result", "deleted")
+	default:
+		return errors.New("unknown component state")
+	}
+
+	return nil
+}
+
+// Delete deletes the ShipwrightBuild object, ignoring it if not found
+func (c *Component) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if err := c.ShipwrightBuild.Delete(ctx, owner); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// Status mirrors the Ready condition of the ShipwrightBuild object
+func (c *Component) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	condition := metav1.Condition{
+		Type: component.ConditionType(c),
+	}
+	if owner.Spec.Shipwright != nil && owner.Spec.Shipwright.Build != nil &&
+		owner.Spec.Shipwright.Build.State == openshiftv1alpha1.Disabled {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disabled"
+		condition.Message = "Shipwright Build is disabled"
+		return condition
+	}
+
+	object, err := c.Get(ctx, owner)
+	if err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "NotFound"
+		condition.Message = fmt.Sprintf("Failed to get ShipwrightBuild: %v", err)
+		return condition
+	}
+
+	ready := apimeta.FindStatusCondition(object.Status.Conditions, openshiftv1alpha1.ConditionReady)
+	if ready == nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "Initializing"
+		condition.Message = "Waiting for ShipwrightBuild to report status"
+		return condition
+	}
+	condition.Status = ready.Status
+	condition.Reason = ready.Reason
+	condition.Message = ready.Message
+	return condition
+}
+
+// WatchedTypes returns the ShipwrightBuild type owned by OpenShiftBuild
+func (c *Component) WatchedTypes() []client.Object {
+	return []client.Object{&shipwrightv1alpha1.ShipwrightBuild{}}
+}
+
+// Degraded mirrors the Degraded condition of the ShipwrightBuild object, set when a release
+// failed its verification
+func (c *Component) Degraded(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) string {
+	if owner.Spec.Shipwright == nil || owner.Spec.Shipwright.Build == nil ||
+		owner.Spec.Shipwright.Build.State != openshiftv1alpha1.Enabled {
+		return ""
+	}
+	object, err := c.Get(ctx, owner)
+	if err != nil {
+		return ""
+	}
+	degraded := apimeta.FindStatusCondition(object.Status.Conditions, rollout.ConditionDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue {
+		return ""
+	}
+	return degraded.Message
+}
+
+// Watches returns the ShipwrightBuild objects, whose Degraded condition is reported by the
+// OpenShiftBuild
+func (c *Component) Watches() []component.Watch {
+	degradedChanged := predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*shipwrightv1alpha1.ShipwrightBuild)
+			object, ok2 := e.ObjectNew.(*shipwrightv1alpha1.ShipwrightBuild)
+			if !ok || !ok2 {
+				return false
+			}
+			before := apimeta.FindStatusCondition(old.Status.Conditions, rollout.ConditionDegraded)
+			after := apimeta.FindStatusCondition(object.Status.Conditions, rollout.ConditionDegraded)
+			return (before == nil) != (after == nil) ||
+				(before != nil && (before.Status != after.Status || before.Message != after.Message))
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	return []component.Watch{{
+		Object:     &shipwrightv1alpha1.ShipwrightBuild{},
+		Predicates: []predicate.Predicate{degradedChanged},
+	}}
+}
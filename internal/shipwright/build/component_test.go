@@ -0,0 +1,61 @@
+package build_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/build"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/rollout"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Component", Label("shipwright", "build", "component"), func() {
+	var (
+		ctx            context.Context
+		c              *build.Component
+		openShiftBuild *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		componentScheme := runtime.NewScheme()
+		Expect(shipwrightv1alpha1.AddToScheme(componentScheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(componentScheme)).To(Succeed())
+		openShiftBuild = &openshiftv1alpha1.OpenShiftBuild{ObjectMeta: metav1.ObjectMeta{Name: "cluster", UID: "uid"}}
+		openShiftBuild.Default()
+		c = build.NewComponent(fake.NewClientBuilder().WithScheme(componentScheme).
+			WithStatusSubresource(&shipwrightv1alpha1.ShipwrightBuild{}).Build())
+		Expect(c.Reconcile(ctx, openShiftBuild)).To(Succeed())
+	})
+
+	setDegraded := func(status metav1.ConditionStatus, message string) {
+		object, err := c.Get(ctx, openShiftBuild)
+		Expect(err).NotTo(HaveOccurred())
+		apimeta.SetStatusCondition(&object.Status.Conditions, metav1.Condition{
+			Type:    rollout.ConditionDegraded,
+			Status:  status,
+			Reason:  rollout.ReasonRolledBack,
+			Message: message,
+		})
+		Expect(c.Client.Status().Update(ctx, object)).To(Succeed())
+	}
+
+	It("is not degraded without a Degraded condition", func() {
+		Expect(c.Degraded(ctx, openShiftBuild)).To(BeEmpty())
+	})
+
+	It("mirrors the Degraded condition of the ShipwrightBuild", func() {
+		setDegraded(metav1.ConditionTrue, "Rolled back to release 0123456789abcdef")
+		Expect(c.Degraded(ctx, openShiftBuild)).To(Equal("Rolled back to release 0123456789abcdef"))
+
+		setDegraded(metav1.ConditionFalse, "Release fedcba9876543210 is verified")
+		Expect(c.Degraded(ctx, openShiftBuild)).To(BeEmpty())
+	})
+})
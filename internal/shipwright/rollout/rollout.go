@@ -0,0 +1,227 @@
+package rollout
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ConditionDegraded is set on the ShipwrightBuild when a release failed its verification
+const ConditionDegraded = "Degraded"
+
+// Reasons of the Degraded condition
+const (
+	ReasonAsExpected         = "AsExpected"
+	ReasonRolledBack         = "RolledBack"
+	ReasonVerificationFailed = "VerificationFailed"
+)
+
+// StateName is the name of the ConfigMap holding the rollout state in the operand namespace
+const StateName = "shipwright-build-rollout"
+
+// Keys of the rollout state ConfigMap
+const (
+	verifiedKey    = "verified"
+	progressingKey = "progressing"
+	startedKey     = "started"
+	failedKey      = "failed"
+	attemptsKey    = "attempts"
+	retryKey       = "retry"
+	manifestsKey   = "manifests.json.gz"
+)
+
+// Timeout is the time a release has to pass its verification before it is rolled back
+var Timeout = 10 * time.Minute
+
+// PollInterval is the interval at which a release being verified is checked
+var PollInterval = 10 * time.Second
+
+// RetryInterval is the delay before verifying again a release which failed its verification
+// without a verified release to roll back to. It doubles with each failed attempt, up to
+// MaxRetryInterval.
+var RetryInterval = time.Minute
+
+// MaxRetryInterval is the maximum delay between the verifications of a release
+var MaxRetryInterval = time.Hour
+
+// RetryDelay returns the delay before the next verification of a release after its failed
+// attempts
+func RetryDelay(attempts int) time.Duration {
+	delay := RetryInterval
+	for i := 1; i < attempts && delay < MaxRetryInterval; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxRetryInterval)
+}
+
+// Set is a set of rendered resources applied together, identified by their hash
+type Set struct {
+	// Revision is the hash of the resources.
+	Revision string
+
+	// Resources are the rendered resources.
+	Resources []unstructured.Unstructured
+}
+
+// NewSet returns the set of the resources. Their revision only depends on their content.
+func NewSet(resources ...[]unstructured.Unstructured) (Set, error) {
+	set := Set{}
+	for _, r := range resources {
+		set.Resources = append(set.Resources, r...)
+	}
+	data, err := json.Marshal(set.Resources)
+	if err != nil {
+		return set, fmt.Errorf("failed to serialize the resources: %v", err)
+	}
+	hash := sha256.Sum256(data)
+	set.Revision = hex.EncodeToString(hash[:])[:16]
+	return set, nil
+}
+
+// State is the rollout state of the Shipwright Build releases
+type State struct {
+	// Verified is the last release which passed its verification.
+	Verified Set
+
+	// Progressing is the revision of the release being verified since Started.
+	Progressing string
+	Started     time.Time
+
+	// Failed is the revision of the last release which failed its verification. With a
+	// verified release to roll back to, it is not applied again until the desired release
+	// changes. Otherwise, it is verified again at RetryAt.
+	Failed string
+
+	// Attempts counts the failed verifications of the Failed release.
+	Attempts int
+	RetryAt  time.Time
+}
+
+// Load returns the rollout state stored in the namespace, or an empty state if there is none
+func Load(ctx context.Context, reader client.Reader, namespace string) (*State, error) {
+	state := &State{}
+	configMap := &corev1.ConfigMap{}
+	err := reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: StateName}, configMap)
+	if err != nil {
+		return state, client.IgnoreNotFound(err)
+	}
+	state.Verified.Revision = configMap.Data[verifiedKey]
+	state.Progressing = configMap.Data[progressingKey]
+	state.Failed = configMap.Data[failedKey]
+	if started, ok := configMap.Data[startedKey]; ok {
+		if state.Started, err = time.Parse(time.RFC3339, started); err != nil {
+			return nil, fmt.Errorf("invalid start time of the rollout %q: %v", started, err)
+		}
+	}
+	if attempts, ok := configMap.Data[attemptsKey]; ok {
+		if state.Attempts, err = strconv.Atoi(attempts); err != nil {
+			return nil, fmt.Errorf("invalid verification attempts of the rollout %q: %v", attempts, err)
+		}
+	}
+	if retry, ok := configMap.Data[retryKey]; ok {
+		if state.RetryAt, err = time.Parse(time.RFC3339, retry); err != nil {
+			return nil, fmt.Errorf("invalid retry time of the rollout %q: %v", retry, err)
+		}
+	}
+	if data, ok := configMap.BinaryData[manifestsKey]; ok {
+		if state.Verified.Resources, err = decode(data); err != nil {
+			return nil, fmt.Errorf("invalid manifests of the verified release: %v", err)
+		}
+	}
+	return state, nil
+}
+
+// Save stores the rollout state in the namespace, in a ConfigMap owned by the owner. The
+// ConfigMap is read with the reader, as ConfigMaps are not cached.
+func Save(ctx context.Context, c client.Client, reader client.Reader, namespace string, owner client.Object, state *State) error {
+	data, err := encode(state.Verified.Resources)
+	if err != nil {
+		return fmt.Errorf("failed to encode the manifests of the verified release: %v", err)
+	}
+	configMap := &corev1.ConfigMap{}
+	err = reader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: StateName}, configMap)
+	if client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to get the rollout state: %v", err)
+	}
+	found := err == nil
+	if !found {
+		configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: StateName}}
+	}
+	configMap.Data = map[string]string{
+		verifiedKey:    state.Verified.Revision,
+		progressingKey: state.Progressing,
+		failedKey:      state.Failed,
+	}
+	if !state.Started.IsZero() {
+		configMap.Data[startedKey] = state.Started.UTC().Format(time.RFC3339)
+	}
+	if state.Attempts > 0 {
+		configMap.Data[attemptsKey] = strconv.Itoa(state.Attempts)
+	}
+	if !state.RetryAt.IsZero() {
+		configMap.Data[retryKey] = state.RetryAt.UTC().Format(time.RFC3339)
+	}
+	configMap.BinaryData = map[string][]byte{manifestsKey: data}
+	if err := controllerutil.SetControllerReference(owner, configMap, c.Scheme()); err != nil {
+		return err
+	}
+	if found {
+		err = c.Update(ctx, configMap)
+	} else {
+		err = c.Create(ctx, configMap)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save the rollout state: %v", err)
+	}
+	return nil
+}
+
+// encode returns the gzipped JSON of the resources, keeping the state under the size limit of
+// the ConfigMaps
+func encode(resources []unstructured.Unstructured) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	if err := json.NewEncoder(writer).Encode(resources); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// decode returns the resources of their gzipped JSON
+func decode(data []byte) ([]unstructured.Unstructured, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	objects := []map[string]interface{}{}
+	if err := json.Unmarshal(content, &objects); err != nil {
+		return nil, err
+	}
+	resources := make([]unstructured.Unstructured, 0, len(objects))
+	for _, object := range objects {
+		resources = append(resources, unstructured.Unstructured{Object: object})
+	}
+	return resources, nil
+}
@@ -0,0 +1,256 @@
+package rollout_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/rollout"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	shipwrightv1alpha1 "github.com/shipwright-io/operator/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const namespace = "openshift-builds"
+
+// resource returns an unstructured object of the kind
+func resource(kind, name string, spec map[string]interface{}) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	u.SetAPIVersion("v1")
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+var _ = Describe("Rollout", func() {
+	var (
+		ctx        context.Context
+		fakeClient client.Client
+		owner      *shipwrightv1alpha1.ShipwrightBuild
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(shipwrightv1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		owner = &shipwrightv1alpha1.ShipwrightBuild{ObjectMeta: metav1.ObjectMeta{Name: "cluster", UID: "uid"}}
+		fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(owner).
+			WithStatusSubresource(&buildv1beta1.BuildRun{}).Build()
+	})
+
+	Describe("NewSet", func() {
+		It("identifies the resources by their content", func() {
+			first, err := rollout.NewSet([]unstructured.Unstructured{resource("Service", "webhook", map[string]interface{}{"a": "b"})})
+			Expect(err).NotTo(HaveOccurred())
+			same, err := rollout.NewSet([]unstructured.Unstructured{resource("Service", "webhook", map[string]interface{}{"a": "b"})})
+			Expect(err).NotTo(HaveOccurred())
+			changed, err := rollout.NewSet([]unstructured.Unstructured{resource("Service", "webhook", map[string]interface{}{"a": "c"})})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(first.Revision).NotTo(BeEmpty())
+			Expect(same.Revision).To(Equal(first.Revision))
+			Expect(changed.Revision).NotTo(Equal(first.Revision))
+		})
+	})
+
+	Describe("RetryDelay", func() {
+		It("doubles with each attempt up to the maximum", func() {
+			Expect(rollout.RetryDelay(1)).To(Equal(rollout.RetryInterval))
+			Expect(rollout.RetryDelay(3)).To(Equal(4 * rollout.RetryInterval))
+			Expect(rollout.RetryDelay(100)).To(Equal(rollout.MaxRetryInterval))
+		})
+	})
+
+	Describe("State", func() {
+		It("is empty when it was never saved", func() {
+			state, err := rollout.Load(ctx, fakeClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*state).To(Equal(rollout.State{}))
+		})
+
+		It("round-trips through the ConfigMap", func() {
+			verified, err := rollout.NewSet([]unstructured.Unstructured{resource("Service", "webhook", map[string]interface{}{"a": "b"})})
+			Expect(err).NotTo(HaveOccurred())
+			state := &rollout.State{
+				Verified:    verified,
+				Progressing: "next",
+				Started:     time.Now().Truncate(time.Second),
+			}
+			Expect(rollout.Save(ctx, fakeClient, fakeClient, namespace, owner, state)).To(Succeed())
+			state.Failed = "next"
+			state.Attempts = 2
+			state.RetryAt = state.Started.Add(time.Minute)
+			Expect(rollout.Save(ctx, fakeClient, fakeClient, namespace, owner, state)).To(Succeed())
+
+			loaded, err := rollout.Load(ctx, fakeClient, namespace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loaded.Verified.Revision).To(Equal(verified.Revision))
+			Expect(loaded.Verified.Resources).To(Equal(verified.Resources))
+			Expect(loaded.Progressing).To(Equal("next"))
+			Expect(loaded.Failed).To(Equal("next"))
+			Expect(loaded.Started.Equal(state.Started)).To(BeTrue())
+			Expect(loaded.Attempts).To(Equal(2))
+			Expect(loaded.RetryAt.Equal(state.RetryAt)).To(BeTrue())
+
+			configMap := &corev1.ConfigMap{}
+			Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: rollout.StateName}, configMap)).To(Succeed())
+			Expect(metav1.IsControlledBy(configMap, owner)).To(BeTrue())
+		})
+	})
+
+	Describe("Verifier", func() {
+		var (
+			verifier *rollout.Verifier
+			set      rollout.Set
+		)
+
+		BeforeEach(func() {
+			verifier = &rollout.Verifier{
+				Client:    fakeClient,
+				APIReader: fakeClient,
+				Images:    images.New(map[string]string{"self_test": "mirror.example.com/ubi@sha256:0123456789abcdef0123456789abcdef"}, false),
+			}
+			var err error
+			set, err = rollout.NewSet([]unstructured.Unstructured{
+				resource("Deployment", "shipwright-build-controller", nil),
+				resource("Service", "shp-build-webhook", nil),
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		verify := func() (rollout.Result, string) {
+			result, message, err := verifier.Verify(ctx, set, namespace, owner)
+			Expect(err).NotTo(HaveOccurred())
+			return result, message
+		}
+
+		createDeployment := func(available int32) *appsv1.Deployment {
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "shipwright-build-controller", Generation: 1},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](1)},
+			}
+			Expect(fakeClient.Create(ctx, deployment)).To(Succeed())
+			deployment.Status = appsv1.DeploymentStatus{
+				ObservedGeneration: deployment.Generation,
+				Replicas:           1,
+				UpdatedReplicas:    1,
+				AvailableReplicas:  available,
+			}
+			Expect(fakeClient.Status().Update(ctx, deployment)).To(Succeed())
+			return deployment
+		}
+
+		createEndpoints := func() {
+			Expect(fakeClient.Create(ctx, &discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      "shp-build-webhook-abcde",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "shp-build-webhook"},
+				},
+				AddressType: discoveryv1.AddressTypeIPv4,
+				Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}}},
+			})).To(Succeed())
+		}
+
+		completeSelfTest := func(status corev1.ConditionStatus) {
+			buildRuns := &buildv1beta1.BuildRunList{}
+			Expect(fakeClient.List(ctx, buildRuns, client.InNamespace(namespace))).To(Succeed())
+			Expect(buildRuns.Items).To(HaveLen(1))
+			buildRun := &buildRuns.Items[0]
+			buildRun.Status.Conditions = buildv1beta1.Conditions{{
+				Type:    buildv1beta1.Succeeded,
+				Status:  status,
+				Message: "step self-test exited",
+			}}
+			Expect(fakeClient.Status().Update(ctx, buildRun)).To(Succeed())
+		}
+
+		It("waits for the Deployments to be available", func() {
+			createDeployment(0)
+			result, message := verify()
+			Expect(result).To(Equal(rollout.Pending))
+			Expect(message).To(ContainSubstring("shipwright-build-controller"))
+		})
+
+		It("fails when a Deployment exceeds its progress deadline", func() {
+			deployment := createDeployment(0)
+			deployment.Status.Conditions = []appsv1.DeploymentCondition{{
+				Type:    appsv1.DeploymentProgressing,
+				Status:  corev1.ConditionFalse,
+				Reason:  "ProgressDeadlineExceeded",
+				Message: "ReplicaSet has timed out progressing",
+			}}
+			Expect(fakeClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			result, message := verify()
+			Expect(result).To(Equal(rollout.Failed))
+			Expect(message).To(ContainSubstring("timed out progressing"))
+		})
+
+		It("waits for the Services to have a ready endpoint", func() {
+			createDeployment(1)
+			result, message := verify()
+			Expect(result).To(Equal(rollout.Pending))
+			Expect(message).To(ContainSubstring("shp-build-webhook"))
+		})
+
+		When("the controller and webhook are available", func() {
+			BeforeEach(func() {
+				createDeployment(1)
+				createEndpoints()
+			})
+
+			It("runs the self-test BuildRun", func() {
+				result, _ := verify()
+				Expect(result).To(Equal(rollout.Pending))
+
+				strategy := &buildv1beta1.BuildStrategy{}
+				Expect(fakeClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: rollout.SelfTestName}, strategy)).To(Succeed())
+				Expect(strategy.Spec.Steps).To(HaveLen(1))
+				Expect(strategy.Spec.Steps[0].Image).To(Equal("mirror.example.com/ubi@sha256:0123456789abcdef0123456789abcdef"))
+				Expect(metav1.IsControlledBy(strategy, owner)).To(BeTrue())
+
+				completeSelfTest(corev1.ConditionTrue)
+				result, _ = verify()
+				Expect(result).To(Equal(rollout.Verified))
+			})
+
+			It("fails when the self-test BuildRun fails", func() {
+				verify()
+				completeSelfTest(corev1.ConditionFalse)
+				result, message := verify()
+				Expect(result).To(Equal(rollout.Failed))
+				Expect(message).To(ContainSubstring("step self-test exited"))
+			})
+
+			It("cleans up the self-test objects", func() {
+				verify()
+				Expect(verifier.Cleanup(ctx, namespace, set.Revision)).To(Succeed())
+				Expect(verifier.Cleanup(ctx, namespace, set.Revision)).To(Succeed())
+
+				buildRuns := &buildv1beta1.BuildRunList{}
+				Expect(fakeClient.List(ctx, buildRuns, client.InNamespace(namespace))).To(Succeed())
+				Expect(buildRuns.Items).To(BeEmpty())
+				strategies := &buildv1beta1.BuildStrategyList{}
+				Expect(fakeClient.List(ctx, strategies, client.InNamespace(namespace))).To(Succeed())
+				Expect(strategies.Items).To(BeEmpty())
+			})
+		})
+	})
+})
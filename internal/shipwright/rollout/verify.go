@@ -0,0 +1,217 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SelfTestName is the name of the build strategy of the self-test BuildRuns, which are named
+// after it and the revision of the release they verify
+const SelfTestName = "openshift-builds-self-test"
+
+// SelfTestImage is the image of the self-test build strategy step, which only runs true
+const SelfTestImage = "registry.access.redhat.com/ubi10@sha256:ccb838d655199bff6a77fc4296a2b2a44e00163dabd0ba8b0d9030f281ec935f"
+
+// Result is the outcome of the verification of a release
+type Result string
+
+// Outcomes of the verification of a release
+const (
+	Pending  Result = "Pending"
+	Verified Result = "Verified"
+	Failed   Result = "Failed"
+)
+
+// Verifier checks that an applied release is healthy: its Deployments are rolled out and
+// available, its Services have ready endpoints, and a self-test BuildRun succeeds
+type Verifier struct {
+	// Client creates and deletes the self-test objects.
+	Client client.Client
+
+	// APIReader reads the release objects from the API server, without caching the
+	// Deployments, EndpointSlices and BuildRuns of the cluster.
+	APIReader client.Reader
+
+	// Images overrides the image of the self-test build strategy step.
+	Images *images.Overrides
+}
+
+// Verify returns whether the release applied in the namespace is verified, still pending or
+// failed, with a message describing what it is waiting for or why it failed. The self-test
+// objects are owned by the owner.
+func (v *Verifier) Verify(ctx context.Context, set Set, namespace string, owner client.Object) (Result, string, error) {
+	for _, resource := range set.Resources {
+		var (
+			result  Result
+			message string
+			err     error
+		)
+		switch resource.GetKind() {
+		case "Deployment":
+			result, message, err = v.deployment(ctx, namespace, resource.GetName())
+		case "Service":
+			result, message, err = v.service(ctx, namespace, resource.GetName())
+		default:
+			continue
+		}
+		if err != nil || result != Verified {
+			return result, message, err
+		}
+	}
+	return v.selfTest(ctx, set, namespace, owner)
+}
+
+// Cleanup deletes the self-test objects of the release revision
+func (v *Verifier) Cleanup(ctx context.Context, namespace string, revision string) error {
+	objects := []client.Object{
+		&buildv1beta1.BuildRun{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: selfTestRunName(revision)}},
+		&buildv1beta1.BuildStrategy{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: SelfTestName}},
+	}
+	for _, object := range objects {
+		err := v.Client.Delete(ctx, object, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete the self-test object %s: %v", object.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// deployment verifies that the Deployment rolled out its latest generation, and that all its
+// replicas are updated and available
+func (v *Verifier) deployment(ctx context.Context, namespace, name string) (Result, string, error) {
+	deployment := &appsv1.Deployment{}
+	if err := v.APIReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return Pending, fmt.Sprintf("Waiting for Deployment %s to be created", name), nil
+		}
+		return Pending, "", err
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return Failed, fmt.Sprintf("Deployment %s failed to roll out: %s", name, condition.Message), nil
+		}
+	}
+	replicas := ptr.Deref(deployment.Spec.Replicas, 1)
+	if deployment.Status.ObservedGeneration < deployment.Generation ||
+		deployment.Status.UpdatedReplicas < replicas || deployment.Status.AvailableReplicas < replicas ||
+		deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
+		return Pending, fmt.Sprintf("Waiting for Deployment %s to roll out: %d of %d updated replicas available",
+			name, deployment.Status.AvailableReplicas, replicas), nil
+	}
+	return Verified, "", nil
+}
+
+// service verifies that the Service has a ready endpoint
+func (v *Verifier) service(ctx context.Context, namespace, name string) (Result, string, error) {
+	slices := &discoveryv1.EndpointSliceList{}
+	err := v.APIReader.List(ctx, slices, client.InNamespace(namespace), client.MatchingLabels{discoveryv1.LabelServiceName: name})
+	if err != nil {
+		return Pending, "", err
+	}
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if ptr.Deref(endpoint.Conditions.Ready, true) {
+				return Verified, "", nil
+			}
+		}
+	}
+	return Pending, fmt.Sprintf("Waiting for Service %s to have a ready endpoint", name), nil
+}
+
+// selfTest runs a BuildRun of the self-test build strategy, and verifies that it succeeds
+func (v *Verifier) selfTest(ctx context.Context, set Set, namespace string, owner client.Object) (Result, string, error) {
+	strategy, err := v.selfTestStrategy(namespace)
+	if err != nil {
+		return Pending, "", err
+	}
+	buildRun := &buildv1beta1.BuildRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: selfTestRunName(set.Revision)},
+		Spec: buildv1beta1.BuildRunSpec{
+			Build: buildv1beta1.ReferencedBuild{
+				Spec: &buildv1beta1.BuildSpec{
+					Strategy: buildv1beta1.Strategy{Name: SelfTestName, Kind: ptr.To(buildv1beta1.NamespacedBuildStrategyKind)},
+					// The self-test step does not push an image
+					Output: buildv1beta1.Image{Image: fmt.Sprintf("image-registry.openshift-image-registry.svc:5000/%s/%s", namespace, SelfTestName)},
+				},
+			},
+		},
+	}
+	for _, object := range []client.Object{strategy, buildRun} {
+		if err := ctrl.SetControllerReference(owner, object, v.Client.Scheme()); err != nil {
+			return Pending, "", err
+		}
+		if err := v.Client.Create(ctx, object); err != nil && !apierrors.IsAlreadyExists(err) {
+			return Pending, "", fmt.Errorf("failed to create the self-test %s: %v", object.GetName(), err)
+		}
+	}
+
+	if err := v.APIReader.Get(ctx, client.ObjectKeyFromObject(buildRun), buildRun); err != nil {
+		return Pending, "", client.IgnoreNotFound(err)
+	}
+	succeeded := buildRun.Status.GetCondition(buildv1beta1.Succeeded)
+	switch {
+	case succeeded == nil || succeeded.Status == corev1.ConditionUnknown:
+		return Pending, fmt.Sprintf("Waiting for the self-test BuildRun %s to complete", buildRun.Name), nil
+	case succeeded.Status == corev1.ConditionTrue:
+		return Verified, "", nil
+	default:
+		return Failed, fmt.Sprintf("Self-test BuildRun %s failed: %s", buildRun.Name, succeeded.Message), nil
+	}
+}
+
+// selfTestStrategy returns the build strategy of the self-test BuildRuns, running true in the
+// restricted security context with the overridden step image
+func (v *Verifier) selfTestStrategy(namespace string) (*buildv1beta1.BuildStrategy, error) {
+	strategy := &buildv1beta1.BuildStrategy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: buildv1beta1.SchemeGroupVersion.String(), Kind: "BuildStrategy"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: SelfTestName},
+		Spec: buildv1beta1.BuildStrategySpec{
+			Steps: []buildv1beta1.Step{{
+				Name:    "self-test",
+				Image:   SelfTestImage,
+				Command: []string{"true"},
+				SecurityContext: &corev1.SecurityContext{
+					AllowPrivilegeEscalation: ptr.To(false),
+					RunAsNonRoot:             ptr.To(true),
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+					SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				},
+			}},
+		},
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(strategy)
+	if err != nil {
+		return nil, err
+	}
+	u := &unstructured.Unstructured{Object: object}
+	if err := v.Images.Transformer()(u); err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, strategy); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+// selfTestRunName returns the name of the self-test BuildRun of the release revision
+func selfTestRunName(revision string) string {
+	if len(revision) > 8 {
+		revision = revision[:8]
+	}
+	return SelfTestName + "-" + revision
+}
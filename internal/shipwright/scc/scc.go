@@ -0,0 +1,218 @@
+package scc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ComponentName is the name of the SecurityContextConstraints component
+const ComponentName = "SecurityContextConstraints"
+
+// Label is set on the SecurityContextConstraints of the build strategies and on the ClusterRoles
+// allowing their use, to the strategy
+const Label = "operator.openshift.io/build-strategy-scc"
+
+// GVK is the kind of the OpenShift SecurityContextConstraints
+var GVK = schema.GroupVersionKind{Group: "security.openshift.io", Version: "v1", Kind: "SecurityContextConstraints"}
+
+// Strategies are the shipped build strategies with a SecurityContextConstraints. The
+// buildpacks-extender strategy runs under the SecurityContextConstraints of buildpacks.
+var Strategies = []string{
+	string(openshiftv1alpha1.BuildahStrategy),
+	string(openshiftv1alpha1.BuildpacksStrategy),
+	string(openshiftv1alpha1.SourceToImageStrategy),
+}
+
+// Name returns the name of the SecurityContextConstraints of the strategy, and of the ClusterRole
+// allowing its use
+func Name(strategy string) string {
+	return "openshift-builds-" + strategy
+}
+
+var _ component.Component = &SCC{}
+
+// SCC creates the SecurityContextConstraints granting the shipped build strategies the privileges
+// they need without the privileged SecurityContextConstraints, and the ClusterRoles allowing their
+// use. Namespaces opt in to run a strategy through their BuildNamespaceConfig.
+type SCC struct {
+	Client client.Client
+}
+
+// New creates new instance of SCC type
+func New(client client.Client) *SCC {
+	return &SCC{
+		Client: client,
+	}
+}
+
+// Name returns the component name
+func (s *SCC) Name() string {
+	return ComponentName
+}
+
+// Setup sets the client from the manager if unset
+func (s *SCC) Setup(mgr ctrl.Manager) error {
+	if s.Client == nil {
+		s.Client = mgr.GetClient()
+	}
+	return nil
+}
+
+// Reconcile creates the SecurityContextConstraints and ClusterRoles of the strategies when
+// Shipwright Build is enabled, and deletes them otherwise
+func (s *SCC) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if !owner.DeletionTimestamp.IsZero() || !enabled(owner) {
+		return s.Delete(ctx, owner)
+	}
+	for _, strategy := range Strategies {
+		if err := s.reconcileSecurityContextConstraints(ctx, owner, strategy); err != nil {
+			return err
+		}
+		if err := s.reconcileClusterRole(ctx, owner, strategy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete removes the SecurityContextConstraints and ClusterRoles of the strategies
+func (s *SCC) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	for _, strategy := range Strategies {
+		object := &unstructured.Unstructured{}
+		object.SetGroupVersionKind(GVK)
+		object.SetName(Name(strategy))
+		for _, object := range []client.Object{object, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: Name(strategy)}}} {
+			if err := s.Client.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+				return fmt.Errorf("failed to delete %T %s: %v", object, object.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Status reports whether the SecurityContextConstraints of the strategies are created
+func (s *SCC) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	condition := metav1.Condition{
+		Type: component.ConditionType(s),
+	}
+	if !enabled(owner) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disabled"
+		condition.Message = "Shipwright Build is disabled"
+		return condition
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Created"
+	condition.Message = fmt.Sprintf("SecurityContextConstraints are created for the strategies %s", strings.Join(Strategies, ", "))
+	return condition
+}
+
+// WatchedTypes returns nil, as the SecurityContextConstraints kind may not be served when the
+// operator starts
+func (s *SCC) WatchedTypes() []client.Object {
+	return nil
+}
+
+// Profile returns the SecurityContextConstraints fields of the strategy. All strategies run their
+// steps as root, or as the user of the builder image, in the SELinux context and with the default
+// seccomp profile of the namespace, on the volumes of the restricted SecurityContextConstraints.
+// Buildah and source-to-image, which builds with buildah, add the SETFCAP capability to run
+// rootless buildah, whose setuid helpers require privilege escalation. Buildpacks changes the
+// ownership of the layers with the default capabilities.
+func Profile(strategy string) map[string]interface{} {
+	profile := map[string]interface{}{
+		"allowHostDirVolumePlugin": false,
+		"allowHostIPC":             false,
+		"allowHostNetwork":         false,
+		"allowHostPID":             false,
+		"allowHostPorts":           false,
+		"allowPrivilegedContainer": false,
+		"allowPrivilegeEscalation": false,
+		"allowedCapabilities":      []interface{}{},
+		"readOnlyRootFilesystem":   false,
+		"requiredDropCapabilities": []interface{}{"KILL", "MKNOD"},
+		"runAsUser":                map[string]interface{}{"type": "RunAsAny"},
+		"seLinuxContext":           map[string]interface{}{"type": "MustRunAs"},
+		"fsGroup":                  map[string]interface{}{"type": "RunAsAny"},
+		"supplementalGroups":       map[string]interface{}{"type": "RunAsAny"},
+		"seccompProfiles":          []interface{}{"runtime/default"},
+		"users":                    []interface{}{},
+		"groups":                   []interface{}{},
+		"volumes": []interface{}{
+			"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret",
+		},
+	}
+	switch openshiftv1alpha1.BuildStrategyName(strategy) {
+	case openshiftv1alpha1.BuildahStrategy, openshiftv1alpha1.SourceToImageStrategy:
+		profile["allowPrivilegeEscalation"] = true
+		profile["allowedCapabilities"] = []interface{}{"SETFCAP"}
+		profile["requiredDropCapabilities"] = []interface{}{"MKNOD"}
+	}
+	return profile
+}
+
+// reconcileSecurityContextConstraints creates the SecurityContextConstraints of the strategy
+func (s *SCC) reconcileSecurityContextConstraints(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, strategy string) error {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(GVK)
+	object.SetName(Name(strategy))
+	_, err := ctrl.CreateOrUpdate(ctx, s.Client, object, func() error {
+		setLabel(object, strategy)
+		for field, value := range Profile(strategy) {
+			object.Object[field] = value
+		}
+		return ctrl.SetControllerReference(owner, object, s.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile SecurityContextConstraints %s: %v", Name(strategy), err)
+	}
+	return nil
+}
+
+// reconcileClusterRole creates the ClusterRole allowing the use of the SecurityContextConstraints
+// of the strategy
+func (s *SCC) reconcileClusterRole(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, strategy string) error {
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: Name(strategy)}}
+	_, err := ctrl.CreateOrUpdate(ctx, s.Client, role, func() error {
+		setLabel(role, strategy)
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{GVK.Group},
+			Resources:     []string{"securitycontextconstraints"},
+			ResourceNames: []string{Name(strategy)},
+			Verbs:         []string{"use"},
+		}}
+		return ctrl.SetControllerReference(owner, role, s.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ClusterRole %s: %v", Name(strategy), err)
+	}
+	return nil
+}
+
+// enabled returns whether Shipwright Build, installing the strategies, is enabled
+func enabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	shipwright := owner.Spec.Shipwright
+	return shipwright != nil && shipwright.Build != nil && shipwright.Build.State == openshiftv1alpha1.Enabled
+}
+
+// setLabel marks the object as created for the strategy
+func setLabel(object client.Object, strategy string) {
+	objectLabels := object.GetLabels()
+	if objectLabels == nil {
+		objectLabels = map[string]string{}
+	}
+	objectLabels[Label] = strategy
+	object.SetLabels(objectLabels)
+}
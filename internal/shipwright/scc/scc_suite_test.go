@@ -0,0 +1,13 @@
+package scc_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSCC(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "SCC Suite")
+}
@@ -0,0 +1,104 @@
+package scc_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/scc"
+	"github.com/redhat-openshift-builds/operator/internal/testutil"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("SCC", Label("scc"), func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		owner     *openshiftv1alpha1.OpenShiftBuild
+		k8sClient client.Client
+		component *scc.SCC
+	)
+
+	newSCC := func() *unstructured.Unstructured {
+		constraints := &unstructured.Unstructured{}
+		constraints.SetGroupVersionKind(scc.GVK)
+		return constraints
+	}
+	key := func(strategy string) types.NamespacedName {
+		return types.NamespacedName{Name: scc.Name(strategy)}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = testutil.NewScheme()
+		owner = testutil.NewOwner()
+		owner.Default()
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		component = scc.New(k8sClient)
+	})
+
+	Describe("Reconcile", func() {
+		It("creates the SCCs of the strategies and the ClusterRoles allowing their use", func() {
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+
+			for _, strategy := range []string{"buildah", "buildpacks", "source-to-image"} {
+				constraints := newSCC()
+				Expect(k8sClient.Get(ctx, key(strategy), constraints)).To(Succeed())
+				Expect(metav1.IsControlledBy(constraints, owner)).To(BeTrue())
+				Expect(constraints.GetLabels()).To(HaveKeyWithValue(scc.Label, strategy))
+				Expect(constraints.Object).To(HaveKeyWithValue("allowPrivilegedContainer", false))
+
+				role := &rbacv1.ClusterRole{}
+				Expect(k8sClient.Get(ctx, key(strategy), role)).To(Succeed())
+				Expect(role.Rules).To(Equal([]rbacv1.PolicyRule{{
+					APIGroups:     []string{"security.openshift.io"},
+					Resources:     []string{"securitycontextconstraints"},
+					ResourceNames: []string{scc.Name(strategy)},
+					Verbs:         []string{"use"},
+				}}))
+			}
+		})
+
+		It("only allows buildah and source-to-image to add SETFCAP", func() {
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+
+			constraints := newSCC()
+			Expect(k8sClient.Get(ctx, key("buildah"), constraints)).To(Succeed())
+			capabilities, _, _ := unstructured.NestedStringSlice(constraints.Object, "allowedCapabilities")
+			Expect(capabilities).To(Equal([]string{"SETFCAP"}))
+
+			Expect(k8sClient.Get(ctx, key("buildpacks"), constraints)).To(Succeed())
+			capabilities, _, _ = unstructured.NestedStringSlice(constraints.Object, "allowedCapabilities")
+			Expect(capabilities).To(BeEmpty())
+			Expect(constraints.Object).To(HaveKeyWithValue("allowPrivilegeEscalation", false))
+		})
+
+		It("deletes them when Shipwright Build is disabled", func() {
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+
+			disabled := owner.DeepCopy()
+			disabled.Spec.Shipwright.Build.State = openshiftv1alpha1.Disabled
+			Expect(component.Reconcile(ctx, disabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, key("buildah"), newSCC())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, key("buildah"), &rbacv1.ClusterRole{})).To(Satisfy(apierrors.IsNotFound))
+		})
+	})
+
+	Describe("Status", func() {
+		It("reports the SCCs", func() {
+			condition := component.Status(ctx, owner)
+			Expect(condition.Type).To(Equal("SecurityContextConstraintsReady"))
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("buildah, buildpacks, source-to-image"))
+		})
+	})
+})
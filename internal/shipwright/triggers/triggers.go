@@ -0,0 +1,256 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ComponentName is the name of the Shipwright Triggers component
+const ComponentName = "ShipwrightTriggers"
+
+// Name is the name of the Deployment, Service and Route of the trigger controller
+const Name = "shipwright-triggers"
+
+// RouteGVK is the kind of the OpenShift Routes
+var RouteGVK = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+
+var (
+	_ component.Component   = &Triggers{}
+	_ component.Relocatable = &Triggers{}
+)
+
+// Triggers deploys the Shipwright Triggers controller, which starts BuildRuns on the GitHub,
+// GitLab and generic webhooks received by its Route
+type Triggers struct {
+	Client       client.Client
+	Logger       logr.Logger
+	Manifest     manifestival.Manifest
+	ManifestPath string
+
+	// NetworkPolicyManifestPath is the path of the NetworkPolicies admitting the webhooks to
+	// the trigger controller. No NetworkPolicy is deployed when empty.
+	NetworkPolicyManifestPath string
+
+	// Images overrides the image of the trigger controller.
+	Images *images.Overrides
+}
+
+// Name returns the component name
+func (t *Triggers) Name() string {
+	return ComponentName
+}
+
+// Setup loads the manifests of the trigger controller and of its NetworkPolicies
+func (t *Triggers) Setup(mgr ctrl.Manager) error {
+	if t.Client == nil {
+		t.Client = mgr.GetClient()
+	}
+
+	manifestivalOptions := []manifestival.Option{
+		manifestival.UseLogger(t.Logger),
+		manifestival.UseClient(manifestivalclient.NewClient(t.Client)),
+	}
+	manifestPath := common.ShipwrightTriggersManifestPath
+	if t.ManifestPath != "" {
+		manifestPath = t.ManifestPath
+	}
+	manifest, err := manifestival.NewManifest(manifestPath, manifestivalOptions...)
+	if err != nil {
+		return err
+	}
+	if t.NetworkPolicyManifestPath != "" {
+		networkPolicies, err := manifestival.NewManifest(t.NetworkPolicyManifestPath, manifestivalOptions...)
+		if err != nil {
+			return err
+		}
+		manifest = manifest.Append(networkPolicies)
+	}
+	t.Manifest = manifest
+	return nil
+}
+
+// Reconcile applies the manifests when the triggers are enabled, and deletes them otherwise
+func (t *Triggers) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	logger := t.Logger.WithValues("name", owner.Name)
+
+	if !owner.DeletionTimestamp.IsZero() || !enabled(owner) {
+		manifest, err := t.transform(owner, false)
+		if err != nil {
+			return err
+		}
+		return t.deleteManifests(&manifest, !enabled(owner))
+	}
+
+	manifest, err := t.transform(owner, true)
+	if err != nil {
+		logger.Error(err, "transforming manifest")
+		return err
+	}
+	logger.Info("Applying Shipwright Triggers manifests")
+	return manifest.Apply()
+}
+
+// Delete removes the finalizers from the trigger controller resources, leaving their deletion to
+// the garbage collector, or deletes them explicitly if the triggers are disabled.
+func (t *Triggers) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	manifest, err := t.transform(owner, false)
+	if err != nil {
+		t.Logger.Error(err, "transforming manifest", "name", owner.Name)
+		return err
+	}
+	return t.deleteManifests(&manifest, !enabled(owner))
+}
+
+// Relocate deletes the trigger controller resources deployed in the previous operand namespace
+func (t *Triggers) Relocate(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, previous string) error {
+	previousOwner := owner.DeepCopy()
+	previousOwner.Spec.Namespace = previous
+	manifest, err := t.transform(previousOwner, false)
+	if err != nil {
+		return err
+	}
+	t.Logger.Info("Deleting Shipwright Triggers resources of the previous operand namespace", "namespace", previous)
+	return common.DeleteOperands(manifest, previous, owner)
+}
+
+// Status reports whether the trigger controller is available, and the host of its Route
+func (t *Triggers) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	condition := metav1.Condition{
+		Type: component.ConditionType(t),
+	}
+	if !enabled(owner) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disabled"
+		condition.Message = "Shipwright Triggers are disabled"
+		return condition
+	}
+
+	namespace := common.OperandNamespace(owner)
+	deployment := &appsv1.Deployment{}
+	if err := t.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: Name}, deployment); err != nil {
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "NotFound"
+		condition.Message = fmt.Sprintf("Failed to get the Shipwright Triggers Deployment: %v", err)
+		return condition
+	}
+	available := findDeploymentCondition(deployment, appsv1.DeploymentAvailable)
+	if available == nil || available.Status != corev1.ConditionTrue {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Deploying"
+		condition.Message = "Waiting for the Shipwright Triggers controller to be available"
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Available"
+	condition.Message = "Shipwright Triggers controller is available"
+	if host := t.routeHost(ctx, namespace); host != "" {
+		condition.Message += ", receiving webhooks at https://" + host
+	}
+	return condition
+}
+
+// WatchedTypes returns nil as changes to the trigger controller resources do not trigger a
+// reconciliation
+func (t *Triggers) WatchedTypes() []client.Object {
+	return nil
+}
+
+// routeHost returns the host admitted for the Route of the trigger controller, or an empty
+// string if it is not admitted yet
+func (t *Triggers) routeHost(ctx context.Context, namespace string) string {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(RouteGVK)
+	if err := t.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: Name}, route); err != nil {
+		return ""
+	}
+	ingresses, _, _ := unstructured.NestedSlice(route.Object, "status", "ingress")
+	for _, item := range ingresses {
+		if ingress, ok := item.(map[string]interface{}); ok {
+			if host, _, _ := unstructured.NestedString(ingress, "host"); host != "" {
+				return host
+			}
+		}
+	}
+	return ""
+}
+
+// transform applies the owner, namespace and finalizer transformers to the manifests, and the
+// image overrides to the manifests being applied
+func (t *Triggers) transform(owner *openshiftv1alpha1.OpenShiftBuild, apply bool) (manifestival.Manifest, error) {
+	transformerfuncs := []manifestival.Transformer{
+		manifestival.InjectOwner(owner),
+		manifestival.InjectNamespace(common.OperandNamespace(owner)),
+	}
+	if apply {
+		// Images are checked only when deployed, so that unpinned images do not prevent
+		// disabling the triggers
+		transformerfuncs = append(transformerfuncs, t.Images.Transformer())
+		transformerfuncs = append(transformerfuncs, common.InjectFinalizer(common.OpenShiftBuildFinalizerName))
+	}
+	return t.Manifest.Transform(transformerfuncs...)
+}
+
+// deleteManifests removes the finalizers of the resources, and deletes them if requested.
+// Resources of kinds which are not served, such as Routes outside of OpenShift, are skipped.
+func (t *Triggers) deleteManifests(manifest *manifestival.Manifest, deleteResources bool) error {
+	mfc := t.Manifest.Client
+	for _, res := range manifest.Resources() {
+		obj, err := mfc.Get(&res)
+		if err != nil {
+			if apierrors.IsNotFound(err) || apimeta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+
+		if len(obj.GetFinalizers()) > 0 {
+			obj.SetFinalizers([]string{})
+			if err := mfc.Update(obj); err != nil {
+				return err
+			}
+		}
+
+		if deleteResources {
+			t.Logger.Info("Deleting Shipwright Triggers resource", "kind", res.GetKind(), "name", res.GetName())
+			if err := mfc.Delete(&res); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// enabled returns whether the owner enables the triggers, which are disabled when omitted
+func enabled(owner *openshiftv1alpha1.OpenShiftBuild) bool {
+	return owner.Spec.Shipwright != nil && owner.Spec.Shipwright.Triggers != nil &&
+		owner.Spec.Shipwright.Triggers.State == openshiftv1alpha1.Enabled
+}
+
+// findDeploymentCondition returns the condition of the given type of the Deployment, if any
+func findDeploymentCondition(deployment *appsv1.Deployment, conditionType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == conditionType {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package triggers_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTriggers(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Triggers Suite")
+}
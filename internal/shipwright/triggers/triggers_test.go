@@ -0,0 +1,162 @@
+package triggers_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/triggers"
+	"github.com/redhat-openshift-builds/operator/internal/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Triggers", Label("triggers"), func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		owner     *openshiftv1alpha1.OpenShiftBuild
+		k8sClient client.Client
+		component *triggers.Triggers
+		enabled   *openshiftv1alpha1.OpenShiftBuild
+		key       types.NamespacedName
+	)
+
+	newRoute := func() *unstructured.Unstructured {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(triggers.RouteGVK)
+		return route
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = testutil.NewScheme()
+		owner = testutil.NewOwner()
+		owner.Default()
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).Build()
+		component = &triggers.Triggers{
+			Client:                    k8sClient,
+			Logger:                    log.Log.WithName("test"),
+			ManifestPath:              filepath.Join("..", "..", "..", "config", "shipwright", "triggers"),
+			NetworkPolicyManifestPath: filepath.Join("..", "..", "..", "config", "networkpolicies", "triggers"),
+		}
+		Expect(component.Setup(nil)).To(Succeed())
+
+		enabled = owner.DeepCopy()
+		enabled.Spec.Shipwright.Triggers.State = openshiftv1alpha1.Enabled
+		key = types.NamespacedName{Namespace: common.OpenShiftBuildNamespaceName, Name: triggers.Name}
+	})
+
+	Describe("Reconcile", func() {
+		It("deploys nothing when the triggers are disabled", func() {
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+			Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("deploys the trigger controller, its Route and NetworkPolicies when enabled", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(metav1.IsControlledBy(deployment, enabled)).To(BeTrue())
+			Expect(deployment.Finalizers).To(ContainElement(common.OpenShiftBuildFinalizerName))
+			Expect(k8sClient.Get(ctx, key, &corev1.Service{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, key, &corev1.ServiceAccount{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, key, newRoute())).To(Succeed())
+
+			binding := &rbacv1.ClusterRoleBinding{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: triggers.Name}, binding)).To(Succeed())
+			Expect(binding.Subjects[0].Namespace).To(Equal(common.OpenShiftBuildNamespaceName))
+
+			policy := &networkingv1.NetworkPolicy{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{
+				Namespace: common.OpenShiftBuildNamespaceName,
+				Name:      "shipwright-triggers-webhook-ingress",
+			}, policy)).To(Succeed())
+		})
+
+		It("deletes the trigger controller when disabled again", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+
+			Expect(k8sClient.Get(ctx, key, &appsv1.Deployment{})).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, key, newRoute())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: triggers.Name}, &rbacv1.ClusterRole{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("deploys into the operand namespace of the owner", func() {
+			enabled.Spec.Namespace = "builds"
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: "builds", Name: triggers.Name}, &appsv1.Deployment{})).To(Succeed())
+		})
+
+		It("rejects an unpinned image only when the triggers are enabled", func() {
+			component.Images = images.New(nil, true)
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+			Expect(component.Reconcile(ctx, enabled)).To(MatchError(ContainSubstring("not pinned by digest")))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("removes the finalizers and leaves the resources to the garbage collector", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(component.Delete(ctx, enabled)).To(Succeed())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Finalizers).To(BeEmpty())
+		})
+	})
+
+	Describe("Status", func() {
+		It("reports the disabled triggers", func() {
+			condition := component.Status(ctx, owner)
+			Expect(condition.Type).To(Equal("ShipwrightTriggersReady"))
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Disabled"))
+		})
+
+		It("waits for the Deployment to be available", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			condition := component.Status(ctx, enabled)
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Deploying"))
+		})
+
+		It("reports the host of the Route once available", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			deployment.Status.Conditions = []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+			}
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+			route := newRoute()
+			Expect(k8sClient.Get(ctx, key, route)).To(Succeed())
+			Expect(unstructured.SetNestedSlice(route.Object, []interface{}{
+				map[string]interface{}{"host": "triggers.apps.example.com"},
+			}, "status", "ingress")).To(Succeed())
+			Expect(k8sClient.Update(ctx, route)).To(Succeed())
+
+			condition := component.Status(ctx, enabled)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("Available"))
+			Expect(condition.Message).To(ContainSubstring("https://triggers.apps.example.com"))
+		})
+	})
+})
@@ -0,0 +1,457 @@
+package variants
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/go-logr/logr"
+	manifestivalclient "github.com/manifestival/controller-runtime-client"
+	"github.com/manifestival/manifestival"
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/component"
+	"github.com/redhat-openshift-builds/operator/internal/images"
+	"github.com/redhat-openshift-builds/operator/internal/registries"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/scc"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ComponentName is the name of the build strategy variants component
+const ComponentName = "BuildStrategyVariants"
+
+// Variants of the build strategies installed by the operator, used as the value of the Label and
+// as the suffix of the name of the variants
+const (
+	Sandboxed     = "sandboxed"
+	UserNamespace = "userns"
+)
+
+// Label is set on the generated build strategies to their variant
+const Label = "operator.openshift.io/strategy-variant"
+
+// RuntimeClassAnnotation is set on the sandboxed build strategies to the RuntimeClass of their
+// BuildRuns
+const RuntimeClassAnnotation = "operator.openshift.io/runtime-class-name"
+
+// RequiredSCCAnnotation requests the SecurityContextConstraints admitting a pod. It is set on the
+// user namespace build strategies, and propagated by Shipwright to the pods of their BuildRuns.
+const RequiredSCCAnnotation = "openshift.io/required-scc"
+
+// HostUsersLabel is set to "false" on the BuildRuns of the user namespace build strategies, and
+// propagated by Shipwright and Tekton to their pods, whose hostUsers field is then set to false by
+// the pod webhook.
+const HostUsersLabel = "operator.openshift.io/host-users"
+
+// Name of the SecurityContextConstraints of the user namespace build strategies, of the
+// ClusterRole allowing its use, and of the RoleBindings granting it to the selected namespaces
+const UserNamespaceName = "openshift-builds-userns"
+
+// ClusterBuildStrategyGVK is the kind of the Shipwright ClusterBuildStrategies
+var ClusterBuildStrategyGVK = schema.GroupVersionKind{Group: "shipwright.io", Version: "v1beta1", Kind: "ClusterBuildStrategy"}
+
+var _ component.Component = &Variants{}
+var _ component.Watcher = &Variants{}
+
+// Variants generates the hardened variants of the build strategies installed by the operator: the
+// sandboxed variants, whose BuildRuns run with a RuntimeClass such as Kata Containers, and the
+// user namespace variants, whose pods run with hostUsers set to false under a dedicated
+// SecurityContextConstraints granted to the service accounts of the selected namespaces.
+type Variants struct {
+	Client   client.Client
+	Logger   logr.Logger
+	Manifest manifestival.Manifest
+
+	// ManifestPath is the path of the build strategy manifests the variants are generated from.
+	ManifestPath string
+
+	// Images overrides the images of the build strategies.
+	Images *images.Overrides
+}
+
+// Name returns the component name
+func (v *Variants) Name() string {
+	return ComponentName
+}
+
+// Setup loads the build strategy manifests
+func (v *Variants) Setup(mgr ctrl.Manager) error {
+	if v.Client == nil {
+		v.Client = mgr.GetClient()
+	}
+	manifestPath := common.ShipwrightBuildStrategyManifestPath
+	if v.ManifestPath != "" {
+		manifestPath = v.ManifestPath
+	}
+	manifest, err := manifestival.NewManifest(manifestPath,
+		manifestival.UseLogger(v.Logger),
+		manifestival.UseClient(manifestivalclient.NewClient(v.Client)),
+	)
+	if err != nil {
+		return err
+	}
+	v.Manifest = manifest.Filter(manifestival.ByKind(ClusterBuildStrategyGVK.Kind))
+	return nil
+}
+
+// Reconcile generates the variants enabled by the owner, and removes the others
+func (v *Variants) Reconcile(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if !owner.DeletionTimestamp.IsZero() {
+		return v.Delete(ctx, owner)
+	}
+	if err := v.reconcileStrategies(ctx, owner); err != nil {
+		return err
+	}
+	if !Enabled(owner, UserNamespace) {
+		return v.deleteUserNamespaceRBAC(ctx)
+	}
+	selector, err := metav1.LabelSelectorAsSelector(owner.Spec.Shipwright.UserNamespaces.NamespaceSelector)
+	if err != nil {
+		return fmt.Errorf("invalid namespace selector: %v", err)
+	}
+	if err := v.reconcileSecurityContextConstraints(ctx, owner); err != nil {
+		return err
+	}
+	if err := v.reconcileClusterRole(ctx, owner); err != nil {
+		return err
+	}
+	return v.reconcileRoleBindings(ctx, owner, selector)
+}
+
+// Delete removes the generated build strategies, and the SecurityContextConstraints of the user
+// namespace variants with its RBAC
+func (v *Variants) Delete(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	if err := v.deleteStrategies(ctx, nil); err != nil {
+		return err
+	}
+	return v.deleteUserNamespaceRBAC(ctx)
+}
+
+// Status reports the variants generated
+func (v *Variants) Status(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) metav1.Condition {
+	condition := metav1.Condition{
+		Type: component.ConditionType(v),
+	}
+	variants := []string{}
+	for _, variant := range []string{Sandboxed, UserNamespace} {
+		if Enabled(owner, variant) {
+			variants = append(variants, variant)
+		}
+	}
+	if len(variants) == 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Disabled"
+		condition.Message = "Build strategy variants are disabled"
+		return condition
+	}
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "Generated"
+	condition.Message = fmt.Sprintf("Build strategy variants %v are generated for %d strategies", variants, len(v.Manifest.Resources()))
+	return condition
+}
+
+// WatchedTypes returns nil, as the ClusterBuildStrategies may not be served when the operator
+// starts
+func (v *Variants) WatchedTypes() []client.Object {
+	return nil
+}
+
+// Watches returns the namespaces, so that namespaces are granted the use of the user namespace
+// SecurityContextConstraints when their labels start matching the selector, and denied when they
+// stop matching
+func (v *Variants) Watches() []component.Watch {
+	return []component.Watch{
+		{
+			Object: &corev1.Namespace{},
+			Predicates: []predicate.Predicate{predicate.Funcs{
+				UpdateFunc: func(e event.UpdateEvent) bool {
+					return !labels.Equals(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+				},
+				DeleteFunc:  func(event.DeleteEvent) bool { return false },
+				GenericFunc: func(event.GenericEvent) bool { return false },
+			}},
+		},
+	}
+}
+
+// Generate returns the variant of the build strategy
+func Generate(strategy *unstructured.Unstructured, variant string, owner *openshiftv1alpha1.OpenShiftBuild) *unstructured.Unstructured {
+	generated := strategy.DeepCopy()
+	generated.SetName(strategy.GetName() + "-" + variant)
+	generated.SetResourceVersion("")
+	generated.SetUID("")
+
+	objectLabels := maps.Clone(generated.GetLabels())
+	if objectLabels == nil {
+		objectLabels = map[string]string{}
+	}
+	objectLabels[Label] = variant
+	generated.SetLabels(objectLabels)
+
+	annotations := maps.Clone(generated.GetAnnotations())
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	switch variant {
+	case Sandboxed:
+		annotations[RuntimeClassAnnotation] = owner.Spec.Shipwright.Sandboxed.RuntimeClassName
+	case UserNamespace:
+		annotations[RequiredSCCAnnotation] = UserNamespaceName
+	}
+	generated.SetAnnotations(annotations)
+	return generated
+}
+
+// Enabled returns whether the owner enables the variant. Variants require Shipwright Build.
+func Enabled(owner *openshiftv1alpha1.OpenShiftBuild, variant string) bool {
+	shipwright := owner.Spec.Shipwright
+	if shipwright == nil || shipwright.Build == nil || shipwright.Build.State != openshiftv1alpha1.Enabled {
+		return false
+	}
+	switch variant {
+	case Sandboxed:
+		return shipwright.Sandboxed != nil && shipwright.Sandboxed.State == openshiftv1alpha1.Enabled
+	case UserNamespace:
+		return shipwright.UserNamespaces != nil && shipwright.UserNamespaces.State == openshiftv1alpha1.Enabled
+	}
+	return false
+}
+
+// reconcileStrategies applies the variants of the build strategies enabled by the owner, and
+// deletes the others
+func (v *Variants) reconcileStrategies(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	resources := []unstructured.Unstructured{}
+	desired := map[string]bool{}
+	for _, variant := range []string{Sandboxed, UserNamespace} {
+		if !Enabled(owner, variant) {
+			continue
+		}
+		for _, strategy := range v.Manifest.Resources() {
+			generated := Generate(&strategy, variant, owner)
+			resources = append(resources, *generated)
+			desired[generated.GetName()] = true
+		}
+	}
+	if len(resources) > 0 {
+		manifest, err := manifestival.ManifestFrom(manifestival.Slice(resources), manifestival.UseClient(v.Manifest.Client), manifestival.UseLogger(v.Logger))
+		if err != nil {
+			return err
+		}
+		// The variants are transformed like the strategies they are generated from
+		manifest, err = manifest.Transform(
+			manifestival.InjectOwner(owner),
+			v.Images.Transformer(),
+			registries.InjectRegistriesConf(variantNames(registries.Strategies, desired)...),
+		)
+		if err != nil {
+			return err
+		}
+		v.Logger.Info("Applying build strategy variants", "count", len(resources))
+		if err := manifest.Apply(); err != nil {
+			return err
+		}
+	}
+	return v.deleteStrategies(ctx, desired)
+}
+
+// deleteStrategies deletes the generated build strategies which are not desired
+func (v *Variants) deleteStrategies(ctx context.Context, desired map[string]bool) error {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(ClusterBuildStrategyGVK.GroupVersion().WithKind(ClusterBuildStrategyGVK.Kind + "List"))
+	if err := v.Client.List(ctx, list, client.HasLabels{Label}); err != nil {
+		if apimeta.IsNoMatchError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list the build strategy variants: %v", err)
+	}
+	for i := range list.Items {
+		if desired[list.Items[i].GetName()] {
+			continue
+		}
+		if err := v.Client.Delete(ctx, &list.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete ClusterBuildStrategy %s: %v", list.Items[i].GetName(), err)
+		}
+		v.Logger.Info("Build strategy variant deleted", "name", list.Items[i].GetName())
+	}
+	return nil
+}
+
+// reconcileSecurityContextConstraints creates the SecurityContextConstraints of the user namespace
+// variants. Pods run as any user, including root, which is mapped to an unprivileged user of the
+// node by their user namespace, with the capabilities the build strategies add.
+func (v *Variants) reconcileSecurityContextConstraints(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	object := &unstructured.Unstructured{}
+	object.SetGroupVersionKind(scc.GVK)
+	object.SetName(UserNamespaceName)
+	_, err := ctrl.CreateOrUpdate(ctx, v.Client, object, func() error {
+		setLabel(object)
+		object.Object["allowHostDirVolumePlugin"] = false
+		object.Object["allowHostIPC"] = false
+		object.Object["allowHostNetwork"] = false
+		object.Object["allowHostPID"] = false
+		object.Object["allowHostPorts"] = false
+		object.Object["allowPrivilegeEscalation"] = true
+		object.Object["allowPrivilegedContainer"] = false
+		object.Object["allowedCapabilities"] = []interface{}{"SETFCAP"}
+		object.Object["readOnlyRootFilesystem"] = false
+		object.Object["requiredDropCapabilities"] = []interface{}{"KILL", "MKNOD"}
+		object.Object["runAsUser"] = map[string]interface{}{"type": "RunAsAny"}
+		object.Object["seLinuxContext"] = map[string]interface{}{"type": "MustRunAs"}
+		object.Object["fsGroup"] = map[string]interface{}{"type": "RunAsAny"}
+		object.Object["supplementalGroups"] = map[string]interface{}{"type": "RunAsAny"}
+		object.Object["seccompProfiles"] = []interface{}{"runtime/default"}
+		object.Object["userNamespaceLevel"] = "RequirePodLevel"
+		object.Object["users"] = []interface{}{}
+		object.Object["groups"] = []interface{}{}
+		object.Object["volumes"] = []interface{}{
+			"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret",
+		}
+		return ctrl.SetControllerReference(owner, object, v.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile SecurityContextConstraints %s: %v", UserNamespaceName, err)
+	}
+	return nil
+}
+
+// reconcileClusterRole creates the ClusterRole allowing the use of the user namespace
+// SecurityContextConstraints
+func (v *Variants) reconcileClusterRole(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild) error {
+	role := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: UserNamespaceName}}
+	_, err := ctrl.CreateOrUpdate(ctx, v.Client, role, func() error {
+		setLabel(role)
+		role.Rules = []rbacv1.PolicyRule{{
+			APIGroups:     []string{scc.GVK.Group},
+			Resources:     []string{"securitycontextconstraints"},
+			ResourceNames: []string{UserNamespaceName},
+			Verbs:         []string{"use"},
+		}}
+		return ctrl.SetControllerReference(owner, role, v.Client.Scheme())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ClusterRole %s: %v", UserNamespaceName, err)
+	}
+	return nil
+}
+
+// reconcileRoleBindings allows the service accounts of the selected namespaces to use the user
+// namespace SecurityContextConstraints, and removes the RoleBindings of the namespaces which are
+// no longer selected
+func (v *Variants) reconcileRoleBindings(ctx context.Context, owner *openshiftv1alpha1.OpenShiftBuild, selector labels.Selector) error {
+	namespaces := &corev1.NamespaceList{}
+	if err := v.Client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return fmt.Errorf("failed to list the namespaces selected for user namespaces: %v", err)
+	}
+	selected := map[string]bool{}
+	for _, namespace := range namespaces.Items {
+		if !namespace.DeletionTimestamp.IsZero() {
+			continue
+		}
+		selected[namespace.Name] = true
+		binding := &rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: UserNamespaceName}}
+		result, err := ctrl.CreateOrUpdate(ctx, v.Client, binding, func() error {
+			setLabel(binding)
+			binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: UserNamespaceName}
+			binding.Subjects = []rbacv1.Subject{{
+				APIGroup: rbacv1.GroupName,
+				Kind:     rbacv1.GroupKind,
+				Name:     "system:serviceaccounts:" + namespace.Name,
+			}}
+			return ctrl.SetControllerReference(owner, binding, v.Client.Scheme())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to reconcile RoleBinding %s/%s: %v", namespace.Name, UserNamespaceName, err)
+		}
+		if result != "unchanged" {
+			v.Logger.Info("User namespace builds allowed in namespace", "namespace", namespace.Name, "result", result)
+		}
+	}
+
+	bindings, err := v.roleBindings(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range bindings {
+		if selected[bindings[i].Namespace] {
+			continue
+		}
+		if err := v.Client.Delete(ctx, &bindings[i]); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete RoleBinding %s/%s: %v", bindings[i].Namespace, bindings[i].Name, err)
+		}
+		v.Logger.Info("User namespace builds no longer allowed in namespace", "namespace", bindings[i].Namespace)
+	}
+	return nil
+}
+
+// deleteUserNamespaceRBAC deletes the SecurityContextConstraints of the user namespace variants,
+// the ClusterRole allowing its use and the RoleBindings granting it
+func (v *Variants) deleteUserNamespaceRBAC(ctx context.Context) error {
+	bindings, err := v.roleBindings(ctx)
+	if err != nil {
+		return err
+	}
+	objects := []client.Object{}
+	for i := range bindings {
+		objects = append(objects, &bindings[i])
+	}
+	constraints := &unstructured.Unstructured{}
+	constraints.SetGroupVersionKind(scc.GVK)
+	constraints.SetName(UserNamespaceName)
+	objects = append(objects, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: UserNamespaceName}}, constraints)
+	for _, object := range objects {
+		if err := v.Client.Delete(ctx, object); err != nil && !apierrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+			return fmt.Errorf("failed to delete %T %s: %v", object, client.ObjectKeyFromObject(object), err)
+		}
+	}
+	return nil
+}
+
+// roleBindings returns the RoleBindings allowing namespaces to use the user namespace
+// SecurityContextConstraints
+func (v *Variants) roleBindings(ctx context.Context) ([]rbacv1.RoleBinding, error) {
+	list := &rbacv1.RoleBindingList{}
+	if err := v.Client.List(ctx, list, client.HasLabels{Label}); err != nil {
+		return nil, fmt.Errorf("failed to list the user namespace RoleBindings: %v", err)
+	}
+	bindings := []rbacv1.RoleBinding{}
+	for _, binding := range list.Items {
+		if binding.Name == UserNamespaceName {
+			bindings = append(bindings, binding)
+		}
+	}
+	return bindings, nil
+}
+
+// variantNames returns the names of the desired variants of the given build strategies
+func variantNames(strategies []string, desired map[string]bool) []string {
+	names := []string{}
+	for _, strategy := range strategies {
+		for _, variant := range []string{Sandboxed, UserNamespace} {
+			if name := strategy + "-" + variant; desired[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// setLabel marks the object as created for the user namespace variants
+func setLabel(object client.Object) {
+	objectLabels := object.GetLabels()
+	if objectLabels == nil {
+		objectLabels = map[string]string{}
+	}
+	objectLabels[Label] = UserNamespace
+	object.SetLabels(objectLabels)
+}
@@ -0,0 +1,170 @@
+package variants_test
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/scc"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/variants"
+	"github.com/redhat-openshift-builds/operator/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var _ = Describe("Variants", Label("variants"), func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		owner     *openshiftv1alpha1.OpenShiftBuild
+		k8sClient client.Client
+		component *variants.Variants
+		enabled   *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	newStrategy := func() *unstructured.Unstructured {
+		strategy := &unstructured.Unstructured{}
+		strategy.SetGroupVersionKind(variants.ClusterBuildStrategyGVK)
+		return strategy
+	}
+	newSCC := func() *unstructured.Unstructured {
+		constraints := &unstructured.Unstructured{}
+		constraints.SetGroupVersionKind(scc.GVK)
+		return constraints
+	}
+	bindingKey := func(namespace string) types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: variants.UserNamespaceName}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = testutil.NewScheme()
+		owner = testutil.NewOwner()
+		owner.Default()
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "builds", Labels: map[string]string{"userns": "true"}}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+		).Build()
+		component = &variants.Variants{
+			Client:       k8sClient,
+			Logger:       log.Log.WithName("test"),
+			ManifestPath: filepath.Join("..", "..", "..", "config", "shipwright", "build", "strategy"),
+		}
+		Expect(component.Setup(nil)).To(Succeed())
+
+		enabled = owner.DeepCopy()
+		enabled.Spec.Shipwright.Sandboxed.State = openshiftv1alpha1.Enabled
+		enabled.Spec.Shipwright.UserNamespaces.State = openshiftv1alpha1.Enabled
+		enabled.Spec.Shipwright.UserNamespaces.NamespaceSelector = &metav1.LabelSelector{
+			MatchLabels: map[string]string{"userns": "true"},
+		}
+	})
+
+	Describe("Reconcile", func() {
+		It("generates nothing when the variants are disabled", func() {
+			Expect(component.Reconcile(ctx, owner)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "buildah-sandboxed"}, newStrategy())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: variants.UserNamespaceName}, newSCC())).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("generates the sandboxed variants with the runtime class", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			strategy := newStrategy()
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "buildah-sandboxed"}, strategy)).To(Succeed())
+			Expect(metav1.IsControlledBy(strategy, enabled)).To(BeTrue())
+			Expect(strategy.GetLabels()).To(HaveKeyWithValue(variants.Label, variants.Sandboxed))
+			Expect(strategy.GetAnnotations()).To(HaveKeyWithValue(variants.RuntimeClassAnnotation, "kata"))
+			steps, _, _ := unstructured.NestedSlice(strategy.Object, "spec", "steps")
+			Expect(steps).NotTo(BeEmpty())
+		})
+
+		It("generates the user namespace variants and grants their SCC to the selected namespaces", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			strategy := newStrategy()
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "source-to-image-userns"}, strategy)).To(Succeed())
+			Expect(strategy.GetLabels()).To(HaveKeyWithValue(variants.Label, variants.UserNamespace))
+			Expect(strategy.GetAnnotations()).To(HaveKeyWithValue(variants.RequiredSCCAnnotation, variants.UserNamespaceName))
+
+			constraints := newSCC()
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: variants.UserNamespaceName}, constraints)).To(Succeed())
+			Expect(metav1.IsControlledBy(constraints, enabled)).To(BeTrue())
+			Expect(constraints.Object).To(HaveKeyWithValue("allowPrivilegedContainer", false))
+			Expect(constraints.Object).To(HaveKeyWithValue("userNamespaceLevel", "RequirePodLevel"))
+
+			role := &rbacv1.ClusterRole{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: variants.UserNamespaceName}, role)).To(Succeed())
+			Expect(role.Rules[0].Verbs).To(Equal([]string{"use"}))
+			Expect(role.Rules[0].ResourceNames).To(Equal([]string{variants.UserNamespaceName}))
+
+			binding := &rbacv1.RoleBinding{}
+			Expect(k8sClient.Get(ctx, bindingKey("builds"), binding)).To(Succeed())
+			Expect(binding.RoleRef.Name).To(Equal(variants.UserNamespaceName))
+			Expect(binding.Subjects[0].Name).To(Equal("system:serviceaccounts:builds"))
+			Expect(k8sClient.Get(ctx, bindingKey("other"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("deletes the variants which are disabled", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			enabled.Spec.Shipwright.UserNamespaces.State = openshiftv1alpha1.Disabled
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "buildah-sandboxed"}, newStrategy())).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "buildah-userns"}, newStrategy())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: variants.UserNamespaceName}, newSCC())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, bindingKey("builds"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("revokes the SCC from the namespaces which are no longer selected", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+
+			namespace := &corev1.Namespace{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "builds"}, namespace)).To(Succeed())
+			namespace.Labels = nil
+			Expect(k8sClient.Update(ctx, namespace)).To(Succeed())
+
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, bindingKey("builds"), &rbacv1.RoleBinding{})).To(Satisfy(apierrors.IsNotFound))
+		})
+
+		It("generates nothing when Shipwright Build is disabled", func() {
+			enabled.Spec.Shipwright.Build.State = openshiftv1alpha1.Disabled
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "buildah-sandboxed"}, newStrategy())).To(Satisfy(apierrors.IsNotFound))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("removes the variants and the SCC", func() {
+			Expect(component.Reconcile(ctx, enabled)).To(Succeed())
+			Expect(component.Delete(ctx, enabled)).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "buildpacks-sandboxed"}, newStrategy())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: variants.UserNamespaceName}, newSCC())).To(Satisfy(apierrors.IsNotFound))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: variants.UserNamespaceName}, &rbacv1.ClusterRole{})).To(Satisfy(apierrors.IsNotFound))
+		})
+	})
+
+	Describe("Status", func() {
+		It("reports the generated variants", func() {
+			condition := component.Status(ctx, enabled)
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("sandboxed"))
+		})
+
+		It("reports disabled variants", func() {
+			Expect(component.Status(ctx, owner).Status).To(Equal(metav1.ConditionFalse))
+		})
+	})
+})
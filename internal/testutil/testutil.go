@@ -0,0 +1,33 @@
+// Package testutil provides the fixtures shared by the unit tests of the operator components.
+package testutil
+
+import (
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// NewScheme returns a scheme with the Kubernetes and OpenShiftBuild API types
+func NewScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(openshiftv1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+// NewOwner returns the OpenShiftBuild owning the operands of the components
+func NewOwner() *openshiftv1alpha1.OpenShiftBuild {
+	return &openshiftv1alpha1.OpenShiftBuild{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: openshiftv1alpha1.GroupVersion.String(),
+			Kind:       "OpenShiftBuild",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.OpenShiftBuildResourceName,
+			UID:  "uid",
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/variants"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupPodWebhookWithManager registers the Pod webhooks with the manager
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(&PodUserNamespaceDefaulter{}).
+		WithDefaulterCustomPath("/mutate--v1-pod-user-namespace").
+		Complete()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&corev1.Pod{}).
+		WithDefaulter(&PodBuildQueueDefaulter{}).
+		WithDefaulterCustomPath("/mutate--v1-pod-build-queue").
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate--v1-pod-user-namespace,mutating=true,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-user-namespace-v1.operator.openshift.io,admissionReviewVersions=v1
+
+// PodUserNamespaceDefaulter runs the pods of the BuildRuns of the user namespace strategy variants
+// in a user namespace, setting hostUsers to false. Shipwright has no field for it, so the
+// BuildRuns are labeled instead, and the label is propagated to their pods. The webhook
+// configuration only selects the labeled pods, which are rejected when the webhook fails.
+type PodUserNamespaceDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &PodUserNamespaceDefaulter{}
+
+// Default implements webhook.CustomDefaulter
+func (d *PodUserNamespaceDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+	if pod.Labels[variants.HostUsersLabel] == "false" && pod.Spec.HostUsers == nil {
+		pod.Spec.HostUsers = ptr.To(false)
+		log.FromContext(ctx).Info("Running the pod in a user namespace", "pod", client.ObjectKeyFromObject(pod))
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate--v1-pod-build-queue,mutating=true,failurePolicy=fail,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-build-queue-v1.operator.openshift.io,admissionReviewVersions=v1
+
+// PodBuildQueueDefaulter holds the pods of the BuildRuns admitted into a build queue behind a
+// scheduling gate, removed by the build queue controller when the BuildRun starts. The queue label
+// of the BuildRun is propagated to its pods, and the webhook configuration only selects the labeled
+// pods, which are rejected when the webhook fails rather than skipping the queue.
+type PodBuildQueueDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &PodBuildQueueDefaulter{}
+
+// Default implements webhook.CustomDefaulter
+func (d *PodBuildQueueDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+	if _, ok := pod.Labels[buildqueue.Label]; !ok || buildqueue.Gated(pod) {
+		return nil
+	}
+	pod.Spec.SchedulingGates = append(pod.Spec.SchedulingGates, corev1.PodSchedulingGate{Name: buildqueue.SchedulingGate})
+	log.FromContext(ctx).Info("Holding the pod of the queued BuildRun", "pod", client.ObjectKeyFromObject(pod),
+		"buildrun", pod.Labels[buildv1beta1.LabelBuildRun])
+	return nil
+}
@@ -0,0 +1,78 @@
+package v1_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/variants"
+	webhookcorev1 "github.com/redhat-openshift-builds/operator/internal/webhook/core/v1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Pod webhook", Label("webhook"), func() {
+	var (
+		ctx       context.Context
+		defaulter *webhookcorev1.PodBuildQueueDefaulter
+		pod       *corev1.Pod
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		defaulter = &webhookcorev1.PodBuildQueueDefaulter{}
+		pod = &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "test",
+				Name:      "app-1-pod",
+				Labels: map[string]string{
+					variants.HostUsersLabel:    "false",
+					buildv1beta1.LabelBuildRun: "app-1",
+				},
+			},
+		}
+	})
+
+	Describe("user namespaces", func() {
+		userNamespaceDefaulter := &webhookcorev1.PodUserNamespaceDefaulter{}
+
+		It("runs the labeled pods without host users", func() {
+			Expect(userNamespaceDefaulter.Default(ctx, pod)).To(Succeed())
+			Expect(pod.Spec.HostUsers).To(Equal(ptr.To(false)))
+		})
+
+		It("keeps the host users of the pod", func() {
+			pod.Spec.HostUsers = ptr.To(true)
+			Expect(userNamespaceDefaulter.Default(ctx, pod)).To(Succeed())
+			Expect(pod.Spec.HostUsers).To(Equal(ptr.To(true)))
+		})
+
+		It("leaves the other pods unchanged", func() {
+			pod.Labels = nil
+			Expect(userNamespaceDefaulter.Default(ctx, pod)).To(Succeed())
+			Expect(pod.Spec.HostUsers).To(BeNil())
+		})
+	})
+
+	It("holds the pods of the queued BuildRuns", func() {
+		pod.Labels[buildqueue.Label] = ""
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(pod.Spec.SchedulingGates).To(Equal([]corev1.PodSchedulingGate{{Name: buildqueue.SchedulingGate}}))
+
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(pod.Spec.SchedulingGates).To(HaveLen(1))
+	})
+
+	It("does not hold the pods of the other BuildRuns", func() {
+		Expect(defaulter.Default(ctx, pod)).To(Succeed())
+		Expect(buildqueue.Gated(pod)).To(BeFalse())
+	})
+
+	It("rejects other objects", func() {
+		Expect(defaulter.Default(ctx, &corev1.Service{})).To(MatchError(ContainSubstring("expected a Pod")))
+	})
+})
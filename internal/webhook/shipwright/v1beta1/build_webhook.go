@@ -0,0 +1,206 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/redhat-openshift-builds/operator/internal/buildpolicy"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:rbac:groups=operator.openshift.io,resources=buildpolicies,verbs=get;list;watch
+
+// SetupBuildWebhookWithManager registers the Build webhook with the manager
+func SetupBuildWebhookWithManager(mgr ctrl.Manager, buildPolicies bool) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&buildv1beta1.Build{}).
+		WithValidator(&BuildCustomValidator{
+			Client:        mgr.GetClient(),
+			Recorder:      mgr.GetEventRecorderFor("openshift-builds-operator"),
+			BuildPolicies: buildPolicies,
+		}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-shipwright-io-v1beta1-build,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=shipwright.io,resources=builds,verbs=create;update,versions=v1beta1,name=vbuild-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildCustomValidator rejects the Builds violating the BuildPolicy of their namespace on create,
+// and on the updates changing their specification or labels
+type BuildCustomValidator struct {
+	Client client.Client
+
+	// Recorder records the violations as events of the Builds.
+	Recorder record.EventRecorder
+
+	// BuildPolicies enforces the BuildPolicies.
+	BuildPolicies bool
+}
+
+var _ webhook.CustomValidator = &BuildCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *BuildCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	build, ok := obj.(*buildv1beta1.Build)
+	if !ok {
+		return nil, fmt.Errorf("expected a Build object but got %T", obj)
+	}
+	if !v.BuildPolicies {
+		return nil, nil
+	}
+	return nil, enforcePolicy(ctx, v.Client, v.Recorder, build, buildSubject(build))
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *BuildCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldBuild, ok := oldObj.(*buildv1beta1.Build)
+	if !ok {
+		return nil, fmt.Errorf("expected a Build object but got %T", oldObj)
+	}
+	build, ok := newObj.(*buildv1beta1.Build)
+	if !ok {
+		return nil, fmt.Errorf("expected a Build object but got %T", newObj)
+	}
+	// Updates of the metadata by the controllers are allowed for the Builds created before
+	// their BuildPolicy
+	if !v.BuildPolicies || (apiequality.Semantic.DeepEqual(oldBuild.Spec, build.Spec) && apiequality.Semantic.DeepEqual(oldBuild.Labels, build.Labels)) {
+		return nil, nil
+	}
+	return nil, enforcePolicy(ctx, v.Client, v.Recorder, build, buildSubject(build))
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *BuildCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+//+kubebuilder:webhook:path=/validate-shipwright-io-v1beta1-buildrun,mutating=false,failurePolicy=fail,sideEffects=NoneOnDryRun,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=vbuildrun-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunCustomValidator rejects the BuildRuns violating the BuildPolicy of their namespace on
+// create. The BuildRuns referencing a Build are checked against its specification, so that the
+// Builds created before their BuildPolicy cannot run.
+type BuildRunCustomValidator struct {
+	Client client.Client
+
+	// APIReader reads the referenced Builds without caching them.
+	APIReader client.Reader
+
+	// Recorder records the violations as events of the BuildRuns.
+	Recorder record.EventRecorder
+
+	// BuildPolicies enforces the BuildPolicies.
+	BuildPolicies bool
+}
+
+var _ webhook.CustomValidator = &BuildRunCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *BuildRunCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	buildRun, ok := obj.(*buildv1beta1.BuildRun)
+	if !ok {
+		return nil, fmt.Errorf("expected a BuildRun object but got %T", obj)
+	}
+	if !v.BuildPolicies {
+		return nil, nil
+	}
+
+	var subject buildpolicy.Subject
+	if buildRun.Spec.Build.Spec != nil {
+		subject = buildpolicy.Subject{
+			Strategy: buildRun.Spec.Build.Spec.Strategy,
+			Output:   buildRun.Spec.Build.Spec.Output.Image,
+			Source:   buildRun.Spec.Build.Spec.Source,
+			Timeout:  buildRun.Spec.Build.Spec.Timeout,
+			Labels:   buildRun.Labels,
+		}
+	} else if buildRun.Spec.Build.Name != nil {
+		build := &buildv1beta1.Build{}
+		err := v.APIReader.Get(ctx, types.NamespacedName{Namespace: buildRun.Namespace, Name: *buildRun.Spec.Build.Name}, build)
+		if apierrors.IsNotFound(err) {
+			// Shipwright fails the BuildRuns of missing Builds
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		subject = buildSubject(build)
+	}
+	subject.Kind = "BuildRun"
+	if buildRun.Spec.Output != nil {
+		subject.Output = buildRun.Spec.Output.Image
+	}
+	if buildRun.Spec.Timeout != nil {
+		subject.Timeout = buildRun.Spec.Timeout
+	}
+	return nil, enforcePolicy(ctx, v.Client, v.Recorder, buildRun, subject)
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *BuildRunCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *BuildRunCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// buildSubject returns the specification of the Build checked against the BuildPolicy
+func buildSubject(build *buildv1beta1.Build) buildpolicy.Subject {
+	return buildpolicy.Subject{
+		Kind:     "Build",
+		Strategy: build.Spec.Strategy,
+		Output:   build.Spec.Output.Image,
+		Source:   build.Spec.Source,
+		Timeout:  build.Spec.Timeout,
+		Labels:   build.Labels,
+	}
+}
+
+// enforcePolicy rejects the object when it violates the BuildPolicy of its namespace. The
+// violations are recorded as an event of the object and counted, except for dry run requests.
+func enforcePolicy(ctx context.Context, reader client.Reader, recorder record.EventRecorder, object client.Object, subject buildpolicy.Subject) error {
+	namespace := &corev1.Namespace{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: object.GetNamespace()}, namespace); client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	rules, policies, err := buildpolicy.RulesFor(ctx, reader, namespace)
+	if err != nil {
+		return err
+	}
+	violations := buildpolicy.Check(rules, subject)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	messages := []string{}
+	for _, violation := range violations {
+		messages = append(messages, violation.Message)
+	}
+	message := fmt.Sprintf("violates the BuildPolicy %s: %s", strings.Join(policies, ", "), strings.Join(messages, "; "))
+	if request, err := admission.RequestFromContext(ctx); err != nil || !ptr.Deref(request.DryRun, false) {
+		for _, violation := range violations {
+			buildpolicy.Violations.WithLabelValues(object.GetNamespace(), subject.Kind, violation.Rule).Inc()
+		}
+		if recorder != nil {
+			recorder.Event(object, corev1.EventTypeWarning, buildpolicy.ReasonViolation, subject.Kind+" "+message)
+		}
+	}
+	log.FromContext(ctx).Info("Rejected by the BuildPolicy", "kind", subject.Kind, "namespace", object.GetNamespace(), "name", object.GetName(), "violations", messages)
+
+	resource := schema.GroupResource{Group: buildv1beta1.SchemeGroupVersion.Group, Resource: strings.ToLower(subject.Kind) + "s"}
+	return apierrors.NewForbidden(resource, object.GetName(), fmt.Errorf("%s", message))
+}
@@ -0,0 +1,199 @@
+package v1beta1_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildpolicy"
+	webhookv1beta1 "github.com/redhat-openshift-builds/operator/internal/webhook/shipwright/v1beta1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("Build policy webhooks", Label("webhook"), func() {
+	var (
+		ctx                context.Context
+		k8sClient          client.Client
+		recorder           *record.FakeRecorder
+		buildValidator     *webhookv1beta1.BuildCustomValidator
+		buildRunValidator  *webhookv1beta1.BuildRunCustomValidator
+		build              *buildv1beta1.Build
+		clusterBuildPolicy *openshiftv1alpha1.BuildPolicy
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		clusterBuildPolicy = &openshiftv1alpha1.BuildPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: openshiftv1alpha1.ClusterBuildPolicyName},
+			Spec: openshiftv1alpha1.BuildPolicySpec{
+				BuildPolicyRules: openshiftv1alpha1.BuildPolicyRules{
+					AllowedStrategies:       []openshiftv1alpha1.BuildPolicyStrategy{{Kind: "ClusterBuildStrategy", Name: "buildah"}},
+					AllowedOutputRegistries: []string{"quay.io/team"},
+					MaxTimeout:              &metav1.Duration{Duration: time.Hour},
+				},
+			},
+		}
+		strategyKind := buildv1beta1.ClusterBuildStrategyKind
+		build = &buildv1beta1.Build{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+			Spec: buildv1beta1.BuildSpec{
+				Strategy: buildv1beta1.Strategy{Name: "buildah", Kind: &strategyKind},
+				Output:   buildv1beta1.Image{Image: "quay.io/team/app:latest"},
+			},
+		}
+		k8sClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"team": "a"}}},
+			clusterBuildPolicy,
+		).Build()
+		recorder = record.NewFakeRecorder(10)
+		buildValidator = &webhookv1beta1.BuildCustomValidator{Client: k8sClient, Recorder: recorder, BuildPolicies: true}
+		buildRunValidator = &webhookv1beta1.BuildRunCustomValidator{Client: k8sClient, APIReader: k8sClient, Recorder: recorder, BuildPolicies: true}
+	})
+
+	Describe("Build", func() {
+		It("allows the Builds complying with the BuildPolicy", func() {
+			_, err := buildValidator.ValidateCreate(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("rejects the Builds violating the BuildPolicy and records the violations", func() {
+			build.Spec.Output.Image = "docker.io/library/app"
+			build.Spec.Timeout = &metav1.Duration{Duration: 2 * time.Hour}
+
+			_, err := buildValidator.ValidateCreate(ctx, build)
+			Expect(err).To(Satisfy(apierrors.IsForbidden))
+			Expect(err.Error()).To(ContainSubstring("not in an allowed registry"))
+			Expect(err.Error()).To(ContainSubstring("exceeds the maximum of 1h0m0s"))
+			Expect(recorder.Events).To(Receive(ContainSubstring(buildpolicy.ReasonViolation)))
+		})
+
+		It("does not record the violations of dry run requests", func() {
+			build.Spec.Output.Image = "docker.io/library/app"
+			dryRunCtx := admission.NewContextWithRequest(ctx, admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{DryRun: ptr.To(true)},
+			})
+
+			_, err := buildValidator.ValidateCreate(dryRunCtx, build)
+			Expect(err).To(Satisfy(apierrors.IsForbidden))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("applies the BuildPolicies selecting the namespace over the cluster BuildPolicy", func() {
+			Expect(k8sClient.Create(ctx, &openshiftv1alpha1.BuildPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "team-a"},
+				Spec: openshiftv1alpha1.BuildPolicySpec{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+					BuildPolicyRules: openshiftv1alpha1.BuildPolicyRules{
+						AllowedOutputRegistries: []string{"docker.io"},
+					},
+				},
+			})).To(Succeed())
+			build.Spec.Output.Image = "docker.io/library/app"
+
+			_, err := buildValidator.ValidateCreate(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("allows the updates which do not change the specification or labels", func() {
+			oldBuild := build.DeepCopy()
+			oldBuild.Spec.Output.Image = "docker.io/library/app"
+			newBuild := oldBuild.DeepCopy()
+			newBuild.Annotations = map[string]string{"example.com/touched": "true"}
+
+			_, err := buildValidator.ValidateUpdate(ctx, oldBuild, newBuild)
+			Expect(err).NotTo(HaveOccurred())
+
+			newBuild.Spec.Timeout = &metav1.Duration{Duration: time.Minute}
+			_, err = buildValidator.ValidateUpdate(ctx, oldBuild, newBuild)
+			Expect(err).To(Satisfy(apierrors.IsForbidden))
+		})
+
+		It("allows all Builds when the BuildPolicies are disabled", func() {
+			buildValidator.BuildPolicies = false
+			build.Spec.Output.Image = "docker.io/library/app"
+
+			_, err := buildValidator.ValidateCreate(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("BuildRun", func() {
+		It("checks the BuildRuns against the referenced Build", func() {
+			build.Spec.Strategy.Name = "buildpacks"
+			Expect(k8sClient.Create(ctx, build)).To(Succeed())
+			buildRun := &buildv1beta1.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"},
+				Spec:       buildv1beta1.BuildRunSpec{Build: buildv1beta1.ReferencedBuild{Name: ptr.To("app")}},
+			}
+
+			_, err := buildRunValidator.ValidateCreate(ctx, buildRun)
+			Expect(err).To(Satisfy(apierrors.IsForbidden))
+			Expect(err.Error()).To(ContainSubstring("ClusterBuildStrategy buildpacks is not an allowed strategy"))
+			Expect(recorder.Events).To(Receive(HavePrefix("Warning " + buildpolicy.ReasonViolation + " BuildRun")))
+		})
+
+		It("checks the output and timeout overrides of the BuildRuns", func() {
+			Expect(k8sClient.Create(ctx, build)).To(Succeed())
+			buildRun := &buildv1beta1.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"},
+				Spec: buildv1beta1.BuildRunSpec{
+					Build:   buildv1beta1.ReferencedBuild{Name: ptr.To("app")},
+					Output:  &buildv1beta1.Image{Image: "ghcr.io/team/app"},
+					Timeout: &metav1.Duration{Duration: 3 * time.Hour},
+				},
+			}
+
+			_, err := buildRunValidator.ValidateCreate(ctx, buildRun)
+			Expect(err).To(Satisfy(apierrors.IsForbidden))
+			Expect(err.Error()).To(ContainSubstring("ghcr.io/team/app"))
+			Expect(err.Error()).To(ContainSubstring("timeout 3h0m0s"))
+		})
+
+		It("checks the embedded Build specification with the labels of the BuildRun", func() {
+			clusterBuildPolicy.Spec.RequiredLabels = []string{"cost-center"}
+			Expect(k8sClient.Update(ctx, clusterBuildPolicy)).To(Succeed())
+			buildRun := &buildv1beta1.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"},
+				Spec:       buildv1beta1.BuildRunSpec{Build: buildv1beta1.ReferencedBuild{Spec: &build.Spec}},
+			}
+
+			_, err := buildRunValidator.ValidateCreate(ctx, buildRun)
+			Expect(err).To(Satisfy(apierrors.IsForbidden))
+			Expect(err.Error()).To(ContainSubstring("required label cost-center is missing"))
+
+			buildRun.Labels = map[string]string{"cost-center": "42"}
+			_, err = buildRunValidator.ValidateCreate(ctx, buildRun)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("allows the BuildRuns of missing Builds", func() {
+			buildRun := &buildv1beta1.BuildRun{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"},
+				Spec:       buildv1beta1.BuildRunSpec{Build: buildv1beta1.ReferencedBuild{Name: ptr.To("missing")}},
+			}
+
+			_, err := buildRunValidator.ValidateCreate(ctx, buildRun)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,693 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildcache"
+	"github.com/redhat-openshift-builds/operator/internal/builddefaults"
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	"github.com/redhat-openshift-builds/operator/internal/entitlements"
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/variants"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+//+kubebuilder:rbac:groups=config.openshift.io,resources=builds,verbs=get;list;watch
+//+kubebuilder:rbac:groups=shipwright.io,resources=builds;buildstrategies;clusterbuildstrategies,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=image.openshift.io,resources=imagestreams,verbs=get;list;watch
+
+// SetupBuildRunWebhookWithManager registers the BuildRun webhooks with the manager. Each feature
+// mutates the BuildRuns in its own webhook, so that the API server applies the failure policy of
+// the feature. The mutating webhooks read the Builds, strategies and other namespaced objects
+// through the API reader, rather than caching them across the cluster, and only the singleton
+// OpenShiftBuild through the cache.
+func SetupBuildRunWebhookWithManager(mgr ctrl.Manager, buildDefaults, imageStreamOutputs, builderImageStreams, buildPolicies, buildQueue bool) error {
+	defaulters := []struct {
+		path      string
+		defaulter admission.CustomDefaulter
+	}{
+		{"/mutate-shipwright-io-v1beta1-buildrun-imagestream-output", &BuildRunImageStreamOutputDefaulter{
+			APIReader:          mgr.GetAPIReader(),
+			ImageStreamOutputs: imageStreamOutputs,
+		}},
+		{"/mutate-shipwright-io-v1beta1-buildrun-builder-image", &BuildRunBuilderImageDefaulter{
+			APIReader:           mgr.GetAPIReader(),
+			BuilderImageStreams: builderImageStreams,
+		}},
+		{"/mutate-shipwright-io-v1beta1-buildrun-strategy-variant", &BuildRunStrategyVariantDefaulter{
+			APIReader: mgr.GetAPIReader(),
+		}},
+		{"/mutate-shipwright-io-v1beta1-buildrun-entitlement", &BuildRunEntitlementDefaulter{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+		}},
+		{"/mutate-shipwright-io-v1beta1-buildrun-build-cache", &BuildRunBuildCacheDefaulter{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+		}},
+		{"/mutate-shipwright-io-v1beta1-buildrun-build-defaults", &BuildRunBuildDefaultsDefaulter{
+			APIReader:     mgr.GetAPIReader(),
+			BuildDefaults: buildDefaults,
+		}},
+		{"/mutate-shipwright-io-v1beta1-buildrun-build-queue", &BuildRunBuildQueueDefaulter{
+			Client:     mgr.GetClient(),
+			APIReader:  mgr.GetAPIReader(),
+			BuildQueue: buildQueue,
+		}},
+	}
+	for _, d := range defaulters {
+		err := ctrl.NewWebhookManagedBy(mgr).
+			For(&buildv1beta1.BuildRun{}).
+			WithDefaulter(d.defaulter).
+			WithDefaulterCustomPath(d.path).
+			Complete()
+		if err != nil {
+			return err
+		}
+	}
+	err := ctrl.NewWebhookManagedBy(mgr).
+		For(&buildv1beta1.BuildRun{}).
+		WithValidator(&BuildRunBuildQueueValidator{
+			ServiceAccount: common.FetchCurrentServiceAccountUsername(),
+		}).
+		WithValidatorCustomPath("/validate-shipwright-io-v1beta1-buildrun-build-queue").
+		Complete()
+	if err != nil {
+		return err
+	}
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&buildv1beta1.BuildRun{}).
+		WithValidator(&BuildRunCustomValidator{
+			Client:        mgr.GetClient(),
+			APIReader:     mgr.GetAPIReader(),
+			Recorder:      mgr.GetEventRecorderFor("openshift-builds-operator"),
+			BuildPolicies: buildPolicies,
+		}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-imagestream-output,mutating=true,failurePolicy=ignore,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-imagestream-output-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunImageStreamOutputDefaulter sets the internal registry output of the BuildRuns with an
+// ImageStreamTag output on create. Failures are ignored by the API server, so that builds keep
+// running when the operator is unavailable.
+type BuildRunImageStreamOutputDefaulter struct {
+	APIReader client.Reader
+
+	// ImageStreamOutputs pushes the images of the BuildRuns annotated with an ImageStreamTag
+	// output to the internal registry.
+	ImageStreamOutputs bool
+}
+
+var _ webhook.CustomDefaulter = &BuildRunImageStreamOutputDefaulter{}
+
+// Default implements webhook.CustomDefaulter. It pushes the image to the internal registry when
+// the BuildRun, or else its Build, is annotated with an ImageStreamTag output.
+func (d *BuildRunImageStreamOutputDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil || !d.ImageStreamOutputs {
+		return err
+	}
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+
+	value, ok := buildRun.Annotations[imagestreams.OutputAnnotation]
+	if !ok && build != nil {
+		value, ok = build.Annotations[imagestreams.OutputAnnotation]
+	}
+	if !ok {
+		return nil
+	}
+	tag, err := imagestreams.ParseTag(buildRun.Namespace, value)
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation: %v", imagestreams.OutputAnnotation, err)
+	}
+	if err := imagestreams.SetOutput(ctx, d.APIReader, buildRun, buildSpec(buildRun, build), tag); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Set the ImageStreamTag output", "buildrun", client.ObjectKeyFromObject(buildRun), "imagestreamtag", tag.String())
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-builder-image,mutating=true,failurePolicy=ignore,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-builder-image-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunBuilderImageDefaulter resolves the builder images of the BuildRuns referencing an
+// ImageStreamTag on create. Failures are ignored by the API server, so that builds keep running
+// when the operator is unavailable.
+type BuildRunBuilderImageDefaulter struct {
+	APIReader client.Reader
+
+	// BuilderImageStreams pins the builder images referencing an ImageStreamTag to the digest of
+	// the tag.
+	BuilderImageStreams bool
+}
+
+var _ webhook.CustomDefaulter = &BuildRunBuilderImageDefaulter{}
+
+// Default implements webhook.CustomDefaulter. It pins the builder image of the BuildRun, or else
+// of its Build, to the digest of the ImageStreamTag it references, for the strategies with a
+// builder image parameter. The references without a namespace must resolve to an ImageStreamTag
+// of the openshift namespace, while the other references are left unchanged when no such
+// ImageStream exists, as they may name an image of a registry. Tags without an image reject the
+// BuildRun.
+func (d *BuildRunBuilderImageDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil || !d.BuilderImageStreams {
+		return err
+	}
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+	spec := buildSpec(buildRun, build)
+	if spec == nil {
+		return nil
+	}
+
+	value, ok := paramValue(buildRun.Spec.ParamValues, imagestreams.BuilderImageParam)
+	if !ok {
+		value, ok = paramValue(spec.ParamValues, imagestreams.BuilderImageParam)
+	}
+	if !ok {
+		return nil
+	}
+	tag := imagestreams.ParseBuilderImage(value)
+	if tag == nil {
+		return nil
+	}
+	strategy, err := buildStrategy(ctx, d.APIReader, buildRun.Namespace, spec.Strategy)
+	if err != nil || strategy == nil {
+		return err
+	}
+	if !slices.ContainsFunc(strategy.GetParameters(), func(p buildv1beta1.Parameter) bool {
+		return p.Name == imagestreams.BuilderImageParam
+	}) {
+		return nil
+	}
+
+	image, err := imagestreams.ResolveBuilderImage(ctx, d.APIReader, tag)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the builder image %q: %v", value, err)
+	}
+	if image == "" {
+		if !strings.Contains(value, "/") {
+			return fmt.Errorf("failed to resolve the builder image %q: ImageStream %s/%s not found", value, tag.Namespace, tag.ImageStream)
+		}
+		return nil
+	}
+
+	setParamValue(buildRun, imagestreams.BuilderImageParam, image)
+	annotations := buildRun.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[imagestreams.BuilderImageAnnotation] = tag.Namespace + "/" + tag.String()
+	buildRun.SetAnnotations(annotations)
+	log.FromContext(ctx).Info("Resolved the builder image", "buildrun", client.ObjectKeyFromObject(buildRun), "imagestreamtag", annotations[imagestreams.BuilderImageAnnotation], "image", image)
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-strategy-variant,mutating=true,failurePolicy=fail,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-strategy-variant-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunStrategyVariantDefaulter runs the BuildRuns of the hardened strategy variants sandboxed
+// or in a user namespace on create. The BuildRuns are rejected when the webhook fails, rather
+// than running a hardened variant without its isolation.
+type BuildRunStrategyVariantDefaulter struct {
+	APIReader client.Reader
+}
+
+var _ webhook.CustomDefaulter = &BuildRunStrategyVariantDefaulter{}
+
+// Default implements webhook.CustomDefaulter. It runs the BuildRuns of the sandboxed strategy
+// variants with the RuntimeClass of the strategy, unless the BuildRun or its Build sets one, and
+// labels the BuildRuns of the user namespace strategy variants so that their pods run with
+// hostUsers set to false.
+func (d *BuildRunStrategyVariantDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil {
+		return err
+	}
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+	spec := buildSpec(buildRun, build)
+	if spec == nil {
+		return nil
+	}
+	strategy, err := buildStrategy(ctx, d.APIReader, buildRun.Namespace, spec.Strategy)
+	if err != nil || strategy == nil {
+		return err
+	}
+	logger := log.FromContext(ctx).WithValues("buildrun", client.ObjectKeyFromObject(buildRun), "strategy", strategy.GetName())
+
+	switch strategy.(client.Object).GetLabels()[variants.Label] {
+	case variants.Sandboxed:
+		runtimeClassName := strategy.GetAnnotations()[variants.RuntimeClassAnnotation]
+		if runtimeClassName == "" || buildRun.Spec.RuntimeClassName != nil || spec.RuntimeClassName != nil {
+			return nil
+		}
+		buildRun.Spec.RuntimeClassName = &runtimeClassName
+		logger.Info("Set the RuntimeClass of the sandboxed strategy", "runtimeClassName", runtimeClassName)
+	case variants.UserNamespace:
+		labels := buildRun.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[variants.HostUsersLabel] = "false"
+		buildRun.SetLabels(labels)
+		logger.Info("Running the BuildRun in a user namespace")
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-entitlement,mutating=true,failurePolicy=ignore,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-entitlement-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunEntitlementDefaulter mounts the shared cluster entitlement into the BuildRuns requesting
+// it on create. Failures are ignored by the API server, so that builds keep running when the
+// operator is unavailable.
+type BuildRunEntitlementDefaulter struct {
+	// Client reads the OpenShiftBuild from the cache.
+	Client client.Reader
+
+	// APIReader reads the namespaced objects without caching them.
+	APIReader client.Reader
+}
+
+var _ webhook.CustomDefaulter = &BuildRunEntitlementDefaulter{}
+
+// Default implements webhook.CustomDefaulter. It mounts the shared cluster entitlement when the
+// BuildRun, or else its Build, is annotated to request it. The entitlement is only mounted in the
+// namespaces it is shared with, and into the strategies declaring an overridable entitlement
+// volume.
+func (d *BuildRunEntitlementDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil {
+		return err
+	}
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+	value, ok := buildRun.Annotations[entitlements.MountAnnotation]
+	if !ok && build != nil {
+		value, ok = build.Annotations[entitlements.MountAnnotation]
+	}
+	if !ok || value != "true" {
+		return nil
+	}
+	logger := log.FromContext(ctx).WithValues("buildrun", client.ObjectKeyFromObject(buildRun))
+
+	openShiftBuild := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: common.OpenShiftBuildResourceName}, openShiftBuild); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !entitlements.Enabled(openShiftBuild) {
+		return nil
+	}
+	// Only the labels of the namespace are needed
+	metadata := &metav1.PartialObjectMetadata{}
+	metadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Namespace"))
+	if err := d.APIReader.Get(ctx, types.NamespacedName{Name: buildRun.Namespace}, metadata); err != nil {
+		return err
+	}
+	if !entitlements.Selected(openShiftBuild, &corev1.Namespace{ObjectMeta: metadata.ObjectMeta}) {
+		logger.Info("Not mounting the cluster entitlement, which is not shared with the namespace")
+		return nil
+	}
+
+	spec := buildSpec(buildRun, build)
+	if spec == nil {
+		return nil
+	}
+	strategy, err := buildStrategy(ctx, d.APIReader, buildRun.Namespace, spec.Strategy)
+	if err != nil || strategy == nil {
+		return err
+	}
+	if !overridableVolume(strategy, entitlements.VolumeName) {
+		logger.Info("Not mounting the cluster entitlement, the strategy has no overridable entitlement volume", "strategy", spec.Strategy.Name)
+		return nil
+	}
+
+	if entitlements.Mount(buildRun, spec) {
+		logger.Info("Mounted the cluster entitlement")
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-build-cache,mutating=true,failurePolicy=ignore,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-build-cache-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunBuildCacheDefaulter mounts the build cache into the BuildRuns requesting it on create.
+// Failures are ignored by the API server, so that builds keep running without a cache when the
+// operator is unavailable.
+type BuildRunBuildCacheDefaulter struct {
+	// Client reads the OpenShiftBuild from the cache.
+	Client client.Reader
+
+	// APIReader reads the namespaced objects without caching them.
+	APIReader client.Reader
+}
+
+var _ webhook.CustomDefaulter = &BuildRunBuildCacheDefaulter{}
+
+// Default implements webhook.CustomDefaulter. It mounts the build cache claim when the BuildRun,
+// or else its Build, is annotated to request it. The claim is only mounted once created by the
+// operator, and into the strategies declaring an overridable cache volume.
+func (d *BuildRunBuildCacheDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil {
+		return err
+	}
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+	if !buildcache.Requested(buildRun, build) {
+		return nil
+	}
+	logger := log.FromContext(ctx).WithValues("buildrun", client.ObjectKeyFromObject(buildRun))
+
+	openShiftBuild := &openshiftv1alpha1.OpenShiftBuild{}
+	if err := d.Client.Get(ctx, types.NamespacedName{Name: common.OpenShiftBuildResourceName}, openShiftBuild); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !buildcache.Enabled(openShiftBuild) {
+		return nil
+	}
+	claimName, err := buildcache.ClaimName(openShiftBuild, build)
+	if err != nil {
+		logger.Info("Not mounting the build cache", "reason", err.Error())
+		return nil
+	}
+	// Only the existence of the claim is needed
+	claim := &metav1.PartialObjectMetadata{}
+	claim.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+	if err := d.APIReader.Get(ctx, types.NamespacedName{Namespace: buildRun.Namespace, Name: claimName}, claim); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("Not mounting the build cache, the claim does not exist yet", "claim", claimName)
+			return nil
+		}
+		return err
+	}
+
+	spec := buildSpec(buildRun, build)
+	if spec == nil {
+		return nil
+	}
+	strategy, err := buildStrategy(ctx, d.APIReader, buildRun.Namespace, spec.Strategy)
+	if err != nil || strategy == nil {
+		return err
+	}
+	if !overridableVolume(strategy, buildcache.VolumeName) {
+		logger.Info("Not mounting the build cache, the strategy has no overridable cache volume", "strategy", spec.Strategy.Name)
+		return nil
+	}
+
+	if buildcache.Mount(buildRun, spec, claimName) {
+		logger.Info("Mounted the build cache", "claim", claimName)
+	}
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-build-defaults,mutating=true,failurePolicy=ignore,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-build-defaults-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunBuildDefaultsDefaulter applies the cluster build defaults and overrides of
+// build.config.openshift.io to the BuildRuns on create. Failures are ignored by the API server,
+// so that builds keep running when the operator is unavailable.
+type BuildRunBuildDefaultsDefaulter struct {
+	APIReader client.Reader
+
+	// BuildDefaults applies the cluster build defaults and overrides.
+	BuildDefaults bool
+}
+
+var _ webhook.CustomDefaulter = &BuildRunBuildDefaultsDefaulter{}
+
+// Default implements webhook.CustomDefaulter. The resources are applied to the steps of the
+// strategy of the referenced Build, if known.
+func (d *BuildRunBuildDefaultsDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil || !d.BuildDefaults {
+		return err
+	}
+	defaults, err := builddefaults.Get(ctx, d.APIReader)
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster build configuration: %v", err)
+	}
+	if defaults.IsEmpty() {
+		return nil
+	}
+
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+	spec := buildSpec(buildRun, build)
+	var steps []buildv1beta1.Step
+	if spec != nil {
+		strategy, err := buildStrategy(ctx, d.APIReader, buildRun.Namespace, spec.Strategy)
+		if err != nil {
+			return err
+		}
+		if strategy != nil {
+			steps = strategy.GetBuildSteps()
+		}
+	}
+
+	defaults.Apply(buildRun, spec, steps)
+	log.FromContext(ctx).Info("Applied the cluster build defaults", "buildrun", client.ObjectKeyFromObject(buildRun))
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-shipwright-io-v1beta1-buildrun-build-queue,mutating=true,failurePolicy=fail,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create,versions=v1beta1,name=mbuildrun-build-queue-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunBuildQueueDefaulter admits the BuildRuns of the namespaces with concurrency limits into
+// the queue of their namespace on create, where they wait until the build queue controller starts
+// them. The BuildRuns are rejected when the webhook fails, rather than skipping the queue, as for
+// the strategy variants. The webhook is called for the BuildRuns of all namespaces, as it removes
+// the queue metadata set by the users.
+type BuildRunBuildQueueDefaulter struct {
+	// Client reads the cached BuildNamespaceConfigs.
+	Client client.Reader
+
+	APIReader client.Reader
+
+	// BuildQueue queues the BuildRuns of the namespaces with concurrency limits.
+	BuildQueue bool
+}
+
+var _ webhook.CustomDefaulter = &BuildRunBuildQueueDefaulter{}
+
+// Default implements webhook.CustomDefaulter. The queue metadata set by the user is removed.
+// Shipwright creates the TaskRun of the BuildRun right away, so the timeout of the BuildRun, or
+// else of its Build, is recorded for the queue to enforce from the start of the BuildRun, and the
+// TaskRun runs without timeout. The queue label is propagated to the pods of the BuildRun, which
+// are held by a scheduling gate until it starts.
+func (d *BuildRunBuildQueueDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	buildRun, err := asBuildRun(obj)
+	if err != nil {
+		return err
+	}
+	// The queue metadata is set by the operator alone, so that BuildRuns cannot skip the queue
+	delete(buildRun.Labels, buildqueue.Label)
+	delete(buildRun.Annotations, buildqueue.StartedAnnotation)
+	delete(buildRun.Annotations, buildqueue.TimeoutAnnotation)
+	if !d.BuildQueue || buildRun.IsCanceled() {
+		return nil
+	}
+	config := &openshiftv1alpha1.BuildNamespaceConfig{}
+	err = d.Client.Get(ctx, types.NamespacedName{Namespace: buildRun.Namespace, Name: openshiftv1alpha1.BuildNamespaceConfigName}, config)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !buildqueue.Limited(config.Spec.Concurrency) {
+		return nil
+	}
+	build, err := referencedBuild(ctx, d.APIReader, buildRun)
+	if err != nil {
+		return err
+	}
+
+	timeout := buildRun.Spec.Timeout
+	if spec := buildSpec(buildRun, build); timeout == nil && spec != nil {
+		timeout = spec.Timeout
+	}
+	metav1.SetMetaDataLabel(&buildRun.ObjectMeta, buildqueue.Label, "")
+	if timeout != nil {
+		metav1.SetMetaDataAnnotation(&buildRun.ObjectMeta, buildqueue.TimeoutAnnotation, timeout.Duration.String())
+	}
+	buildRun.Spec.Timeout = &metav1.Duration{}
+	log.FromContext(ctx).Info("Queued the BuildRun", "buildrun", client.ObjectKeyFromObject(buildRun))
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-shipwright-io-v1beta1-buildrun-build-queue,mutating=false,failurePolicy=fail,sideEffects=None,groups=shipwright.io,resources=buildruns,verbs=create;update,versions=v1beta1,name=vbuildrun-build-queue-v1beta1.shipwright.io,admissionReviewVersions=v1
+
+// BuildRunBuildQueueValidator rejects the changes to the queue metadata of the BuildRuns made by
+// anyone but the operator, so that the queued BuildRuns cannot start themselves. The webhook is
+// only called for the BuildRuns admitted into a queue.
+type BuildRunBuildQueueValidator struct {
+	// ServiceAccount is the username of the operator.
+	ServiceAccount string
+}
+
+var _ webhook.CustomValidator = &BuildRunBuildQueueValidator{}
+
+// ValidateCreate implements webhook.CustomValidator. The queue defaulter removes the start time set
+// by the user, so a BuildRun created with one was not admitted by the queue.
+func (v *BuildRunBuildQueueValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	buildRun, err := asBuildRun(obj)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := buildRun.Annotations[buildqueue.StartedAnnotation]; ok {
+		return nil, queueMetadataForbidden(buildRun)
+	}
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *BuildRunBuildQueueValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldBuildRun, err := asBuildRun(oldObj)
+	if err != nil {
+		return nil, err
+	}
+	buildRun, err := asBuildRun(newObj)
+	if err != nil {
+		return nil, err
+	}
+	if request, err := admission.RequestFromContext(ctx); err == nil && request.UserInfo.Username == v.ServiceAccount {
+		return nil, nil
+	}
+	_, wasAdmitted := oldBuildRun.Labels[buildqueue.Label]
+	if wasAdmitted != buildqueue.Admitted(buildRun) ||
+		oldBuildRun.Annotations[buildqueue.StartedAnnotation] != buildRun.Annotations[buildqueue.StartedAnnotation] ||
+		oldBuildRun.Annotations[buildqueue.TimeoutAnnotation] != buildRun.Annotations[buildqueue.TimeoutAnnotation] {
+		return nil, queueMetadataForbidden(buildRun)
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *BuildRunBuildQueueValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// queueMetadataForbidden returns the error of the BuildRuns setting their own queue metadata
+func queueMetadataForbidden(buildRun *buildv1beta1.BuildRun) error {
+	resource := schema.GroupResource{Group: buildv1beta1.SchemeGroupVersion.Group, Resource: "buildruns"}
+	return apierrors.NewForbidden(resource, buildRun.Name, fmt.Errorf("the %s label and the %s and %s annotations are managed by the build queue",
+		buildqueue.Label, buildqueue.StartedAnnotation, buildqueue.TimeoutAnnotation))
+}
+
+// asBuildRun returns the BuildRun admitted by a webhook
+func asBuildRun(obj runtime.Object) (*buildv1beta1.BuildRun, error) {
+	buildRun, ok := obj.(*buildv1beta1.BuildRun)
+	if !ok {
+		return nil, fmt.Errorf("expected a BuildRun object but got %T", obj)
+	}
+	return buildRun, nil
+}
+
+// referencedBuild returns the Build referenced by the BuildRun, or nil if it has none or it does
+// not exist
+func referencedBuild(ctx context.Context, reader client.Reader, buildRun *buildv1beta1.BuildRun) (*buildv1beta1.Build, error) {
+	if buildRun.Spec.Build.Spec != nil || buildRun.Spec.Build.Name == nil {
+		return nil, nil
+	}
+	build := &buildv1beta1.Build{}
+	err := reader.Get(ctx, types.NamespacedName{Namespace: buildRun.Namespace, Name: *buildRun.Spec.Build.Name}, build)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return build, nil
+}
+
+// buildSpec returns the specification of the referenced Build, or else the embedded one
+func buildSpec(buildRun *buildv1beta1.BuildRun, build *buildv1beta1.Build) *buildv1beta1.BuildSpec {
+	if build != nil {
+		return &build.Spec
+	}
+	return buildRun.Spec.Build.Spec
+}
+
+// buildStrategy returns the build strategy, or nil if it does not exist
+func buildStrategy(ctx context.Context, reader client.Reader, namespace string, strategy buildv1beta1.Strategy) (buildv1beta1.BuilderStrategy, error) {
+	var object buildv1beta1.BuilderStrategy
+	key := types.NamespacedName{Name: strategy.Name}
+	if strategy.Kind != nil && *strategy.Kind == buildv1beta1.ClusterBuildStrategyKind {
+		object = &buildv1beta1.ClusterBuildStrategy{}
+	} else {
+		object = &buildv1beta1.BuildStrategy{}
+		key.Namespace = namespace
+	}
+	if err := reader.Get(ctx, key, object.(client.Object)); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// paramValue returns the single value of the named parameter
+func paramValue(params []buildv1beta1.ParamValue, name string) (string, bool) {
+	for _, param := range params {
+		if param.Name == name && param.SingleValue != nil && param.SingleValue.Value != nil {
+			return *param.SingleValue.Value, true
+		}
+	}
+	return "", false
+}
+
+// setParamValue sets the single value of the named parameter of the BuildRun, which overrides
+// the value of its Build
+func setParamValue(buildRun *buildv1beta1.BuildRun, name, value string) {
+	for i := range buildRun.Spec.ParamValues {
+		if buildRun.Spec.ParamValues[i].Name == name {
+			buildRun.Spec.ParamValues[i] = buildv1beta1.ParamValue{
+				Name:        name,
+				SingleValue: &buildv1beta1.SingleValue{Value: &value},
+			}
+			return
+		}
+	}
+	buildRun.Spec.ParamValues = append(buildRun.Spec.ParamValues, buildv1beta1.ParamValue{
+		Name:        name,
+		SingleValue: &buildv1beta1.SingleValue{Value: &value},
+	})
+}
+
+// overridableVolume returns true if the strategy declares the volume as overridable
+func overridableVolume(strategy buildv1beta1.BuilderStrategy, name string) bool {
+	for _, volume := range strategy.GetVolumes() {
+		if volume.Name == name && volume.Overridable != nil && *volume.Overridable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,606 @@
+package v1beta1_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/buildcache"
+	"github.com/redhat-openshift-builds/operator/internal/buildqueue"
+	"github.com/redhat-openshift-builds/operator/internal/entitlements"
+	"github.com/redhat-openshift-builds/operator/internal/imagestreams"
+	"github.com/redhat-openshift-builds/operator/internal/shipwright/variants"
+	webhookv1beta1 "github.com/redhat-openshift-builds/operator/internal/webhook/shipwright/v1beta1"
+	buildv1beta1 "github.com/shipwright-io/build/pkg/apis/build/v1beta1"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var _ = Describe("BuildRun webhook", Label("webhook"), func() {
+	var (
+		ctx      context.Context
+		scheme   *runtime.Scheme
+		buildRun *buildv1beta1.BuildRun
+		objects  []client.Object
+	)
+
+	// newClient returns a client reading the objects of the spec
+	newClient := func() client.Client {
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	}
+
+	clusterBuild := func() *unstructured.Unstructured {
+		object := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "config.openshift.io/v1",
+			"kind":       "Build",
+			"metadata":   map[string]interface{}{"name": "cluster"},
+			"spec": map[string]interface{}{
+				"buildDefaults": map[string]interface{}{
+					"env": []interface{}{map[string]interface{}{"name": "GOFLAGS", "value": "-mod=vendor"}},
+					"resources": map[string]interface{}{
+						"limits": map[string]interface{}{"memory": "2Gi"},
+					},
+				},
+			},
+		}}
+		return object
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(buildv1beta1.AddToScheme(scheme)).To(Succeed())
+		Expect(openshiftv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		strategyKind := buildv1beta1.ClusterBuildStrategyKind
+		objects = []client.Object{
+			&buildv1beta1.Build{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app"},
+				Spec: buildv1beta1.BuildSpec{
+					Strategy: buildv1beta1.Strategy{Name: "buildah", Kind: &strategyKind},
+				},
+			},
+			&buildv1beta1.ClusterBuildStrategy{
+				ObjectMeta: metav1.ObjectMeta{Name: "buildah"},
+				Spec: buildv1beta1.BuildStrategySpec{
+					Steps: []buildv1beta1.Step{{Name: "build-and-push"}},
+				},
+			},
+		}
+		buildRun = &buildv1beta1.BuildRun{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "app-1"},
+			Spec: buildv1beta1.BuildRunSpec{
+				Build: buildv1beta1.ReferencedBuild{Name: ptr.To("app")},
+			},
+		}
+	})
+
+	Describe("build defaults", func() {
+		var defaulter *webhookv1beta1.BuildRunBuildDefaultsDefaulter
+
+		JustBeforeEach(func() {
+			defaulter = &webhookv1beta1.BuildRunBuildDefaultsDefaulter{APIReader: newClient(), BuildDefaults: true}
+		})
+
+		When("the cluster has no build configuration", func() {
+			It("leaves the BuildRun unchanged", func() {
+				expected := buildRun.DeepCopy()
+				Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+				Expect(buildRun).To(Equal(expected))
+			})
+		})
+
+		When("the cluster has build defaults", func() {
+			BeforeEach(func() {
+				objects = append(objects, clusterBuild())
+			})
+
+			It("applies them using the referenced Build and strategy", func() {
+				Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+				Expect(buildRun.Spec.Env).To(Equal([]corev1.EnvVar{{Name: "GOFLAGS", Value: "-mod=vendor"}}))
+				Expect(buildRun.Spec.StepResources).To(HaveLen(1))
+				Expect(buildRun.Spec.StepResources[0].Name).To(Equal("build-and-push"))
+				Expect(buildRun.Spec.StepResources[0].Resources.Limits.Memory().String()).To(Equal("2Gi"))
+			})
+
+			It("applies the environment when the Build does not exist yet", func() {
+				buildRun.Spec.Build.Name = ptr.To("missing")
+				Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+				Expect(buildRun.Spec.Env).To(HaveLen(1))
+				Expect(buildRun.Spec.StepResources).To(BeEmpty())
+			})
+		})
+
+		It("rejects other objects", func() {
+			Expect(defaulter.Default(ctx, &buildv1beta1.Build{})).To(MatchError(ContainSubstring("expected a BuildRun")))
+		})
+	})
+
+	When("the Build has an ImageStreamTag output", func() {
+		var defaulter *webhookv1beta1.BuildRunImageStreamOutputDefaulter
+
+		BeforeEach(func() {
+			build := objects[0].(*buildv1beta1.Build)
+			build.Annotations = map[string]string{imagestreams.OutputAnnotation: "app:v1"}
+			build.Spec.Output = buildv1beta1.Image{Image: "placeholder"}
+			objects = append(objects, &corev1.ServiceAccount{
+				ObjectMeta:       metav1.ObjectMeta{Namespace: "test", Name: "pipeline"},
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "pipeline-dockercfg-abcde"}},
+			})
+		})
+
+		JustBeforeEach(func() {
+			defaulter = &webhookv1beta1.BuildRunImageStreamOutputDefaulter{APIReader: newClient(), ImageStreamOutputs: true}
+		})
+
+		It("pushes the image to the internal registry", func() {
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Output.Image).To(Equal("image-registry.openshift-image-registry.svc:5000/test/app:v1"))
+			Expect(buildRun.Spec.Output.PushSecret).To(Equal(ptr.To("pipeline-dockercfg-abcde")))
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(imagestreams.OutputAnnotation, "app:v1"))
+		})
+
+		It("prefers the ImageStreamTag of the BuildRun", func() {
+			buildRun.Annotations = map[string]string{imagestreams.OutputAnnotation: "other"}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Output.Image).To(Equal("image-registry.openshift-image-registry.svc:5000/test/other:latest"))
+		})
+
+		It("rejects invalid ImageStreamTags", func() {
+			buildRun.Annotations = map[string]string{imagestreams.OutputAnnotation: "Invalid:"}
+			Expect(defaulter.Default(ctx, buildRun)).To(MatchError(ContainSubstring("invalid")))
+		})
+
+		It("leaves the output unchanged when the feature is disabled", func() {
+			defaulter.ImageStreamOutputs = false
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Output).To(BeNil())
+		})
+	})
+
+	When("the Build requests the cluster entitlement", func() {
+		var defaulter *webhookv1beta1.BuildRunEntitlementDefaulter
+
+		// newDefaulter returns a defaulter reading the objects of the spec
+		newDefaulter := func() *webhookv1beta1.BuildRunEntitlementDefaulter {
+			reader := newClient()
+			return &webhookv1beta1.BuildRunEntitlementDefaulter{Client: reader, APIReader: reader}
+		}
+
+		BeforeEach(func() {
+			build := objects[0].(*buildv1beta1.Build)
+			build.Annotations = map[string]string{entitlements.MountAnnotation: "true"}
+			strategy := objects[1].(*buildv1beta1.ClusterBuildStrategy)
+			strategy.Spec.Volumes = []buildv1beta1.BuildStrategyVolume{{
+				Name:         entitlements.VolumeName,
+				Overridable:  ptr.To(true),
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}}
+			objects = append(objects,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: map[string]string{"entitled": "true"}}},
+				&openshiftv1alpha1.OpenShiftBuild{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+					Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+						Entitlements: &openshiftv1alpha1.Entitlements{
+							State: openshiftv1alpha1.Enabled,
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{"entitled": "true"},
+							},
+						},
+					},
+				},
+			)
+		})
+
+		JustBeforeEach(func() {
+			defaulter = newDefaulter()
+		})
+
+		It("mounts the SharedSecret into the entitlement volume", func() {
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(HaveLen(1))
+			Expect(buildRun.Spec.Volumes[0].Name).To(Equal(entitlements.VolumeName))
+			Expect(buildRun.Spec.Volumes[0].CSI).NotTo(BeNil())
+			Expect(buildRun.Spec.Volumes[0].CSI.Driver).To(Equal(entitlements.CSIDriverName))
+			Expect(buildRun.Spec.Volumes[0].CSI.VolumeAttributes).To(HaveKeyWithValue("sharedSecret", entitlements.SharedSecretName))
+		})
+
+		It("keeps the volume of the BuildRun", func() {
+			buildRun.Spec.Volumes = []buildv1beta1.BuildVolume{{
+				Name:         entitlements.VolumeName,
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "my-entitlement"}},
+			}}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(HaveLen(1))
+			Expect(buildRun.Spec.Volumes[0].Secret).NotTo(BeNil())
+		})
+
+		It("does not mount it when the namespace is not selected", func() {
+			objects[2].(*corev1.Namespace).Labels = nil
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(BeEmpty())
+		})
+
+		It("does not mount it when the strategy has no entitlement volume", func() {
+			objects[1].(*buildv1beta1.ClusterBuildStrategy).Spec.Volumes = nil
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(BeEmpty())
+		})
+	})
+
+	When("the Build requests the build cache", func() {
+		var defaulter *webhookv1beta1.BuildRunBuildCacheDefaulter
+
+		// newDefaulter returns a defaulter reading the objects of the spec
+		newDefaulter := func() *webhookv1beta1.BuildRunBuildCacheDefaulter {
+			reader := newClient()
+			return &webhookv1beta1.BuildRunBuildCacheDefaulter{Client: reader, APIReader: reader}
+		}
+
+		BeforeEach(func() {
+			build := objects[0].(*buildv1beta1.Build)
+			build.Annotations = map[string]string{buildcache.Annotation: "true"}
+			strategy := objects[1].(*buildv1beta1.ClusterBuildStrategy)
+			strategy.Spec.Volumes = []buildv1beta1.BuildStrategyVolume{{
+				Name:         buildcache.VolumeName,
+				Overridable:  ptr.To(true),
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}}
+			objects = append(objects,
+				&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: "build-cache-app"}},
+				&openshiftv1alpha1.OpenShiftBuild{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+					Spec: openshiftv1alpha1.OpenShiftBuildSpec{
+						BuildCache: &openshiftv1alpha1.BuildCache{
+							State: openshiftv1alpha1.Enabled,
+							Scope: openshiftv1alpha1.BuildCacheScopeBuild,
+						},
+					},
+				},
+			)
+		})
+
+		JustBeforeEach(func() {
+			defaulter = newDefaulter()
+		})
+
+		It("mounts the claim of the Build into the cache volume", func() {
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(HaveLen(1))
+			Expect(buildRun.Spec.Volumes[0].Name).To(Equal(buildcache.VolumeName))
+			Expect(buildRun.Spec.Volumes[0].PersistentVolumeClaim).NotTo(BeNil())
+			Expect(buildRun.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal("build-cache-app"))
+		})
+
+		It("does not mount it before the claim is created", func() {
+			objects[2].(*corev1.PersistentVolumeClaim).Name = "other"
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(BeEmpty())
+		})
+
+		It("does not mount it when the build cache is disabled", func() {
+			objects[3].(*openshiftv1alpha1.OpenShiftBuild).Spec.BuildCache.State = openshiftv1alpha1.Disabled
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(BeEmpty())
+		})
+
+		It("does not mount it when the strategy has no cache volume", func() {
+			objects[1].(*buildv1beta1.ClusterBuildStrategy).Spec.Volumes = nil
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.Volumes).To(BeEmpty())
+		})
+	})
+
+	When("the Build references a builder ImageStreamTag", func() {
+		var defaulter *webhookv1beta1.BuildRunBuilderImageDefaulter
+
+		BeforeEach(func() {
+			build := objects[0].(*buildv1beta1.Build)
+			build.Spec.ParamValues = []buildv1beta1.ParamValue{{
+				Name:        imagestreams.BuilderImageParam,
+				SingleValue: &buildv1beta1.SingleValue{Value: ptr.To("nodejs:20-ubi9")},
+			}}
+			strategy := objects[1].(*buildv1beta1.ClusterBuildStrategy)
+			strategy.Spec.Parameters = []buildv1beta1.Parameter{{Name: imagestreams.BuilderImageParam}}
+			objects = append(objects, &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "image.openshift.io/v1",
+				"kind":       "ImageStream",
+				"metadata":   map[string]interface{}{"namespace": "openshift", "name": "nodejs"},
+				"status": map[string]interface{}{
+					"tags": []interface{}{
+						map[string]interface{}{"tag": "20-ubi9", "items": []interface{}{
+							map[string]interface{}{"image": "sha256:2222", "dockerImageReference": "registry.example.com/nodejs@sha256:2222"},
+						}},
+						map[string]interface{}{"tag": "18-ubi9", "items": []interface{}{}},
+					},
+				},
+			}})
+		})
+
+		JustBeforeEach(func() {
+			defaulter = &webhookv1beta1.BuildRunBuilderImageDefaulter{APIReader: newClient(), BuilderImageStreams: true}
+		})
+
+		builderImage := func() string {
+			Expect(buildRun.Spec.ParamValues).To(HaveLen(1))
+			Expect(buildRun.Spec.ParamValues[0].Name).To(Equal(imagestreams.BuilderImageParam))
+			return *buildRun.Spec.ParamValues[0].Value
+		}
+
+		It("pins the image of the openshift namespace tag into the BuildRun", func() {
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(builderImage()).To(Equal("registry.example.com/nodejs@sha256:2222"))
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(imagestreams.BuilderImageAnnotation, "openshift/nodejs:20-ubi9"))
+		})
+
+		It("prefers the builder image of the BuildRun", func() {
+			buildRun.Spec.ParamValues = []buildv1beta1.ParamValue{{
+				Name:        imagestreams.BuilderImageParam,
+				SingleValue: &buildv1beta1.SingleValue{Value: ptr.To("quay.io/example/nodejs:20")},
+			}}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(builderImage()).To(Equal("quay.io/example/nodejs:20"))
+			Expect(buildRun.Annotations).NotTo(HaveKey(imagestreams.BuilderImageAnnotation))
+		})
+
+		It("rejects tags without an image", func() {
+			buildRun.Spec.ParamValues = []buildv1beta1.ParamValue{{
+				Name:        imagestreams.BuilderImageParam,
+				SingleValue: &buildv1beta1.SingleValue{Value: ptr.To("nodejs:18-ubi9")},
+			}}
+			Expect(defaulter.Default(ctx, buildRun)).To(MatchError(ContainSubstring("has no image for tag 18-ubi9")))
+		})
+
+		It("rejects missing ImageStreams of the openshift namespace", func() {
+			buildRun.Spec.ParamValues = []buildv1beta1.ParamValue{{
+				Name:        imagestreams.BuilderImageParam,
+				SingleValue: &buildv1beta1.SingleValue{Value: ptr.To("python:3.12-ubi9")},
+			}}
+			Expect(defaulter.Default(ctx, buildRun)).To(MatchError(ContainSubstring("ImageStream openshift/python not found")))
+		})
+
+		It("leaves the namespaced references without an ImageStream unchanged", func() {
+			buildRun.Spec.ParamValues = []buildv1beta1.ParamValue{{
+				Name:        imagestreams.BuilderImageParam,
+				SingleValue: &buildv1beta1.SingleValue{Value: ptr.To("bitnami/node:20")},
+			}}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(builderImage()).To(Equal("bitnami/node:20"))
+		})
+
+		It("leaves the BuildRun unchanged when the strategy has no builder image", func() {
+			objects[1].(*buildv1beta1.ClusterBuildStrategy).Spec.Parameters = nil
+			defaulter.APIReader = newClient()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.ParamValues).To(BeEmpty())
+		})
+
+		It("leaves the BuildRun unchanged when the feature is disabled", func() {
+			defaulter.BuilderImageStreams = false
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.ParamValues).To(BeEmpty())
+		})
+	})
+
+	When("the Build references a strategy variant", func() {
+		var defaulter *webhookv1beta1.BuildRunStrategyVariantDefaulter
+
+		setVariant := func(variant string) {
+			strategy := objects[1].(*buildv1beta1.ClusterBuildStrategy)
+			strategy.Labels = map[string]string{variants.Label: variant}
+			strategy.Annotations = map[string]string{variants.RuntimeClassAnnotation: "kata"}
+		}
+
+		It("runs the BuildRuns of the sandboxed variants with the RuntimeClass", func() {
+			setVariant(variants.Sandboxed)
+			defaulter = &webhookv1beta1.BuildRunStrategyVariantDefaulter{APIReader: newClient()}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.RuntimeClassName).To(Equal(ptr.To("kata")))
+		})
+
+		It("keeps the RuntimeClass of the Build", func() {
+			setVariant(variants.Sandboxed)
+			objects[0].(*buildv1beta1.Build).Spec.RuntimeClassName = ptr.To("gvisor")
+			defaulter = &webhookv1beta1.BuildRunStrategyVariantDefaulter{APIReader: newClient()}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Spec.RuntimeClassName).To(BeNil())
+		})
+
+		It("runs the BuildRuns of the user namespace variants without host users", func() {
+			setVariant(variants.UserNamespace)
+			defaulter = &webhookv1beta1.BuildRunStrategyVariantDefaulter{APIReader: newClient()}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Labels).To(HaveKeyWithValue(variants.HostUsersLabel, "false"))
+			Expect(buildRun.Spec.RuntimeClassName).To(BeNil())
+		})
+
+		It("fails when the strategy cannot be read", func() {
+			setVariant(variants.UserNamespace)
+			reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if _, ok := obj.(*buildv1beta1.ClusterBuildStrategy); ok {
+						return errors.New("connection refused")
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+			}).Build()
+			defaulter = &webhookv1beta1.BuildRunStrategyVariantDefaulter{APIReader: reader}
+			Expect(defaulter.Default(ctx, buildRun)).To(MatchError(ContainSubstring("connection refused")))
+			Expect(buildRun.Labels).NotTo(HaveKey(variants.HostUsersLabel))
+		})
+	})
+
+	When("the namespace has concurrency limits", func() {
+		var defaulter *webhookv1beta1.BuildRunBuildQueueDefaulter
+
+		// newDefaulter returns a defaulter reading the objects of the spec
+		newDefaulter := func() *webhookv1beta1.BuildRunBuildQueueDefaulter {
+			reader := newClient()
+			return &webhookv1beta1.BuildRunBuildQueueDefaulter{Client: reader, APIReader: reader, BuildQueue: true}
+		}
+
+		BeforeEach(func() {
+			objects[0].(*buildv1beta1.Build).Spec.Timeout = &metav1.Duration{Duration: 10 * time.Minute}
+			objects = append(objects, &openshiftv1alpha1.BuildNamespaceConfig{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "test", Name: openshiftv1alpha1.BuildNamespaceConfigName},
+				Spec: openshiftv1alpha1.BuildNamespaceConfigSpec{
+					Concurrency: &openshiftv1alpha1.BuildConcurrency{MaxRunning: ptr.To[int32](2)},
+				},
+			})
+		})
+
+		JustBeforeEach(func() {
+			defaulter = newDefaulter()
+		})
+
+		It("queues the BuildRun and suspends the timeout of its Build", func() {
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Labels).To(HaveKey(buildqueue.Label))
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(buildqueue.TimeoutAnnotation, "10m0s"))
+			Expect(buildRun.Spec.Timeout).To(Equal(&metav1.Duration{}))
+		})
+
+		It("records the timeout of the BuildRun", func() {
+			buildRun.Spec.Timeout = &metav1.Duration{Duration: 5 * time.Minute}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(buildqueue.TimeoutAnnotation, "5m0s"))
+		})
+
+		It("records no timeout when the BuildRun has none", func() {
+			objects[0].(*buildv1beta1.Build).Spec.Timeout = nil
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Labels).To(HaveKey(buildqueue.Label))
+			Expect(buildRun.Annotations).NotTo(HaveKey(buildqueue.TimeoutAnnotation))
+		})
+
+		It("does not queue the BuildRuns of the namespaces without concurrency limits", func() {
+			objects[2].(*openshiftv1alpha1.BuildNamespaceConfig).Spec.Concurrency = nil
+			defaulter = newDefaulter()
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Labels).NotTo(HaveKey(buildqueue.Label))
+			Expect(buildRun.Spec.Timeout).To(BeNil())
+		})
+
+		It("does not queue the BuildRuns when the queue is disabled", func() {
+			defaulter.BuildQueue = false
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Labels).NotTo(HaveKey(buildqueue.Label))
+		})
+
+		It("queues the BuildRuns created with the queue label", func() {
+			buildRun.Labels = map[string]string{buildqueue.Label: ""}
+			buildRun.Spec.Timeout = &metav1.Duration{Duration: 5 * time.Minute}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(buildqueue.TimeoutAnnotation, "5m0s"))
+			Expect(buildRun.Spec.Timeout).To(Equal(&metav1.Duration{}))
+		})
+
+		It("queues the BuildRuns created with a start time", func() {
+			buildRun.Labels = map[string]string{buildqueue.Label: ""}
+			buildRun.Annotations = map[string]string{
+				buildqueue.StartedAnnotation: time.Now().UTC().Format(time.RFC3339),
+				buildqueue.TimeoutAnnotation: "0s",
+			}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildqueue.Queued(buildRun)).To(BeTrue())
+			Expect(buildRun.Annotations).To(HaveKeyWithValue(buildqueue.TimeoutAnnotation, "10m0s"))
+		})
+
+		It("removes the queue metadata of the BuildRuns of the namespaces without concurrency limits", func() {
+			objects[2].(*openshiftv1alpha1.BuildNamespaceConfig).Spec.Concurrency = nil
+			defaulter = newDefaulter()
+			buildRun.Labels = map[string]string{buildqueue.Label: ""}
+			buildRun.Annotations = map[string]string{buildqueue.StartedAnnotation: time.Now().UTC().Format(time.RFC3339)}
+			Expect(defaulter.Default(ctx, buildRun)).To(Succeed())
+			Expect(buildRun.Labels).NotTo(HaveKey(buildqueue.Label))
+			Expect(buildRun.Annotations).NotTo(HaveKey(buildqueue.StartedAnnotation))
+		})
+	})
+
+	When("the BuildRun is in a build queue", func() {
+		const operator = "system:serviceaccount:openshift-builds:openshift-builds-operator"
+
+		var validator *webhookv1beta1.BuildRunBuildQueueValidator
+
+		// requestBy returns the context of an admission request made by the user
+		requestBy := func(username string) context.Context {
+			return admission.NewContextWithRequest(ctx, admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				UserInfo: authenticationv1.UserInfo{Username: username},
+			}})
+		}
+
+		BeforeEach(func() {
+			validator = &webhookv1beta1.BuildRunBuildQueueValidator{ServiceAccount: operator}
+			buildRun.Labels = map[string]string{buildqueue.Label: ""}
+			buildRun.Annotations = map[string]string{buildqueue.TimeoutAnnotation: "10m0s"}
+		})
+
+		It("admits the BuildRuns queued on create", func() {
+			_, err := validator.ValidateCreate(requestBy("developer"), buildRun)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects the BuildRuns created with a start time", func() {
+			buildRun.Annotations[buildqueue.StartedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			_, err := validator.ValidateCreate(requestBy("developer"), buildRun)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("rejects the users starting their queued BuildRuns", func() {
+			started := buildRun.DeepCopy()
+			started.Annotations[buildqueue.StartedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			_, err := validator.ValidateUpdate(requestBy("developer"), buildRun, started)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("rejects the users removing their BuildRuns from the queue", func() {
+			removed := buildRun.DeepCopy()
+			delete(removed.Labels, buildqueue.Label)
+			_, err := validator.ValidateUpdate(requestBy("developer"), buildRun, removed)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("rejects the users changing the timeout of their queued BuildRuns", func() {
+			changed := buildRun.DeepCopy()
+			changed.Annotations[buildqueue.TimeoutAnnotation] = "0s"
+			_, err := validator.ValidateUpdate(requestBy("developer"), buildRun, changed)
+			Expect(apierrors.IsForbidden(err)).To(BeTrue())
+		})
+
+		It("admits the other changes of the users", func() {
+			canceled := buildRun.DeepCopy()
+			canceled.Spec.State = ptr.To[buildv1beta1.BuildRunRequestedState](buildv1beta1.BuildRunStateCancel)
+			_, err := validator.ValidateUpdate(requestBy("developer"), buildRun, canceled)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("admits the operator starting the queued BuildRuns", func() {
+			started := buildRun.DeepCopy()
+			started.Annotations[buildqueue.StartedAnnotation] = time.Now().UTC().Format(time.RFC3339)
+			_, err := validator.ValidateUpdate(requestBy(operator), buildRun, started)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
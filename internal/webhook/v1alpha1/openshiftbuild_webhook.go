@@ -0,0 +1,222 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupOpenShiftBuildWebhookWithManager registers the OpenShiftBuild webhooks with the manager
+func SetupOpenShiftBuildWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&openshiftv1alpha1.OpenShiftBuild{}).
+		WithDefaulter(&OpenShiftBuildCustomDefaulter{}).
+		WithValidator(&OpenShiftBuildCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-operator-openshift-io-v1alpha1-openshiftbuild,mutating=true,failurePolicy=fail,sideEffects=None,groups=operator.openshift.io,resources=openshiftbuilds,verbs=create;update,versions=v1alpha1,name=mopenshiftbuild-v1alpha1.operator.openshift.io,admissionReviewVersions=v1
+
+// OpenShiftBuildCustomDefaulter fills the component stanzas of OpenShiftBuild on create and update
+type OpenShiftBuildCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &OpenShiftBuildCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter
+func (d *OpenShiftBuildCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	openShiftBuild, ok := obj.(*openshiftv1alpha1.OpenShiftBuild)
+	if !ok {
+		return fmt.Errorf("expected an OpenShiftBuild object but got %T", obj)
+	}
+	openShiftBuild.Default()
+	return nil
+}
+
+//+kubebuilder:webhook:path=/validate-operator-openshift-io-v1alpha1-openshiftbuild,mutating=false,failurePolicy=fail,sideEffects=None,groups=operator.openshift.io,resources=openshiftbuilds,verbs=create;update,versions=v1alpha1,name=vopenshiftbuild-v1alpha1.operator.openshift.io,admissionReviewVersions=v1
+
+// OpenShiftBuildCustomValidator validates OpenShiftBuild on create and update
+type OpenShiftBuildCustomValidator struct{}
+
+var _ webhook.CustomValidator = &OpenShiftBuildCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *OpenShiftBuildCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	openShiftBuild, ok := obj.(*openshiftv1alpha1.OpenShiftBuild)
+	if !ok {
+		return nil, fmt.Errorf("expected an OpenShiftBuild object but got %T", obj)
+	}
+	return nil, toError(openShiftBuild, ValidateCreate(openShiftBuild))
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *OpenShiftBuildCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldOpenShiftBuild, ok := oldObj.(*openshiftv1alpha1.OpenShiftBuild)
+	if !ok {
+		return nil, fmt.Errorf("expected an OpenShiftBuild object but got %T", oldObj)
+	}
+	openShiftBuild, ok := newObj.(*openshiftv1alpha1.OpenShiftBuild)
+	if !ok {
+		return nil, fmt.Errorf("expected an OpenShiftBuild object but got %T", newObj)
+	}
+	return nil, toError(openShiftBuild, ValidateUpdate(oldOpenShiftBuild, openShiftBuild))
+}
+
+// ValidateDelete implements webhook.CustomValidator. Deletion is always allowed.
+func (v *OpenShiftBuildCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// ValidateCreate checks the new OpenShiftBuild is the cluster singleton and holds a valid spec
+func ValidateCreate(openShiftBuild *openshiftv1alpha1.OpenShiftBuild) field.ErrorList {
+	errs := Validate(openShiftBuild)
+	if openShiftBuild.Name != common.OpenShiftBuildResourceName {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), openShiftBuild.Name,
+			fmt.Sprintf("OpenShiftBuild is a singleton and must be named %q", common.OpenShiftBuildResourceName)))
+	}
+	return errs
+}
+
+// ValidateUpdate checks the updated OpenShiftBuild is valid and does not change immutable fields.
+// The singleton name is only enforced on create, so that objects created earlier can be deleted.
+func ValidateUpdate(oldOpenShiftBuild, openShiftBuild *openshiftv1alpha1.OpenShiftBuild) field.ErrorList {
+	errs := Validate(openShiftBuild)
+	if oldOpenShiftBuild.Spec.Namespace != "" && openShiftBuild.Spec.Namespace != oldOpenShiftBuild.Spec.Namespace {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "namespace"), "namespace is immutable"))
+	}
+	return errs
+}
+
+// Validate checks the spec of the OpenShiftBuild
+func Validate(openShiftBuild *openshiftv1alpha1.OpenShiftBuild) field.ErrorList {
+	errs := field.ErrorList{}
+	spec := field.NewPath("spec")
+	if openShiftBuild.Spec.Namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(openShiftBuild.Spec.Namespace) {
+			errs = append(errs, field.Invalid(spec.Child("namespace"), openShiftBuild.Spec.Namespace, msg))
+		}
+		if common.IsSystemNamespace(openShiftBuild.Spec.Namespace) {
+			errs = append(errs, field.Invalid(spec.Child("namespace"), openShiftBuild.Spec.Namespace,
+				"must not be a Kubernetes or OpenShift system namespace"))
+		}
+	}
+	if openShiftBuild.Spec.Shipwright != nil && openShiftBuild.Spec.Shipwright.Build != nil {
+		errs = append(errs, validateState(spec.Child("shipwright", "build", "state"), openShiftBuild.Spec.Shipwright.Build.State)...)
+	}
+	if openShiftBuild.Spec.Shipwright != nil && openShiftBuild.Spec.Shipwright.Triggers != nil {
+		errs = append(errs, validateState(spec.Child("shipwright", "triggers", "state"), openShiftBuild.Spec.Shipwright.Triggers.State)...)
+	}
+	if openShiftBuild.Spec.Shipwright != nil && openShiftBuild.Spec.Shipwright.Pruning != nil {
+		errs = append(errs, validatePruning(spec.Child("shipwright", "pruning"), openShiftBuild.Spec.Shipwright.Pruning)...)
+	}
+	if openShiftBuild.Spec.Shipwright != nil && openShiftBuild.Spec.Shipwright.Sandboxed != nil {
+		path := spec.Child("shipwright", "sandboxed")
+		sandboxed := openShiftBuild.Spec.Shipwright.Sandboxed
+		errs = append(errs, validateState(path.Child("state"), sandboxed.State)...)
+		if sandboxed.RuntimeClassName != "" {
+			for _, msg := range validation.IsDNS1123Subdomain(sandboxed.RuntimeClassName) {
+				errs = append(errs, field.Invalid(path.Child("runtimeClassName"), sandboxed.RuntimeClassName, msg))
+			}
+		}
+	}
+	if openShiftBuild.Spec.Shipwright != nil && openShiftBuild.Spec.Shipwright.UserNamespaces != nil {
+		path := spec.Child("shipwright", "userNamespaces")
+		userNamespaces := openShiftBuild.Spec.Shipwright.UserNamespaces
+		errs = append(errs, validateState(path.Child("state"), userNamespaces.State)...)
+		errs = append(errs, metav1validation.ValidateLabelSelector(userNamespaces.NamespaceSelector,
+			metav1validation.LabelSelectorValidationOptions{}, path.Child("namespaceSelector"))...)
+	}
+	if openShiftBuild.Spec.SharedResource != nil {
+		errs = append(errs, validateState(spec.Child("sharedResource", "state"), openShiftBuild.Spec.SharedResource.State)...)
+	}
+	if entitlements := openShiftBuild.Spec.Entitlements; entitlements != nil {
+		path := spec.Child("entitlements")
+		errs = append(errs, validateState(path.Child("state"), entitlements.State)...)
+		errs = append(errs, metav1validation.ValidateLabelSelector(entitlements.NamespaceSelector,
+			metav1validation.LabelSelectorValidationOptions{}, path.Child("namespaceSelector"))...)
+		if entitlements.State == openshiftv1alpha1.Enabled && openShiftBuild.Spec.SharedResource != nil &&
+			openShiftBuild.Spec.SharedResource.State == openshiftv1alpha1.Disabled {
+			errs = append(errs, field.Invalid(path.Child("state"), entitlements.State,
+				"entitlements are shared through the Shared Resource CSI Driver, which is disabled"))
+		}
+	}
+	if buildCache := openShiftBuild.Spec.BuildCache; buildCache != nil {
+		errs = append(errs, validateBuildCache(spec.Child("buildCache"), buildCache)...)
+	}
+	return errs
+}
+
+// validateBuildCache checks the state, scope, storage class and size of the build cache
+func validateBuildCache(path *field.Path, buildCache *openshiftv1alpha1.BuildCache) field.ErrorList {
+	errs := validateState(path.Child("state"), buildCache.State)
+	switch buildCache.Scope {
+	case "", openshiftv1alpha1.BuildCacheScopeBuild, openshiftv1alpha1.BuildCacheScopeNamespace:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("scope"), buildCache.Scope,
+			[]openshiftv1alpha1.BuildCacheScope{openshiftv1alpha1.BuildCacheScopeBuild, openshiftv1alpha1.BuildCacheScopeNamespace}))
+	}
+	if buildCache.StorageClassName != nil {
+		for _, msg := range validation.IsDNS1123Subdomain(*buildCache.StorageClassName) {
+			errs = append(errs, field.Invalid(path.Child("storageClassName"), *buildCache.StorageClassName, msg))
+		}
+	}
+	if buildCache.Size != nil && buildCache.Size.Sign() <= 0 {
+		errs = append(errs, field.Invalid(path.Child("size"), buildCache.Size.String(), "must be positive"))
+	}
+	return errs
+}
+
+// validateState checks the state of a component is one of Enabled or Disabled
+func validateState(path *field.Path, state openshiftv1alpha1.State) field.ErrorList {
+	switch state {
+	case openshiftv1alpha1.Enabled, openshiftv1alpha1.Disabled:
+		return nil
+	}
+	return field.ErrorList{
+		field.NotSupported(path, state, []openshiftv1alpha1.State{openshiftv1alpha1.Enabled, openshiftv1alpha1.Disabled}),
+	}
+}
+
+// validatePruning checks the state, limits and TTLs of the BuildRun retention policy
+func validatePruning(path *field.Path, pruning *openshiftv1alpha1.ShipwrightPruning) field.ErrorList {
+	errs := validateState(path.Child("state"), pruning.State)
+	errs = append(errs, validateLimit(path.Child("succeededLimit"), pruning.SucceededLimit)...)
+	errs = append(errs, validateLimit(path.Child("failedLimit"), pruning.FailedLimit)...)
+	errs = append(errs, validateTTL(path.Child("ttlAfterSucceeded"), pruning.TTLAfterSucceeded)...)
+	errs = append(errs, validateTTL(path.Child("ttlAfterFailed"), pruning.TTLAfterFailed)...)
+	return errs
+}
+
+// validateLimit checks a retention limit is between 1 and 10000 when set
+func validateLimit(path *field.Path, limit *int32) field.ErrorList {
+	if limit == nil || (*limit >= 1 && *limit <= 10000) {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path, *limit, "must be between 1 and 10000")}
+}
+
+// validateTTL checks a retention TTL is positive when set
+func validateTTL(path *field.Path, ttl *metav1.Duration) field.ErrorList {
+	if ttl == nil || ttl.Duration > 0 {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(path, ttl.Duration.String(), "must be positive")}
+}
+
+// toError converts the validation errors to an Invalid API error
+func toError(openShiftBuild *openshiftv1alpha1.OpenShiftBuild, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(openshiftv1alpha1.GroupVersion.WithKind("OpenShiftBuild").GroupKind(), openShiftBuild.Name, errs)
+}
@@ -0,0 +1,182 @@
+package v1alpha1_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	openshiftv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
+	"github.com/redhat-openshift-builds/operator/internal/common"
+	webhookv1alpha1 "github.com/redhat-openshift-builds/operator/internal/webhook/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("OpenShiftBuild webhook", Label("webhook"), func() {
+	var (
+		ctx            context.Context
+		openShiftBuild *openshiftv1alpha1.OpenShiftBuild
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		openShiftBuild = &openshiftv1alpha1.OpenShiftBuild{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: common.OpenShiftBuildResourceName,
+			},
+		}
+	})
+
+	Describe("Defaulter", func() {
+		It("fills the empty component stanzas", func() {
+			Expect((&webhookv1alpha1.OpenShiftBuildCustomDefaulter{}).Default(ctx, openShiftBuild)).To(Succeed())
+			Expect(openShiftBuild.Spec.Shipwright.Build.State).To(Equal(openshiftv1alpha1.Enabled))
+			Expect(openShiftBuild.Spec.Shipwright.Triggers.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.Shipwright.Pruning.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.Shipwright.Sandboxed.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.Shipwright.Sandboxed.RuntimeClassName).To(Equal("kata"))
+			Expect(openShiftBuild.Spec.Shipwright.UserNamespaces.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.SharedResource.State).To(Equal(openshiftv1alpha1.Enabled))
+			Expect(openShiftBuild.Spec.Entitlements.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.BuildCache.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.BuildCache.Scope).To(Equal(openshiftv1alpha1.BuildCacheScopeBuild))
+		})
+
+		It("keeps the states already set", func() {
+			openShiftBuild.Spec.Shipwright = &openshiftv1alpha1.Shipwright{
+				Build:    &openshiftv1alpha1.ShipwrightBuild{State: openshiftv1alpha1.Disabled},
+				Triggers: &openshiftv1alpha1.ShipwrightTriggers{State: openshiftv1alpha1.Enabled},
+			}
+			openShiftBuild.Spec.SharedResource = &openshiftv1alpha1.SharedResource{State: openshiftv1alpha1.Disabled}
+			Expect((&webhookv1alpha1.OpenShiftBuildCustomDefaulter{}).Default(ctx, openShiftBuild)).To(Succeed())
+			Expect(openShiftBuild.Spec.Shipwright.Build.State).To(Equal(openshiftv1alpha1.Disabled))
+			Expect(openShiftBuild.Spec.Shipwright.Triggers.State).To(Equal(openshiftv1alpha1.Enabled))
+			Expect(openShiftBuild.Spec.SharedResource.State).To(Equal(openshiftv1alpha1.Disabled))
+		})
+	})
+
+	Describe("Validator", func() {
+		var validator *webhookv1alpha1.OpenShiftBuildCustomValidator
+
+		BeforeEach(func() {
+			validator = &webhookv1alpha1.OpenShiftBuildCustomValidator{}
+			openShiftBuild.Default()
+		})
+
+		It("accepts the defaulted singleton", func() {
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects an instance not named cluster", func() {
+			openShiftBuild.Name = "test"
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			Expect(err).To(MatchError(ContainSubstring("metadata.name")))
+		})
+
+		It("rejects unknown component states", func() {
+			openShiftBuild.Spec.Shipwright.Build.State = "Unknown"
+			openShiftBuild.Spec.Shipwright.Triggers.State = "Managed"
+			openShiftBuild.Spec.SharedResource.State = "Removed"
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.build.state")))
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.triggers.state")))
+			Expect(err).To(MatchError(ContainSubstring("spec.sharedResource.state")))
+		})
+
+		It("rejects an invalid entitlement namespace selector", func() {
+			openShiftBuild.Spec.Entitlements.State = openshiftv1alpha1.Enabled
+			openShiftBuild.Spec.Entitlements.NamespaceSelector = &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "builds", Operator: "Equals"}},
+			}
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.entitlements.namespaceSelector")))
+		})
+
+		It("rejects entitlements without the Shared Resource CSI Driver", func() {
+			openShiftBuild.Spec.Entitlements.State = openshiftv1alpha1.Enabled
+			openShiftBuild.Spec.SharedResource.State = openshiftv1alpha1.Disabled
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.entitlements.state")))
+		})
+
+		It("rejects invalid pruning limits and TTLs", func() {
+			openShiftBuild.Spec.Shipwright.Pruning = &openshiftv1alpha1.ShipwrightPruning{
+				State:          openshiftv1alpha1.Enabled,
+				SucceededLimit: ptr.To[int32](0),
+				TTLAfterFailed: &metav1.Duration{Duration: -time.Hour},
+			}
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.pruning.succeededLimit")))
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.pruning.ttlAfterFailed")))
+		})
+
+		It("rejects an invalid build cache", func() {
+			openShiftBuild.Spec.BuildCache = &openshiftv1alpha1.BuildCache{
+				State:            openshiftv1alpha1.Enabled,
+				Scope:            "Cluster",
+				StorageClassName: ptr.To("Invalid_Class"),
+				Size:             ptr.To(resource.MustParse("0")),
+			}
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.buildCache.scope")))
+			Expect(err).To(MatchError(ContainSubstring("spec.buildCache.storageClassName")))
+			Expect(err).To(MatchError(ContainSubstring("spec.buildCache.size")))
+		})
+
+		It("rejects invalid strategy variants", func() {
+			openShiftBuild.Spec.Shipwright.Sandboxed = &openshiftv1alpha1.ShipwrightSandboxed{
+				State:            openshiftv1alpha1.Enabled,
+				RuntimeClassName: "Invalid_Class",
+			}
+			openShiftBuild.Spec.Shipwright.UserNamespaces = &openshiftv1alpha1.ShipwrightUserNamespaces{
+				State: "Managed",
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "builds", Operator: "Equals"}},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.sandboxed.runtimeClassName")))
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.userNamespaces.state")))
+			Expect(err).To(MatchError(ContainSubstring("spec.shipwright.userNamespaces.namespaceSelector")))
+		})
+
+		It("rejects an invalid operand namespace", func() {
+			openShiftBuild.Spec.Namespace = "Invalid_Namespace"
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).To(MatchError(ContainSubstring("spec.namespace")))
+		})
+
+		It("rejects a system operand namespace", func() {
+			for _, namespace := range []string{"default", "kube-system", "openshift-config"} {
+				openShiftBuild.Spec.Namespace = namespace
+				_, err := validator.ValidateCreate(ctx, openShiftBuild)
+				Expect(err).To(MatchError(ContainSubstring("system namespace")), namespace)
+			}
+			openShiftBuild.Spec.Namespace = "openshift-builds"
+			_, err := validator.ValidateCreate(ctx, openShiftBuild)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects changes to the operand namespace", func() {
+			openShiftBuild.Spec.Namespace = "openshift-builds"
+			updated := openShiftBuild.DeepCopy()
+			updated.Spec.Namespace = "other"
+			_, err := validator.ValidateUpdate(ctx, openShiftBuild, updated)
+			Expect(err).To(MatchError(ContainSubstring("namespace is immutable")))
+		})
+
+		It("allows updates of instances created before the singleton was enforced", func() {
+			openShiftBuild.Name = "test"
+			updated := openShiftBuild.DeepCopy()
+			updated.Finalizers = nil
+			_, err := validator.ValidateUpdate(ctx, openShiftBuild, updated)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -8,6 +8,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	operatorv1alpha1 "github.com/redhat-openshift-builds/operator/api/v1alpha1"
 	"github.com/redhat-openshift-builds/operator/test/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -76,6 +77,7 @@ var _ = Describe("Shared Resource CSI Driver test", Ordered, Label("e2e"), Label
 		var testResources = []string{
 			"/test/data/rbac.yaml",
 			"/test/data/resources.yaml",
+			"/test/data/grant.yaml",
 		}
 
 		BeforeEach(func(ctx SpecContext) {
@@ -99,6 +101,15 @@ var _ = Describe("Shared Resource CSI Driver test", Ordered, Label("e2e"), Label
 			for _, resource := range testResources {
 				Expect(utils.ApplyResourceFromFile(ctx, kubeClient, projectDir+resource)).To(Succeed())
 			}
+
+			By("Waiting for the SharedResourceGrant to bind the default service account")
+			Eventually(func() []string {
+				grant := &operatorv1alpha1.SharedResourceGrant{}
+				if err := kubeClient.Get(ctx, client.ObjectKey{Name: "e2e-shared-resources"}, grant); err != nil {
+					return nil
+				}
+				return grant.Status.Grantees
+			}, "1m", "2s").Should(ContainElement("system:serviceaccount:" + targetNS + ":default"))
 		})
 
 		AfterEach(func(ctx SpecContext) {